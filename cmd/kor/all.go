@@ -1,12 +1,11 @@
 package kor
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/spf13/cobra"
 
 	"github.com/yonahd/kor/pkg/kor"
-	"github.com/yonahd/kor/pkg/utils"
 )
 
 var allCmd = &cobra.Command{
@@ -14,17 +13,10 @@ var allCmd = &cobra.Command{
 	Short: "Gets unused resources",
 	Args:  cobra.ExactArgs(0),
 	Run: func(cmd *cobra.Command, args []string) {
-		clientset := kor.GetKubeClient(kubeconfig)
-		apiExtClient := kor.GetAPIExtensionsClient(kubeconfig)
-		dynamicClient := kor.GetDynamicClient(kubeconfig)
 		kor.SetNamespacedFlagState(cmd.Flags().Changed("namespaced"))
-
-		if response, err := kor.GetUnusedAll(filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts); err != nil {
-			fmt.Println(err)
-		} else {
-			utils.PrintLogo(outputFormat)
-			fmt.Println(response)
-		}
+		runReport(cmd, true, func(ctx context.Context, clients kor.Clients) (*kor.Report, error) {
+			return kor.GetUnusedAllReport(ctx, filterOptions, clients, opts)
+		})
 	},
 }
 
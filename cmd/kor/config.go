@@ -0,0 +1,47 @@
+package kor
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configFile string
+
+// applyConfigFile sets the --fail-on-findings options from the --config file.
+// Flags given on the command line take precedence over the file.
+func applyConfigFile(cmd *cobra.Command) error {
+	if configFile != "" {
+		config := viper.New()
+		config.SetConfigFile(configFile)
+		if err := config.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to read config file %s: %w", configFile, err)
+		}
+
+		if config.IsSet("fail-on-findings") && !cmd.Flags().Changed("fail-on-findings") {
+			opts.FailOnFindings = config.GetBool("fail-on-findings")
+		}
+		for key, thresholds := range map[string]*map[string]int{
+			"max-unused":               &opts.MaxUnused,
+			"max-unused-per-namespace": &opts.MaxUnusedPerNamespace,
+		} {
+			var fromFile map[string]int
+			if err := config.UnmarshalKey(key, &fromFile); err != nil {
+				return fmt.Errorf("invalid %s in config file %s: %w", key, configFile, err)
+			}
+			if len(fromFile) == 0 {
+				continue
+			}
+			maps.Copy(fromFile, *thresholds)
+			*thresholds = fromFile
+		}
+	}
+
+	// thresholds are only checked when failing on findings
+	if len(opts.MaxUnused) > 0 || len(opts.MaxUnusedPerNamespace) > 0 {
+		opts.FailOnFindings = true
+	}
+	return nil
+}
@@ -1,31 +0,0 @@
-package kor
-
-import (
-	"fmt"
-
-	"github.com/spf13/cobra"
-
-	"github.com/yonahd/kor/pkg/kor"
-	"github.com/yonahd/kor/pkg/utils"
-)
-
-var dsCmd = &cobra.Command{
-	Use:     "daemonset",
-	Aliases: []string{"ds", "daemonsets"},
-	Short:   "Gets unused daemonSets",
-	Args:    cobra.ExactArgs(0),
-	Run: func(cmd *cobra.Command, args []string) {
-		clientset := kor.GetKubeClient(kubeconfig)
-
-		if response, err := kor.GetUnusedDaemonSets(filterOptions, clientset, outputFormat, opts); err != nil {
-			fmt.Println(err)
-		} else {
-			utils.PrintLogo(outputFormat)
-			fmt.Println(response)
-		}
-	},
-}
-
-func init() {
-	rootCmd.AddCommand(dsCmd)
-}
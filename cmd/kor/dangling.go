@@ -0,0 +1,64 @@
+package kor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var danglingCmd = &cobra.Command{
+	Use:   "dangling",
+	Short: "Find references to resources that don't exist",
+	Long: `Find the references to resources that don't exist, the reverse of the unused
+resources: pods and the pod templates of workloads referencing missing
+ConfigMaps, Secrets, PVCs, ServiceAccounts or PriorityClasses, Ingresses
+pointing at missing Services or TLS Secrets, and HPAs targeting missing
+workloads. Each broken reference is reported with its field path. References
+marked optional are left out.
+
+The exit code is 3 when a broken reference is found.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if opts.DeleteFlag {
+			fatal(errors.New("kor dangling doesn't delete anything, run it without --delete"))
+			return
+		}
+		clients, err := getClients()
+		if err != nil {
+			fatal(err)
+			return
+		}
+		report, err := kor.FindDanglingReferences(cmd.Context(), clients, filterOptions, opts)
+		if err != nil {
+			fatal(err)
+			return
+		}
+		output, err := kor.FormatDanglingReport(report, outputFormat)
+		if err != nil {
+			fatal(err)
+			return
+		}
+		fmt.Print(output)
+		for _, scanErr := range report.Errors {
+			if scanErr.Namespace == "" {
+				fmt.Fprintln(os.Stderr, scanErr.Message)
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "Failed to check namespace %s: %s\n", scanErr.Namespace, scanErr.Message)
+		}
+		switch {
+		case len(report.Errors) > 0:
+			exitCode = exitPartial
+		case len(report.References) > 0:
+			exitCode = exitFindings
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(danglingCmd)
+}
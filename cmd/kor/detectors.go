@@ -0,0 +1,50 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+// newDetectorCmd builds the command listing the unused resources found by a single detector
+func newDetectorCmd(detector kor.Detector) *cobra.Command {
+	names := detector.Aliases()
+	if len(names) == 0 {
+		names = []string{strings.ToLower(detector.Name())}
+	}
+
+	return &cobra.Command{
+		Use:     names[0],
+		Aliases: names[1:],
+		Short:   fmt.Sprintf("Gets unused %s resources", names[0]),
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runReport(cmd, true, func(ctx context.Context, clients kor.Clients) (*kor.Report, error) {
+				return kor.GetUnusedReport(ctx, []kor.Detector{detector}, filterOptions, clients, opts)
+			})
+		},
+	}
+}
+
+// addDetectorCmds adds a command for every registered detector that doesn't clash with an existing command
+func addDetectorCmds() {
+	for _, detector := range kor.Detectors() {
+		detectorCmd := newDetectorCmd(detector)
+		if existing, _, err := rootCmd.Find([]string{detectorCmd.Name()}); err == nil && existing != rootCmd {
+			continue
+		}
+		rootCmd.AddCommand(detectorCmd)
+	}
+}
+
+func getClients() (kor.Clients, error) {
+	return clientConfig().NewClients()
+}
+
+func clientConfig() kor.ClientConfig {
+	return kor.ClientConfig{Kubeconfig: kubeconfig, QPS: clientQPS, Burst: clientBurst}
+}
@@ -0,0 +1,128 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+	"github.com/yonahd/kor/pkg/utils"
+)
+
+// Exit codes of the scan commands, so CI jobs can tell the outcomes apart
+const (
+	exitOK       = 0
+	exitFatal    = 1
+	exitPartial  = 2
+	exitFindings = 3
+)
+
+var exitCode = exitOK
+
+// reportExitCode returns exitPartial when some namespaces or kinds could not be
+// scanned, and exitFindings when unused resources were left behind. With
+// --fail-on-findings, only the unused resources exceeding its thresholds
+// return exitFindings.
+func reportExitCode(report *kor.Report) int {
+	if report.Incomplete || len(report.Errors) > 0 {
+		return exitPartial
+	}
+	if opts.FailOnFindings {
+		if len(report.Violations) > 0 {
+			return exitFindings
+		}
+		return exitOK
+	}
+	for _, finding := range report.Findings {
+		if !finding.Deleted || finding.DryRun {
+			return exitFindings
+		}
+	}
+	return exitOK
+}
+
+// printViolations summarizes the exceeded thresholds on stderr, leaving
+// stdout to the report
+func printViolations(violations []kor.ThresholdViolation) {
+	if len(violations) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Unused resources exceed the --fail-on-findings thresholds:")
+	for _, violation := range violations {
+		fmt.Fprintf(os.Stderr, "  %s\n", violation)
+	}
+}
+
+// runReport runs a scan with the command's context and prints its report,
+// setting the exit code from its outcome
+func runReport(cmd *cobra.Command, printLogo bool, scan func(ctx context.Context, clients kor.Clients) (*kor.Report, error)) {
+	clients, err := getClients()
+	if err != nil {
+		fatal(err)
+		return
+	}
+
+	report, err := scan(cmd.Context(), clients)
+	if err != nil {
+		fatal(err)
+		return
+	}
+
+	output, err := kor.FormatReport(report, outputFormat, opts)
+	if err != nil {
+		fatal(err)
+		return
+	}
+	if printLogo {
+		utils.PrintLogo(outputFormat)
+	}
+	fmt.Println(output)
+	if report.BackupArchive != "" {
+		fmt.Fprintf(os.Stderr, "Deleted resources were backed up to %s, run kor restore %s to restore them\n", report.BackupArchive, report.BackupArchive)
+	}
+	if opts.DryRun != "" {
+		printCascade("The garbage collector would also remove the dependents of the resources deleted by the dry run:", report.Cascade)
+	} else {
+		printCascade("The garbage collector also removes the dependents of the deleted resources:", report.Cascade)
+	}
+	for _, released := range report.Released {
+		fmt.Fprintf(os.Stderr, "Released %s %s from quarantine, it is no longer unused\n", released.Kind, qualifiedName(released.Namespace, released.Name))
+	}
+	for _, skipped := range report.Skipped {
+		fmt.Fprintf(os.Stderr, "Skipped %s %s, %s\n", skipped.Kind, qualifiedName(skipped.Namespace, skipped.Name), skipped.Reason)
+	}
+	printViolations(report.Violations)
+	exitCode = reportExitCode(report)
+}
+
+// printCascade shows on stderr, under header, the tree of dependents the
+// garbage collector removes along with the deleted resources
+func printCascade(header string, cascade []kor.CascadeNode) {
+	if len(cascade) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, header)
+	var printNodes func(nodes []kor.CascadeNode, indent string)
+	printNodes = func(nodes []kor.CascadeNode, indent string) {
+		for _, node := range nodes {
+			fmt.Fprintf(os.Stderr, "%s%s %s\n", indent, node.Kind, qualifiedName(node.Namespace, node.Name))
+			printNodes(node.Dependents, indent+"  ")
+		}
+	}
+	printNodes(cascade, "  ")
+}
+
+// qualifiedName returns namespace/name, or name for cluster-scoped resources
+func qualifiedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	exitCode = exitFatal
+}
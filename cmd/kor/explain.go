@@ -0,0 +1,62 @@
+package kor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <resource type>/<name>",
+	Short: "Explain why a resource is considered used or unused",
+	Long: `Show why a resource is reported as unused or not. For the resource types kor
+finds unused when nothing references them, such as ConfigMaps, Secrets or
+ServiceAccounts, every reference found is listed with the field it comes from,
+e.g. spec.containers[0].envFrom[1].configMapRef, along with each rule checked.
+Other resource types are explained by the reason of their finding.
+
+The namespace is set with -n, the default namespace when not set.
+Example: kor explain configmap/foo -n bar`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		kind, name, ok := strings.Cut(args[0], "/")
+		if !ok || kind == "" || name == "" {
+			fatal(fmt.Errorf("invalid resource %q, expected <resource type>/<name>, e.g. configmap/foo", args[0]))
+			return
+		}
+		namespace := "default"
+		switch len(filterOptions.IncludeNamespaces) {
+		case 0:
+		case 1:
+			namespace = filterOptions.IncludeNamespaces[0]
+		default:
+			fatal(errors.New("kor explain explains a single resource, set a single namespace with -n"))
+			return
+		}
+
+		clients, err := getClients()
+		if err != nil {
+			fatal(err)
+			return
+		}
+		explanation, err := kor.Explain(cmd.Context(), clients, kind, namespace, name, filterOptions, opts)
+		if err != nil {
+			fatal(err)
+			return
+		}
+		output, err := kor.FormatExplanation(explanation, outputFormat)
+		if err != nil {
+			fatal(err)
+			return
+		}
+		fmt.Print(output)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
@@ -13,12 +13,15 @@ var exporterCmd = &cobra.Command{
 	Short: "start prometheus exporter",
 	Args:  cobra.ExactArgs(0),
 	Run: func(cmd *cobra.Command, args []string) {
-		clientset := kor.GetKubeClient(kubeconfig)
-		apiExtClient := kor.GetAPIExtensionsClient(kubeconfig)
-		dynamicClient := kor.GetDynamicClient(kubeconfig)
+		clients, err := getClients()
+		if err != nil {
+			fatal(err)
+			return
+		}
 		kor.SetNamespacedFlagState(cmd.Flags().Changed("namespaced"))
-		kor.Exporter(filterOptions, clientset, apiExtClient, dynamicClient, "json", opts, resourceList)
-
+		if err := kor.Exporter(cmd.Context(), filterOptions, clients.Clientset, clients.APIExtensions, clients.Dynamic, "json", opts, resourceList); err != nil {
+			fatal(err)
+		}
 	},
 }
 
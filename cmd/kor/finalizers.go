@@ -1,7 +1,7 @@
 package kor
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/spf13/cobra"
 
@@ -14,14 +14,9 @@ var finalizerCmd = &cobra.Command{
 	Short:   "Gets resources waiting for finalizers to delete",
 	Args:    cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		clientset := kor.GetKubeClient(kubeconfig)
-		dynamicClient := kor.GetDynamicClient(kubeconfig)
-
-		if response, err := kor.GetUnusedfinalizers(filterOptions, clientset, dynamicClient, outputFormat, opts); err != nil {
-			fmt.Println(err)
-		} else {
-			fmt.Println(response)
-		}
+		runReport(cmd, false, func(ctx context.Context, clients kor.Clients) (*kor.Report, error) {
+			return kor.GetUnusedFinalizersReport(ctx, filterOptions, clients.Clientset, clients.Dynamic, opts)
+		})
 	},
 }
 
@@ -0,0 +1,60 @@
+package kor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var graphFormat string
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Print the references between resources as a Graphviz DOT, Mermaid or JSON graph",
+	Long: `Print the references kor works out between resources, such as Pod to ConfigMap,
+Secret or PVC, PVC to PV to StorageClass, RoleBinding to Role and ServiceAccount,
+Ingress to Service and HPA to Deployment, for the namespaces set with
+--include-namespaces or the whole cluster. Each edge is labeled with the field
+the reference comes from, and the unused resources are highlighted.
+
+Example: kor graph -n bar -o mermaid`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if opts.DeleteFlag {
+			fatal(errors.New("kor graph doesn't delete anything, run it without --delete"))
+			return
+		}
+		clients, err := getClients()
+		if err != nil {
+			fatal(err)
+			return
+		}
+		graph, err := kor.BuildGraph(cmd.Context(), clients, filterOptions, opts)
+		if err != nil {
+			fatal(err)
+			return
+		}
+		output, err := kor.FormatGraph(graph, graphFormat)
+		if err != nil {
+			fatal(err)
+			return
+		}
+		fmt.Print(output)
+		for _, scanErr := range graph.Errors {
+			fmt.Fprintf(os.Stderr, "Failed to scan %s, its unused resources are not highlighted\n", scanErr)
+		}
+		if len(graph.Errors) > 0 {
+			exitCode = exitPartial
+		}
+	},
+}
+
+func init() {
+	// shadows the --output format of the other commands, graphs are not tables
+	graphCmd.Flags().StringVarP(&graphFormat, "output", "o", "dot", "Output format (dot, mermaid, json or yaml)")
+	rootCmd.AddCommand(graphCmd)
+}
@@ -0,0 +1,72 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var planFile string
+
+var planCmd = &cobra.Command{
+	Use:   "plan <resource types>",
+	Short: "Write the unused resources to delete to a plan file, to review before kor apply",
+	Long: `Scan the comma-separated resource types, or all of them, and write the unused
+resources to a plan file instead of deleting them. Each resource is recorded with
+its UID, resourceVersion, reason and a hash of its content, so kor apply only
+deletes it when it did not change since. The dependents the garbage collector
+removes along with each resource are listed under it.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if opts.DeleteFlag {
+			fatal(errors.New("kor plan doesn't delete anything, run kor apply on the plan instead of --delete"))
+			return
+		}
+		resourceNames := args[0]
+		runReport(cmd, true, func(ctx context.Context, clients kor.Clients) (*kor.Report, error) {
+			report, err := scanResources(ctx, clients, resourceNames)
+			if err != nil {
+				return nil, err
+			}
+			plan := kor.NewPlan(ctx, report, clients)
+			if err := kor.WritePlan(planFile, plan); err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(os.Stderr, "Planned the deletion of %d resources in %s, run kor apply %s to delete them\n", len(plan.Items), planFile, planFile)
+			printCascade("The garbage collector also removes the dependents of the planned resources:", plan.Cascade())
+			return report, nil
+		})
+	},
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <plan>",
+	Short: "Delete the resources of a kor plan file that did not change since",
+	Long: `Delete the resources of a plan written by kor plan. Resources whose UID,
+resourceVersion or content changed since the plan was made are skipped, and the
+deletes are sent with the UID and resourceVersion as preconditions. Nothing is
+asked, reviewing the plan is the confirmation. --dry-run, --cascade and
+--backup-dir apply as with --delete.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runReport(cmd, false, func(ctx context.Context, clients kor.Clients) (*kor.Report, error) {
+			plan, err := kor.ReadPlan(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return kor.ApplyPlan(ctx, plan, clients, opts)
+		})
+	},
+}
+
+func init() {
+	// shadows the --output format of the other commands, the plan is always JSON
+	planCmd.Flags().StringVarP(&planFile, "output", "o", "", "File to write the plan to. Example: -o plan.json")
+	_ = planCmd.MarkFlagRequired("output")
+	rootCmd.AddCommand(planCmd, applyCmd)
+}
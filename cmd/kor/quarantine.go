@@ -0,0 +1,92 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var quarantineCmd = &cobra.Command{
+	Use:   "quarantine <resource types>",
+	Short: "Label unused resources with kor/quarantined-at, to be deleted later by kor reap",
+	Long: `Label the unused resources of the comma-separated resource types, or all of
+them, with kor/quarantined-at=<time>. Resources already in quarantine keep their
+time, and quarantined resources that are used again are released.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts.Quarantine = kor.QuarantineMark
+		runQuarantineScan(cmd, args[0])
+	},
+}
+
+var reapCmd = &cobra.Command{
+	Use:   "reap <resource types>",
+	Short: "Delete the resources quarantined for longer than --after that are still unused",
+	Long: `Delete the unused resources of the comma-separated resource types, or all of
+them, that kor quarantine labelled longer than --after ago. Quarantined resources
+that are used again are released. --no-interactive, --dry-run, --cascade and
+--backup-dir apply as with --delete.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts.Quarantine = kor.QuarantineReap
+		opts.DeleteFlag = true
+		runQuarantineScan(cmd, args[0])
+	},
+}
+
+func runQuarantineScan(cmd *cobra.Command, resourceNames string) {
+	runReport(cmd, true, func(ctx context.Context, clients kor.Clients) (*kor.Report, error) {
+		return scanResources(ctx, clients, resourceNames)
+	})
+}
+
+// scanResources scans the comma-separated resource types, or all of them
+func scanResources(ctx context.Context, clients kor.Clients, resourceNames string) (*kor.Report, error) {
+	if resourceNames == "all" {
+		return kor.GetUnusedAllReport(ctx, filterOptions, clients, opts)
+	}
+	return kor.GetUnusedMultiReport(ctx, resourceNames, filterOptions, clients, opts)
+}
+
+// gracePeriod is a duration flag that also accepts days, e.g. 14d
+type gracePeriod time.Duration
+
+func (g *gracePeriod) Set(value string) error {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid number of days %q", value)
+		}
+		*g = gracePeriod(time.Duration(n) * 24 * time.Hour)
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	if d < 0 {
+		return fmt.Errorf("grace period %q must not be negative", value)
+	}
+	*g = gracePeriod(d)
+	return nil
+}
+
+func (g *gracePeriod) String() string {
+	return time.Duration(*g).String()
+}
+
+func (g *gracePeriod) Type() string {
+	return "duration"
+}
+
+func init() {
+	reapCmd.Flags().Var((*gracePeriod)(&opts.QuarantineGracePeriod), "after", "Minimum time a resource must have been in quarantine to be deleted. Example: --after=14d or --after=36h")
+	_ = reapCmd.MarkFlagRequired("after")
+	rootCmd.AddCommand(quarantineCmd, reapCmd)
+}
@@ -0,0 +1,49 @@
+package kor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var restoreSelection kor.RestoreSelection
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "Restore resources saved with --backup-dir",
+	Long: `Restore the resources of a --backup-dir archive that no longer exist.
+Resources that already exist are skipped. The selection flags, along with
+--include-namespaces, restore only part of the archive.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		clients, err := getClients()
+		if err != nil {
+			fatal(err)
+			return
+		}
+
+		restoreSelection.Namespaces = filterOptions.IncludeNamespaces
+		results, err := kor.RestoreBackup(cmd.Context(), args[0], clients, restoreSelection)
+		for _, result := range results {
+			name := qualifiedName(result.Namespace, result.Name)
+			if result.Status == kor.RestoreFailed {
+				fmt.Fprintf(os.Stderr, "Failed to restore %s %s: %s\n", result.Kind, name, result.Error)
+				exitCode = exitPartial
+				continue
+			}
+			fmt.Printf("%s %s: %s\n", result.Kind, name, result.Status)
+		}
+		if err != nil {
+			fatal(err)
+		}
+	},
+}
+
+func init() {
+	restoreCmd.Flags().StringSliceVar(&restoreSelection.Kinds, "kinds", nil, "Resource types to restore, split by commas. Example: --kinds secret,cm")
+	restoreCmd.Flags().StringSliceVar(&restoreSelection.Names, "names", nil, "Names of the resources to restore, split by commas")
+	rootCmd.AddCommand(restoreCmd)
+}
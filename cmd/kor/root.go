@@ -1,10 +1,13 @@
 package kor
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -12,7 +15,6 @@ import (
 	"github.com/yonahd/kor/pkg/common"
 	"github.com/yonahd/kor/pkg/filters"
 	"github.com/yonahd/kor/pkg/kor"
-	"github.com/yonahd/kor/pkg/utils"
 )
 
 func execName() string {
@@ -35,27 +37,36 @@ var rootCmd = &cobra.Command{
 			return nil
 		}
 
-		initKindsList()
-		return nil
+		if err := applyConfigFile(cmd); err != nil {
+			return err
+		}
+		if err := kor.ValidateDryRun(opts.DryRun); err != nil {
+			return err
+		}
+		if err := kor.ValidateCascade(opts.Cascade); err != nil {
+			return err
+		}
+		if opts.AuditLog != "" || opts.AuditEvents {
+			opts.AuditUser = kor.GetKubeUser(kubeconfig)
+		}
+		if opts.DryRun != "" && !opts.DeleteFlag && cmd != reapCmd && cmd != applyCmd {
+			return fmt.Errorf("--dry-run requires --delete")
+		}
+		return initKindsList()
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		resourceNames := args[0]
-		clientset := kor.GetKubeClient(kubeconfig)
-		apiExtClient := kor.GetAPIExtensionsClient(kubeconfig)
-		dynamicClient := kor.GetDynamicClient(kubeconfig)
-
-		if response, err := kor.GetUnusedMulti(resourceNames, filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts); err != nil {
-			fmt.Println(err)
-		} else {
-			utils.PrintLogo(outputFormat)
-			fmt.Println(response)
-		}
+		runReport(cmd, true, func(ctx context.Context, clients kor.Clients) (*kor.Report, error) {
+			return kor.GetUnusedMultiReport(ctx, resourceNames, filterOptions, clients, opts)
+		})
 	},
 }
 
 var (
 	outputFormat  string
 	kubeconfig    string
+	clientQPS     float32
+	clientBurst   int
 	opts          common.Opts
 	filterOptions = &filters.Options{}
 )
@@ -66,12 +77,20 @@ func init() {
 	addFilterOptionsFlag(rootCmd, filterOptions)
 }
 
-func initKindsList() {
+func initKindsList() error {
 	// Only initialize if not already done
 	if kor.ResourceKindList == nil {
-		clientset := kor.GetKubeClient(kubeconfig)
-		kor.ResourceKindList, _ = kor.GetResourceKinds(clientset)
+		clients, err := getClients()
+		if err != nil {
+			return err
+		}
+		kor.ResourceKindList, err = kor.GetResourceKinds(clients.Clientset)
+		if err != nil {
+			// the kinds of the groups that were discovered are still used
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
 	}
+	return nil
 }
 
 func initFlags() {
@@ -81,10 +100,28 @@ func initFlags() {
 	rootCmd.PersistentFlags().StringVar(&opts.Channel, "slack-channel", "", "Slack channel to send notifications to, requires --slack-auth-token to be set")
 	rootCmd.PersistentFlags().StringVar(&opts.Token, "slack-auth-token", "", "Slack auth token to send notifications to, requires --slack-channel to be set")
 	rootCmd.PersistentFlags().BoolVar(&opts.DeleteFlag, "delete", false, "Delete unused resources")
+	rootCmd.PersistentFlags().StringVar(&opts.DryRun, "dry-run", "", "Only report what --delete would delete (client), or send the deletes to the API server as a dry run (server) to check admission and RBAC")
+	rootCmd.PersistentFlags().Lookup("dry-run").NoOptDefVal = kor.DryRunClient
+	rootCmd.PersistentFlags().StringVar(&opts.Cascade, "cascade", "", "Propagation policy of the deletes: background, foreground or orphan, to keep the dependents (default: the policy of each kind)")
+	rootCmd.PersistentFlags().StringVar(&opts.BackupDir, "backup-dir", "", "Directory to save the resources to before --delete deletes them, restored with kor restore")
+	rootCmd.PersistentFlags().StringVar(&opts.AuditLog, "audit-log", "", "File to append a JSON line to for every delete, flag, skip and failure, with who ran it, when, and the object UID")
+	rootCmd.PersistentFlags().BoolVar(&opts.AuditEvents, "audit-events", false, "Also record every delete, flag, skip and failure as a Kubernetes Event in the namespace of the resource")
 	rootCmd.PersistentFlags().BoolVar(&opts.NoInteractive, "no-interactive", false, "Do not prompt for confirmation when deleting resources. Be careful when using this flag!")
+	rootCmd.PersistentFlags().StringVar(&opts.ExceptionsFile, "exceptions-file", "", "File of resources never to report as unused, in addition to the built-in exceptions. kor tui adds to it")
 	rootCmd.PersistentFlags().BoolVarP(&opts.Verbose, "verbose", "v", false, "Verbose output (print empty namespaces)")
 	rootCmd.PersistentFlags().StringVar(&opts.GroupBy, "group-by", "namespace", "Group output by (namespace, resource)")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Path to a config file setting fail-on-findings, max-unused and max-unused-per-namespace (optional)")
+	rootCmd.PersistentFlags().BoolVar(&opts.FailOnFindings, "fail-on-findings", false, "Exit with code 3 only when unused resources exceed the --max-unused thresholds, and summarize them")
+	rootCmd.PersistentFlags().StringToIntVar(&opts.MaxUnused, "max-unused", nil, "Maximum number of unused resources of a type, implies --fail-on-findings. Example: --max-unused configmap=5,secret=0")
+	rootCmd.PersistentFlags().StringToIntVar(&opts.MaxUnusedPerNamespace, "max-unused-per-namespace", nil, "Maximum number of unused resources in a namespace, implies --fail-on-findings. Example: --max-unused-per-namespace ns1=3,ns2=0")
 	rootCmd.PersistentFlags().BoolVar(&opts.ShowReason, "show-reason", false, "Print reason resource is considered unused")
+	rootCmd.PersistentFlags().IntVar(&opts.Concurrency, "concurrency", 4, "Number of namespaces and resource kinds scanned in parallel")
+	rootCmd.PersistentFlags().Float32Var(&clientQPS, "qps", 0, "Maximum queries per second to the Kubernetes API server (0 keeps the client-go default)")
+	rootCmd.PersistentFlags().IntVar(&clientBurst, "burst", 0, "Maximum burst of queries to the Kubernetes API server (0 keeps the client-go default)")
+	rootCmd.PersistentFlags().DurationVar(&opts.Timeout, "timeout", 0, "Maximum duration of a scan, after which the results found so far are returned as incomplete. Example: --timeout=5m (0 means no timeout)")
+	rootCmd.PersistentFlags().DurationVar(&opts.CronJobUnscheduledAge, "cronjob-unscheduled-age", kor.DefaultCronJobUnscheduledAge, "Minimum age of a CronJob that has never been scheduled to be considered unused. Example: --cronjob-unscheduled-age=72h")
+	rootCmd.PersistentFlags().DurationVar(&opts.LeaseStaleAge, "lease-stale-age", kor.DefaultLeaseStaleAge, "Minimum time since a Lease was last renewed for it to be considered unused. Example: --lease-stale-age=72h")
+	rootCmd.PersistentFlags().DurationVar(&opts.ResourceQuotaIdleAge, "resourcequota-idle-age", kor.DefaultResourceQuotaIdleAge, "Minimum time the used values of a ResourceQuota stay zero for it to be considered unused. Example: --resourcequota-idle-age=168h")
 }
 
 func initViper() {
@@ -113,16 +150,28 @@ func initViper() {
 }
 
 func Execute() {
+	addDetectorCmds()
 	_ = rootCmd.ParseFlags(os.Args)
 	if err := filterOptions.Validate(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error while validating filter options '%s'", err)
-		os.Exit(1)
+		os.Exit(exitFatal)
 	}
 	filterOptions.Modify()
-	if err := rootCmd.Execute(); err != nil {
+
+	// The first interrupt cancels the scan, a second one exits right away
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Error while executing your CLI '%s'", err)
-		os.Exit(1)
+		os.Exit(exitFatal)
 	}
+	stop()
+	os.Exit(exitCode)
 }
 
 func addFilterOptionsFlag(cmd *cobra.Command, opts *filters.Options) {
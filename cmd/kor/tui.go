@@ -0,0 +1,431 @@
+package kor
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/spf13/cobra"
+
+	"github.com/yonahd/kor/pkg/kor"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui [resource types]",
+	Short: "Browse the unused resources in a terminal UI, and delete them, flag them as used or add exceptions",
+	Long: `Scan the comma-separated resource types, or all of them, and browse the unused
+resources grouped by --group-by. The findings can be filtered by kind, namespace
+and reason, and the selected one is shown with its YAML and why it is unused.
+
+Keys: d deletes the selected resource after a confirmation, u flags it as used
+with the kor/used=true label, e adds it to the --exceptions-file, / focuses the
+filters and q quits. --dry-run, --cascade, --backup-dir and the audit options
+apply to the deletes as with --delete.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if opts.DeleteFlag {
+			fatal(errors.New("kor tui deletes from the UI, run it without --delete"))
+			return
+		}
+		resourceNames := "all"
+		if len(args) == 1 {
+			resourceNames = args[0]
+		}
+
+		clients, err := getClients()
+		if err != nil {
+			fatal(err)
+			return
+		}
+		fmt.Fprintln(os.Stderr, "Scanning for unused resources...")
+		report, err := scanResources(cmd.Context(), clients, resourceNames)
+		if err != nil {
+			fatal(err)
+			return
+		}
+		actions, err := kor.NewFindingActions(clients, opts)
+		if err != nil {
+			fatal(err)
+			return
+		}
+
+		err = newBrowser(cmd.Context(), report, actions).run()
+		if closeErr := actions.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+		if archive := actions.BackupArchive(); archive != "" {
+			fmt.Fprintf(os.Stderr, "Deleted resources were backed up to %s, run kor restore %s to restore them\n", archive, archive)
+		}
+		for _, scanErr := range report.Errors {
+			fmt.Fprintln(os.Stderr, scanErr)
+		}
+		if err != nil {
+			fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
+
+// browser is the terminal UI of kor tui. The findings keep their index in the
+// tree nodes, and what was done to them in states.
+type browser struct {
+	ctx      context.Context
+	report   *kor.Report
+	actions  *kor.FindingActions
+	findings []kor.Finding
+	states   map[int]string
+
+	app     *tview.Application
+	pages   *tview.Pages
+	tree    *tview.TreeView
+	detail  *tview.TextView
+	header  *tview.TextView
+	status  *tview.TextView
+	filters []*tview.InputField
+}
+
+func newBrowser(ctx context.Context, report *kor.Report, actions *kor.FindingActions) *browser {
+	b := &browser{
+		ctx:      ctx,
+		report:   report,
+		actions:  actions,
+		findings: slices.Clone(report.Findings),
+		states:   make(map[int]string),
+		app:      tview.NewApplication(),
+		pages:    tview.NewPages(),
+		tree:     tview.NewTreeView(),
+		detail:   tview.NewTextView(),
+		header:   tview.NewTextView(),
+		status:   tview.NewTextView(),
+	}
+
+	filterBar := tview.NewFlex()
+	for _, label := range []string{"Kind: ", "Namespace: ", "Reason: "} {
+		filter := tview.NewInputField().SetLabel(label).SetFieldWidth(20)
+		filter.SetChangedFunc(func(string) { b.refresh() })
+		filter.SetDoneFunc(b.filterDone)
+		b.filters = append(b.filters, filter)
+		filterBar.AddItem(filter, 0, 1, false)
+	}
+
+	b.tree.SetRoot(tview.NewTreeNode("")).SetTopLevel(1)
+	b.tree.SetBorder(true).SetTitle(" Unused resources ")
+	b.tree.SetChangedFunc(func(*tview.TreeNode) { b.showDetail() })
+	b.tree.SetInputCapture(b.treeKey)
+	b.detail.SetDynamicColors(true).SetWrap(false).SetScrollable(true)
+	b.detail.SetBorder(true).SetTitle(" Details ")
+	b.detail.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || event.Key() == tcell.KeyTab {
+			b.app.SetFocus(b.tree)
+			return nil
+		}
+		return event
+	})
+	b.status.SetDynamicColors(true)
+
+	body := tview.NewFlex().
+		AddItem(b.tree, 0, 1, true).
+		AddItem(b.detail, 0, 2, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(b.header, 1, 0, false).
+		AddItem(filterBar, 1, 0, false).
+		AddItem(body, 0, 1, true).
+		AddItem(b.status, 1, 0, false)
+	b.pages.AddPage("main", layout, true, true)
+	b.app.SetRoot(b.pages, true).SetFocus(b.tree)
+
+	b.setStatus("")
+	b.refresh()
+	return b
+}
+
+func (b *browser) run() error {
+	return b.app.Run()
+}
+
+// matches tells whether a finding matches the kind, namespace and reason
+// filters, as case-insensitive substrings
+func (b *browser) matches(finding kor.Finding) bool {
+	for i, value := range []string{finding.Kind, finding.Namespace, finding.Reason} {
+		filter := strings.ToLower(strings.TrimSpace(b.filters[i].GetText()))
+		if !strings.Contains(strings.ToLower(value), filter) {
+			return false
+		}
+	}
+	return true
+}
+
+// refresh rebuilds the tree from the filtered findings, grouped by namespace
+// then kind, or kind then namespace with --group-by=resource, and keeps the
+// selected finding selected when it still matches
+func (b *browser) refresh() {
+	selected, hasSelected := b.selected()
+
+	var shown []int
+	for i, finding := range b.findings {
+		if b.matches(finding) {
+			shown = append(shown, i)
+		}
+	}
+	groupKeys := func(finding kor.Finding) (string, string) {
+		namespace := finding.Namespace
+		if namespace == "" {
+			namespace = "(cluster-scoped)"
+		}
+		if opts.GroupBy == "resource" {
+			return finding.Kind, namespace
+		}
+		return namespace, finding.Kind
+	}
+	slices.SortStableFunc(shown, func(i, j int) int {
+		groupI, subgroupI := groupKeys(b.findings[i])
+		groupJ, subgroupJ := groupKeys(b.findings[j])
+		return cmp.Or(cmp.Compare(groupI, groupJ), cmp.Compare(subgroupI, subgroupJ), cmp.Compare(b.findings[i].Name, b.findings[j].Name))
+	})
+
+	root := tview.NewTreeNode("")
+	var group, subgroup, current, first *tview.TreeNode
+	for _, i := range shown {
+		groupName, subgroupName := groupKeys(b.findings[i])
+		if group == nil || group.GetReference() != groupName {
+			group = tview.NewTreeNode(groupName).SetReference(groupName).SetColor(tcell.ColorYellow).SetSelectable(false)
+			root.AddChild(group)
+			subgroup = nil
+		}
+		if subgroup == nil || subgroup.GetReference() != subgroupName {
+			subgroup = tview.NewTreeNode(subgroupName).SetReference(subgroupName).SetColor(tcell.ColorTeal).SetSelectable(false)
+			group.AddChild(subgroup)
+		}
+		node := tview.NewTreeNode(b.nodeText(i)).SetReference(i)
+		if b.states[i] != "" {
+			node.SetColor(tcell.ColorGray)
+		}
+		subgroup.AddChild(node)
+		if first == nil {
+			first = node
+		}
+		if hasSelected && i == selected {
+			current = node
+		}
+	}
+	if current == nil {
+		current = first
+	}
+
+	b.tree.SetRoot(root).SetCurrentNode(current)
+	b.header.SetText(fmt.Sprintf("kor tui: %d unused resources, %d shown, %d scan errors", len(b.findings), len(shown), len(b.report.Errors)))
+	b.showDetail()
+}
+
+func (b *browser) nodeText(i int) string {
+	if state := b.states[i]; state != "" {
+		return fmt.Sprintf("%s (%s)", b.findings[i].Name, state)
+	}
+	return b.findings[i].Name
+}
+
+// selected returns the index of the selected finding
+func (b *browser) selected() (int, bool) {
+	node := b.tree.GetCurrentNode()
+	if node == nil {
+		return 0, false
+	}
+	i, ok := node.GetReference().(int)
+	return i, ok
+}
+
+// showDetail shows the selected finding, why it is unused and its YAML,
+// fetched in the background
+func (b *browser) showDetail() {
+	i, ok := b.selected()
+	if !ok {
+		b.detail.SetText("No unused resources match the filters")
+		return
+	}
+	finding := b.findings[i]
+	var text strings.Builder
+	fmt.Fprintf(&text, "[yellow]Kind:[-]      %s\n", finding.Kind)
+	if finding.Namespace != "" {
+		fmt.Fprintf(&text, "[yellow]Namespace:[-] %s\n", tview.Escape(finding.Namespace))
+	}
+	fmt.Fprintf(&text, "[yellow]Name:[-]      %s\n", tview.Escape(finding.Name))
+	fmt.Fprintf(&text, "[yellow]Reason:[-]    %s\n", tview.Escape(finding.Reason))
+	if state := b.states[i]; state != "" {
+		fmt.Fprintf(&text, "[yellow]Status:[-]    %s\n", state)
+	}
+	text.WriteString("\n")
+	summary := text.String()
+	b.detail.SetText(summary + "Loading...").ScrollToBeginning()
+
+	if finding.Deleted && !finding.DryRun {
+		b.detail.SetText(summary + "The resource was deleted")
+		return
+	}
+	go func() {
+		manifest, err := b.actions.Manifest(b.ctx, finding)
+		b.app.QueueUpdateDraw(func() {
+			if current, ok := b.selected(); !ok || current != i {
+				return
+			}
+			if err != nil {
+				b.detail.SetText(summary + "[red]" + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+			b.detail.SetText(summary + tview.Escape(string(manifest)))
+		})
+	}()
+}
+
+func (b *browser) setStatus(message string) {
+	keys := "[yellow]d[-] delete  [yellow]u[-] flag as used  [yellow]e[-] add exception  [yellow]/[-] filter  [yellow]tab[-] details  [yellow]q[-] quit"
+	if message != "" {
+		keys = message + "  |  " + keys
+	}
+	b.status.SetText(keys)
+}
+
+func (b *browser) setError(err error) {
+	b.setStatus("[red]" + tview.Escape(err.Error()) + "[-]")
+}
+
+func (b *browser) treeKey(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyTab:
+		b.app.SetFocus(b.detail)
+		return nil
+	case tcell.KeyRune:
+	default:
+		return event
+	}
+
+	switch event.Rune() {
+	case 'q':
+		b.app.Stop()
+	case '/':
+		b.app.SetFocus(b.filters[0])
+	case 'd':
+		b.act(b.confirmDelete)
+	case 'u':
+		b.act(b.flag)
+	case 'e':
+		b.act(b.addException)
+	default:
+		return event
+	}
+	return nil
+}
+
+// filterDone moves between the filters on enter and tab, and back to the
+// tree on escape or after the last filter
+func (b *browser) filterDone(key tcell.Key) {
+	current := slices.IndexFunc(b.filters, func(filter *tview.InputField) bool {
+		return filter.HasFocus()
+	})
+	if (key == tcell.KeyEnter || key == tcell.KeyTab) && current+1 < len(b.filters) {
+		b.app.SetFocus(b.filters[current+1])
+		return
+	}
+	b.app.SetFocus(b.tree)
+}
+
+// act runs an action on the selected finding, unless something was already
+// done to it
+func (b *browser) act(action func(i int)) {
+	i, ok := b.selected()
+	if !ok {
+		return
+	}
+	if state := b.states[i]; state != "" {
+		b.setStatus(fmt.Sprintf("%s %s is already %s", b.findings[i].Kind, b.findings[i].Name, state))
+		return
+	}
+	action(i)
+}
+
+func (b *browser) confirmDelete(i int) {
+	finding := b.findings[i]
+	question := fmt.Sprintf("Delete %s %s?", finding.Kind, qualifiedName(finding.Namespace, finding.Name))
+	if opts.DryRun != "" {
+		question += fmt.Sprintf(" (%s dry run)", opts.DryRun)
+	}
+	dependents, err := b.actions.Dependents(b.ctx, finding)
+	if err != nil {
+		b.setError(err)
+		return
+	}
+	if len(dependents) != 0 {
+		question += "\n\nThe garbage collector also removes:"
+		var addNodes func(nodes []kor.CascadeNode, indent string)
+		addNodes = func(nodes []kor.CascadeNode, indent string) {
+			for _, node := range nodes {
+				question += fmt.Sprintf("\n%s%s %s", indent, node.Kind, qualifiedName(node.Namespace, node.Name))
+				addNodes(node.Dependents, indent+"  ")
+			}
+		}
+		addNodes(dependents, "")
+	}
+	modal := tview.NewModal().
+		SetText(question).
+		AddButtons([]string{"Delete", "Cancel"}).
+		SetDoneFunc(func(_ int, label string) {
+			b.pages.RemovePage("confirm")
+			b.app.SetFocus(b.tree)
+			if label == "Delete" {
+				b.delete(i)
+			}
+		})
+	modal.SetFocus(1)
+	b.pages.AddPage("confirm", modal, false, true)
+	b.app.SetFocus(modal)
+}
+
+func (b *browser) delete(i int) {
+	finding, err := b.actions.Delete(b.ctx, b.findings[i])
+	if err != nil {
+		b.setError(err)
+		return
+	}
+	b.findings[i] = finding
+	b.states[i] = "deleted"
+	if finding.DryRun {
+		b.states[i] = "deleted, dry run"
+	}
+	b.setStatus(fmt.Sprintf("Deleted %s %s", finding.Kind, finding.Name))
+	b.refresh()
+}
+
+func (b *browser) flag(i int) {
+	finding := b.findings[i]
+	if err := b.actions.Flag(b.ctx, finding); err != nil {
+		b.setError(err)
+		return
+	}
+	b.states[i] = "flagged as used"
+	b.setStatus(fmt.Sprintf("Flagged %s %s as used", finding.Kind, finding.Name))
+	b.refresh()
+}
+
+func (b *browser) addException(i int) {
+	if opts.ExceptionsFile == "" {
+		b.setError(errors.New("run kor tui with --exceptions-file to add exceptions"))
+		return
+	}
+	finding := b.findings[i]
+	exception := kor.Exception{Kind: finding.Kind, Namespace: finding.Namespace, Name: finding.Name}
+	if err := kor.AddException(opts.ExceptionsFile, exception); err != nil {
+		b.setError(err)
+		return
+	}
+	b.states[i] = "excepted"
+	b.setStatus(fmt.Sprintf("Added %s %s to %s", finding.Kind, finding.Name, opts.ExceptionsFile))
+	b.refresh()
+}
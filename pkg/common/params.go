@@ -1,13 +1,32 @@
 package common
 
+import "time"
+
 type Opts struct {
-	DeleteFlag    bool
-	NoInteractive bool
-	Verbose       bool
-	WebhookURL    string
-	Channel       string
-	Token         string
-	GroupBy       string
-	ShowReason    bool
-	Namespaced    bool
+	DeleteFlag            bool
+	DryRun                string
+	Cascade               string
+	BackupDir             string
+	AuditLog              string
+	AuditEvents           bool
+	AuditUser             string
+	Quarantine            string
+	QuarantineGracePeriod time.Duration
+	NoInteractive         bool
+	ExceptionsFile        string
+	Verbose               bool
+	WebhookURL            string
+	Channel               string
+	Token                 string
+	GroupBy               string
+	ShowReason            bool
+	Namespaced            bool
+	CronJobUnscheduledAge time.Duration
+	LeaseStaleAge         time.Duration
+	ResourceQuotaIdleAge  time.Duration
+	Concurrency           int
+	Timeout               time.Duration
+	FailOnFindings        bool
+	MaxUnused             map[string]int
+	MaxUnusedPerNamespace map[string]int
 }
@@ -42,8 +42,9 @@ type Options struct {
 	// IgnoreOwnerReferences skips any resource that has ownerReferences set (for all resource types)
 	IgnoreOwnerReferences bool
 
-	namespace []string
-	once      sync.Once
+	namespace    []string
+	namespaceErr error
+	once         sync.Once
 }
 
 // NewFilterOptions returns a new FilterOptions instance with default values
@@ -111,7 +112,7 @@ func (o *Options) Modify() {
 }
 
 // Namespaces returns the namespaces, only called once
-func (o *Options) Namespaces(clientset kubernetes.Interface) []string {
+func (o *Options) Namespaces(ctx context.Context, clientset kubernetes.Interface) ([]string, error) {
 	o.once.Do(func() {
 		namespaces := make([]string, 0)
 		namespacesMap := make(map[string]bool)
@@ -128,7 +129,7 @@ func (o *Options) Namespaces(clientset kubernetes.Interface) []string {
 
 			for _, ns := range includeNamespaces {
 
-				_, err := clientset.CoreV1().Namespaces().Get(context.TODO(), ns, metav1.GetOptions{})
+				_, err := clientset.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
 				if err == nil {
 					namespacesMap[ns] = true
 				} else {
@@ -136,9 +137,9 @@ func (o *Options) Namespaces(clientset kubernetes.Interface) []string {
 				}
 			}
 		} else {
-			namespaceList, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+			namespaceList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to retrieve namespaces: %v\n", err)
+				o.namespaceErr = fmt.Errorf("failed to retrieve namespaces: %w", err)
 				return
 			}
 
@@ -158,7 +159,7 @@ func (o *Options) Namespaces(clientset kubernetes.Interface) []string {
 		}
 		o.namespace = namespaces
 	})
-	return o.namespace
+	return o.namespace, o.namespaceErr
 }
 
 func (o *Options) modifyLabels() {
@@ -0,0 +1,138 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+// FindingActions acts on the findings of a report one at a time, as kor tui
+// does, sharing a backup archive and an audit log until Close. Deletes follow
+// opts.DryRun, opts.Cascade, opts.BackupDir and the audit options without
+// asking anything, callers confirm them first. Nothing is printed.
+type FindingActions struct {
+	clients Clients
+	opts    common.Opts
+	backup  *backupArchive
+	audit   *auditLog
+}
+
+func NewFindingActions(clients Clients, opts common.Opts) (*FindingActions, error) {
+	if err := ValidateDryRun(opts.DryRun); err != nil {
+		return nil, err
+	}
+	if err := ValidateCascade(opts.Cascade); err != nil {
+		return nil, err
+	}
+	audit, err := newAuditLog(opts, clients.Clientset)
+	if err != nil {
+		return nil, err
+	}
+	return &FindingActions{clients: clients, opts: opts, backup: newBackupArchive(opts), audit: audit}, nil
+}
+
+func (a *FindingActions) detector(finding Finding) (Detector, error) {
+	detector, ok := LookupDetector(finding.Kind)
+	if !ok {
+		return nil, fmt.Errorf("resource type %q is not supported", finding.Kind)
+	}
+	return detector, nil
+}
+
+// Manifest returns the object of a finding as YAML, without its managed fields
+func (a *FindingActions) Manifest(ctx context.Context, finding Finding) ([]byte, error) {
+	detector, err := a.detector(finding)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := getObject(ctx, detector, a.clients, finding.Namespace, finding.Name)
+	if err != nil {
+		return nil, err
+	}
+	u, err := toUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(u.Object, "metadata", "managedFields")
+	return yaml.Marshal(u.Object)
+}
+
+// Delete deletes the object of a finding, saving it to the backup archive
+// first, and returns the finding marked as deleted
+func (a *FindingActions) Delete(ctx context.Context, finding Finding) (Finding, error) {
+	detector, err := a.detector(finding)
+	if err != nil {
+		return finding, err
+	}
+	resourceType := detector.Name()
+	record := func(result string, obj runtime.Object, err error) error {
+		return a.audit.record(ctx, newAuditEntry(AuditDelete, result, finding, err), obj)
+	}
+
+	if a.opts.DryRun != DryRunClient {
+		obj, err := fetchForDeletion(a.backup, a.audit, func() (runtime.Object, error) {
+			return getObject(ctx, detector, a.clients, finding.Namespace, finding.Name)
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to back up %s %s in namespace %s, not deleting it: %w", resourceType, finding.Name, finding.Namespace, err)
+			return finding, errors.Join(err, record(AuditFailed, obj, err))
+		}
+		deleteOptions := metav1.DeleteOptions{DryRun: dryRunOption(a.opts.DryRun), PropagationPolicy: propagationPolicy(a.opts.Cascade)}
+		if err := detector.Delete(ctx, a.clients, finding.Namespace, finding.Name, deleteOptions); err != nil {
+			return finding, errors.Join(deleteError(a.opts.DryRun, resourceType, finding, err), record(AuditFailed, obj, err))
+		}
+		if err := record(AuditSucceeded, obj, nil); err != nil {
+			return finding, err
+		}
+	}
+	finding.Deleted = true
+	finding.DryRun = a.opts.DryRun != ""
+	return finding, nil
+}
+
+// Dependents returns the objects the garbage collector removes when the
+// object of a finding is deleted, none when opts.Cascade orphans them
+func (a *FindingActions) Dependents(ctx context.Context, finding Finding) ([]CascadeNode, error) {
+	if a.opts.Cascade == CascadeOrphan {
+		return nil, nil
+	}
+	preview, err := cascadePreview(ctx, a.clients, []Finding{finding})
+	if err != nil || len(preview) == 0 {
+		return nil, err
+	}
+	return preview[0].Dependents, nil
+}
+
+// Flag labels the object of a finding with kor/used=true, so it is no longer
+// reported as unused
+func (a *FindingActions) Flag(ctx context.Context, finding Finding) error {
+	detector, err := a.detector(finding)
+	if err != nil {
+		return err
+	}
+	if err := detector.Flag(ctx, a.clients, finding.Namespace, finding.Name); err != nil {
+		err = fmt.Errorf("failed to flag %s %s in namespace %s as in use: %w", finding.Kind, finding.Name, finding.Namespace, err)
+		return errors.Join(err, a.audit.record(ctx, newAuditEntry(AuditFlag, AuditFailed, finding, err), nil))
+	}
+	obj := a.audit.object(func() (runtime.Object, error) {
+		return getObject(ctx, detector, a.clients, finding.Namespace, finding.Name)
+	})
+	return a.audit.record(ctx, newAuditEntry(AuditFlag, AuditSucceeded, finding, nil), obj)
+}
+
+// BackupArchive returns the file the deleted objects were saved to, empty
+// when nothing was saved
+func (a *FindingActions) BackupArchive() string {
+	return a.backup.Path()
+}
+
+func (a *FindingActions) Close() error {
+	return errors.Join(a.backup.Close(), a.audit.Close())
+}
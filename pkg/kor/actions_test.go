@@ -0,0 +1,71 @@
+package kor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+func TestFindingActions(t *testing.T) {
+	clientset := fake.NewClientset()
+	for _, name := range []string{"configmap-1", "configmap-2"} {
+		_, err := clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, name, AppLabels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+
+	backupDir := t.TempDir()
+	actions, err := NewFindingActions(Clients{Clientset: clientset}, common.Opts{BackupDir: backupDir})
+	if err != nil {
+		t.Fatalf("Error creating actions: %v", err)
+	}
+	toDelete := Finding{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-1"}
+	toFlag := Finding{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-2"}
+
+	manifest, err := actions.Manifest(context.TODO(), toDelete)
+	if err != nil {
+		t.Fatalf("Error getting manifest: %v", err)
+	}
+	if !strings.Contains(string(manifest), "kind: ConfigMap") || !strings.Contains(string(manifest), "name: configmap-1") {
+		t.Errorf("Expected the YAML of configmap-1, got %s", manifest)
+	}
+
+	deleted, err := actions.Delete(context.TODO(), toDelete)
+	if err != nil {
+		t.Fatalf("Error deleting: %v", err)
+	}
+	if !deleted.Deleted || deleted.DryRun {
+		t.Errorf("Expected configmap-1 to be marked deleted, got %+v", deleted)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "configmap-1", v1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected configmap-1 to be deleted, got %v", err)
+	}
+	if _, err := actions.Delete(context.TODO(), toDelete); err == nil {
+		t.Error("Expected an error deleting configmap-1 again")
+	}
+
+	if err := actions.Flag(context.TODO(), toFlag); err != nil {
+		t.Fatalf("Error flagging: %v", err)
+	}
+	configmap, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "configmap-2", v1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting configmap-2: %v", err)
+	}
+	if configmap.Labels["kor/used"] != "true" {
+		t.Errorf("Expected configmap-2 to be flagged as in use, got labels %v", configmap.Labels)
+	}
+
+	if err := actions.Close(); err != nil {
+		t.Fatalf("Error closing actions: %v", err)
+	}
+	if !strings.HasPrefix(actions.BackupArchive(), backupDir) {
+		t.Errorf("Expected configmap-1 to be backed up to %s, got %q", backupDir, actions.BackupArchive())
+	}
+}
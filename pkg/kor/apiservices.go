@@ -0,0 +1,106 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// apiServiceGVR is the resource of the APIServices, client-go has no typed
+// client for the apiregistration.k8s.io group
+var apiServiceGVR = schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+
+// apiServiceUnavailableReason returns the reason of the Available=False
+// condition of an APIService, or an empty string when it is available
+func apiServiceUnavailableReason(apiService *unstructured.Unstructured) string {
+	conditions, _, _ := unstructured.NestedSlice(apiService.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != "Available" || condition["status"] != "False" {
+			continue
+		}
+		reason, _ := condition["reason"].(string)
+		if message, _ := condition["message"].(string); message != "" {
+			reason = message
+		}
+		if reason == "" {
+			return "APIService is not available"
+		}
+		return fmt.Sprintf("APIService is not available: %s", reason)
+	}
+	return ""
+}
+
+func processAPIServices(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	apiServices, err := dynamicClient.Resource(apiServiceGVR).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedAPIServices []ResourceInfo
+
+	for _, apiService := range apiServices.Items {
+		// Skip resources with ownerReferences if the general flag is set
+		if filterOpts.IgnoreOwnerReferences && len(apiService.GetOwnerReferences()) > 0 {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&apiService).Run(filterOpts); pass {
+			continue
+		}
+
+		if apiService.GetLabels()["kor/used"] == "false" {
+			reason := "Marked with unused label"
+			unusedAPIServices = append(unusedAPIServices, ResourceInfo{Name: apiService.GetName(), Reason: reason})
+			continue
+		}
+
+		// local APIServices, without a Service, are served by the kube-apiserver itself
+		serviceName, _, _ := unstructured.NestedString(apiService.Object, "spec", "service", "name")
+		if serviceName != "" {
+			serviceNamespace, _, _ := unstructured.NestedString(apiService.Object, "spec", "service", "namespace")
+			_, err := clientset.CoreV1().Services(serviceNamespace).Get(ctx, serviceName, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				reason := fmt.Sprintf("Service %s/%s does not exist", serviceNamespace, serviceName)
+				unusedAPIServices = append(unusedAPIServices, ResourceInfo{Name: apiService.GetName(), Reason: reason})
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get service %s/%s: %v", serviceNamespace, serviceName, err)
+			}
+		}
+
+		if reason := apiServiceUnavailableReason(&apiService); reason != "" {
+			unusedAPIServices = append(unusedAPIServices, ResourceInfo{Name: apiService.GetName(), Reason: reason})
+		}
+	}
+
+	return unusedAPIServices, nil
+}
+
+func detectAPIServices(ctx context.Context, clients Clients, _ string, filterOpts *filters.Options, _ common.Opts) ([]ResourceInfo, error) {
+	if err := requireClientset(clients); err != nil {
+		return nil, err
+	}
+	return processAPIServices(ctx, clients.Clientset, clients.Dynamic, filterOpts)
+}
+
+var apiServiceDetector = newDynamicDetector("APIService", []string{"apiservice", "apiservices"}, ClusterScoped, detectAPIServices, apiServiceGVR)
+
+func init() {
+	MustRegisterDetector(apiServiceDetector)
+}
+
+func GetUnusedAPIServices(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{apiServiceDetector}, filterOpts, Clients{Clientset: clientset, Dynamic: dynamicClient}, outputFormat, opts)
+}
@@ -0,0 +1,128 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestAPIService(name, serviceNamespace, serviceName, available string) *unstructured.Unstructured {
+	apiService := CreateTestUnstructered("APIService", "apiregistration.k8s.io/v1", "", name)
+	if serviceName != "" {
+		apiService.Object["spec"] = map[string]interface{}{
+			"service": map[string]interface{}{"namespace": serviceNamespace, "name": serviceName},
+		}
+	}
+	apiService.Object["status"] = map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Available", "status": available, "reason": "FailedDiscoveryCheck"},
+		},
+	}
+	return apiService
+}
+
+func createTestAPIServices(t *testing.T) (*fake.Clientset, *dynamicfake.FakeDynamicClient) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Services(testNamespace).Create(context.TODO(), CreateTestService(testNamespace, "metrics-server"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake service: %v", err)
+	}
+
+	scheme := runtime.NewScheme()
+	gvrToListKind := map[schema.GroupVersionResource]string{
+		apiServiceGVR: "APIServiceList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind,
+		// served by the kube-apiserver
+		createTestAPIService("v1.apps", "", "", "True"),
+		createTestAPIService("v1beta1.metrics.k8s.io", testNamespace, "metrics-server", "True"),
+		createTestAPIService("v1beta1.unavailable.example.com", testNamespace, "metrics-server", "False"),
+		createTestAPIService("v1beta1.missing.example.com", testNamespace, "missing-service", "False"),
+	)
+
+	return clientset, dynamicClient
+}
+
+func TestProcessAPIServices(t *testing.T) {
+	clientset, dynamicClient := createTestAPIServices(t)
+
+	unused, err := processAPIServices(context.TODO(), clientset, dynamicClient, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Error processing APIServices: %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "v1beta1.missing.example.com", Reason: "Service test-namespace/missing-service does not exist"},
+		{Name: "v1beta1.unavailable.example.com", Reason: "APIService is not available: FailedDiscoveryCheck"},
+	}
+	sort.Slice(unused, func(i, j int) bool { return unused[i].Name < unused[j].Name })
+	if !reflect.DeepEqual(unused, expected) {
+		t.Errorf("Expected unused APIServices %v, got %v", expected, unused)
+	}
+}
+
+func TestGetUnusedAPIServices(t *testing.T) {
+	clientset, dynamicClient := createTestAPIServices(t)
+
+	opts := common.Opts{
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedAPIServices(context.TODO(), &filters.Options{}, clientset, dynamicClient, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedAPIServices: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		"": {
+			"APIService": {"v1beta1.missing.example.com", "v1beta1.unavailable.example.com"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+	sort.Strings(actualOutput[""]["APIService"])
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output %+v, but got %+v", expectedOutput, actualOutput)
+	}
+}
+
+func TestDeleteAPIService(t *testing.T) {
+	clientset, dynamicClient := createTestAPIServices(t)
+	clients := Clients{Clientset: clientset, Dynamic: dynamicClient}
+
+	if err := apiServiceDetector.Delete(context.TODO(), clients, "", "v1beta1.missing.example.com", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Error deleting APIService: %v", err)
+	}
+
+	objects, err := listObjects(context.TODO(), apiServiceDetector, clients, "", "")
+	if err != nil {
+		t.Fatalf("Error listing APIServices: %v", err)
+	}
+	for _, object := range objects {
+		if object.GetName() == "v1beta1.missing.example.com" {
+			t.Error("Expected v1beta1.missing.example.com to be deleted")
+		}
+	}
+	if len(objects) != 3 {
+		t.Errorf("Expected 3 APIServices left, got %d", len(objects))
+	}
+}
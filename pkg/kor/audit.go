@@ -0,0 +1,205 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/reference"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+// Actions recorded in the audit log
+const (
+	AuditDelete           = "delete"
+	AuditRemoveFinalizers = "remove-finalizers"
+	AuditFlag             = "flag"
+	AuditQuarantine       = "quarantine"
+	AuditRelease          = "release"
+)
+
+// Results of the actions recorded in the audit log. Cascaded objects were
+// removed by the garbage collector along with a deleted owner.
+const (
+	AuditSucceeded = "succeeded"
+	AuditFailed    = "failed"
+	AuditSkipped   = "skipped"
+	AuditCascaded  = "cascaded"
+)
+
+// AuditEntry is a line of the --audit-log
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	User      string    `json:"user,omitempty"`
+	Action    string    `json:"action"`
+	Result    string    `json:"result"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	// DryRun is "server" for the actions sent to the API server as a dry run
+	DryRun string `json:"dryRun,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func newAuditEntry(action, result string, finding Finding, err error) AuditEntry {
+	entry := AuditEntry{Action: action, Result: result, Kind: finding.Kind, Namespace: finding.Namespace, Name: finding.Name, Reason: finding.Reason}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+// auditLog records the actions taken on the cluster to opts.AuditLog as JSON
+// lines, and as Events on the namespace of the objects with opts.AuditEvents.
+// Client dry runs send nothing to the cluster and are not recorded.
+type auditLog struct {
+	user      string
+	dryRun    string
+	clientset kubernetes.Interface
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// newAuditLog opens the audit log of opts, appending to an existing file. It
+// returns nil when auditing is disabled or it is a client dry run.
+func newAuditLog(opts common.Opts, clientset kubernetes.Interface) (*auditLog, error) {
+	if opts.AuditLog == "" && !opts.AuditEvents || opts.DryRun == DryRunClient {
+		return nil, nil
+	}
+	audit := &auditLog{user: opts.AuditUser, dryRun: opts.DryRun}
+	if opts.AuditEvents {
+		audit.clientset = clientset
+	}
+	if opts.AuditLog != "" {
+		file, err := os.OpenFile(opts.AuditLog, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log: %w", err)
+		}
+		audit.file = file
+	}
+	return audit, nil
+}
+
+// object returns the object get fetches for an entry, or nil when there is
+// no audit log or it can't be fetched
+func (a *auditLog) object(get func() (runtime.Object, error)) runtime.Object {
+	if a == nil {
+		return nil
+	}
+	obj, err := get()
+	if err != nil {
+		return nil
+	}
+	return obj
+}
+
+// record writes an entry about obj, which sets its UID when not nil
+func (a *auditLog) record(ctx context.Context, entry AuditEntry, obj runtime.Object) error {
+	if a == nil {
+		return nil
+	}
+	entry.Time = time.Now().UTC()
+	entry.User = a.user
+	entry.DryRun = a.dryRun
+	if obj != nil {
+		if accessor, err := meta.Accessor(obj); err == nil {
+			entry.UID = accessor.GetUID()
+		}
+	}
+
+	if a.file != nil {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		a.mu.Lock()
+		_, err = a.file.Write(append(data, '\n'))
+		a.mu.Unlock()
+		if err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+	}
+	if a.clientset != nil && entry.DryRun == "" {
+		if _, err := a.clientset.CoreV1().Events(auditEventNamespace(entry)).Create(ctx, newAuditEvent(entry, obj), metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create audit event for %s %s: %w", entry.Kind, entry.Name, err)
+		}
+	}
+	return nil
+}
+
+func (a *auditLog) Close() error {
+	if a == nil || a.file == nil {
+		return nil
+	}
+	return a.file.Close()
+}
+
+// auditEventNamespace returns the namespace of the event about an entry,
+// default for cluster-scoped objects as kubectl does
+func auditEventNamespace(entry AuditEntry) string {
+	if entry.Namespace == "" {
+		return metav1.NamespaceDefault
+	}
+	return entry.Namespace
+}
+
+// newAuditEvent returns the Event of an entry, its reason being the action
+// and result, such as KorDeleteSucceeded
+func newAuditEvent(entry AuditEntry, obj runtime.Object) *corev1.Event {
+	involved := corev1.ObjectReference{Kind: entry.Kind, Namespace: entry.Namespace, Name: entry.Name, UID: entry.UID}
+	if obj != nil {
+		if ref, err := reference.GetReference(backupScheme, obj); err == nil {
+			involved = *ref
+		}
+	}
+
+	reason := "Kor"
+	for _, word := range strings.Split(entry.Action+"-"+entry.Result, "-") {
+		reason += strings.ToUpper(word[:1]) + word[1:]
+	}
+	message := fmt.Sprintf("kor %s of %s %s %s", entry.Action, entry.Kind, entry.Name, entry.Result)
+	if entry.User != "" {
+		message += ", run by " + entry.User
+	}
+	if entry.Error != "" {
+		message += ": " + entry.Error
+	} else if entry.Reason != "" {
+		message += ": " + entry.Reason
+	}
+	eventType := corev1.EventTypeNormal
+	if entry.Result == AuditFailed {
+		eventType = corev1.EventTypeWarning
+	}
+
+	now := metav1.NewTime(entry.Time)
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// named as by the client-go event recorder
+			Name:      fmt.Sprintf("%v.%x", entry.Name, entry.Time.UnixNano()),
+			Namespace: auditEventNamespace(entry),
+		},
+		InvolvedObject:      involved,
+		Reason:              reason,
+		Message:             message,
+		Type:                eventType,
+		Source:              corev1.EventSource{Component: "kor"},
+		ReportingController: "kor",
+		FirstTimestamp:      now,
+		LastTimestamp:       now,
+		Count:               1,
+	}
+}
@@ -0,0 +1,176 @@
+package kor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestGetUnusedReportAuditLog(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	for _, name := range []string{"configmap-1", "configmap-2"} {
+		configmap := CreateTestConfigmap(testNamespace, name, AppLabels)
+		configmap.UID = types.UID("uid-" + name)
+		_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), configmap, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+	clientset.PrependReactor("delete", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.DeleteAction).GetName() == "configmap-2" {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "configmap-2", nil)
+		}
+		return false, nil, nil
+	})
+
+	auditLog := t.TempDir() + "/audit.jsonl"
+	opts := common.Opts{DeleteFlag: true, NoInteractive: true, AuditLog: auditLog, AuditEvents: true, AuditUser: "alice"}
+	report, err := GetUnusedReport(context.TODO(), []Detector{configMapDetector}, &filters.Options{}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Errors) != 1 {
+		t.Errorf("Expected the failed delete of configmap-2 in the errors, got %v", report.Errors)
+	}
+
+	file, err := os.Open(auditLog)
+	if err != nil {
+		t.Fatalf("Error opening audit log: %v", err)
+	}
+	defer file.Close()
+	results := map[string]AuditEntry{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Error parsing audit log line %q: %v", scanner.Text(), err)
+		}
+		results[entry.Name] = entry
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected an entry per configmap, got %v", results)
+	}
+	for name, result := range map[string]string{"configmap-1": AuditSucceeded, "configmap-2": AuditFailed} {
+		entry := results[name]
+		if entry.Action != AuditDelete || entry.Result != result || entry.User != "alice" || entry.UID != types.UID("uid-"+name) || entry.Time.IsZero() || entry.Reason == "" {
+			t.Errorf("Expected a %s delete of %s by alice with its UID, time and reason, got %+v", result, name, entry)
+		}
+	}
+	if results["configmap-2"].Error == "" {
+		t.Errorf("Expected the error of the failed delete, got %+v", results["configmap-2"])
+	}
+
+	events, err := clientset.CoreV1().Events(testNamespace).List(context.TODO(), v1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Error listing events: %v", err)
+	}
+	reasons := map[string]corev1.Event{}
+	for _, event := range events.Items {
+		reasons[event.InvolvedObject.Name] = event
+	}
+	if event := reasons["configmap-1"]; event.Reason != "KorDeleteSucceeded" || event.Type != corev1.EventTypeNormal || event.InvolvedObject.Kind != "ConfigMap" || event.InvolvedObject.UID != "uid-configmap-1" {
+		t.Errorf("Expected a KorDeleteSucceeded event on configmap-1, got %+v", event)
+	}
+	if event := reasons["configmap-2"]; event.Reason != "KorDeleteFailed" || event.Type != corev1.EventTypeWarning {
+		t.Errorf("Expected a KorDeleteFailed warning on configmap-2, got %+v", event)
+	}
+}
+
+func TestNewAuditLogClientDryRun(t *testing.T) {
+	auditLog := t.TempDir() + "/audit.jsonl"
+	audit, err := newAuditLog(common.Opts{AuditLog: auditLog, DryRun: DryRunClient}, nil)
+	if err != nil || audit != nil {
+		t.Errorf("Expected no audit log for a client dry run, got %v, %v", audit, err)
+	}
+}
+
+func TestAuditLogSkipped(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	for _, name := range []string{"configmap-1", "configmap-2"} {
+		configmap := CreateTestConfigmap(testNamespace, name, AppLabels)
+		configmap.UID = types.UID("uid-" + name)
+		configmap.ResourceVersion = "1"
+		_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), configmap, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+	clients := Clients{Clientset: clientset}
+
+	// configmap-1 is declined in the review
+	input, output := reviewInput, reviewOutput
+	defer func() { reviewInput, reviewOutput = input, output }()
+	reviewInput = strings.NewReader("2\n\ny\n")
+	reviewOutput = &bytes.Buffer{}
+	reviewLog := t.TempDir() + "/review.jsonl"
+	opts := common.Opts{DeleteFlag: true, AuditLog: reviewLog}
+	if _, err := GetUnusedReport(context.TODO(), []Detector{configMapDetector}, &filters.Options{}, clients, opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := AuditEntry{Action: AuditDelete, Result: AuditSkipped, Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-1", UID: "uid-configmap-1", Reason: "not deleted - user declined"}
+	assertAuditEntry(t, reviewLog, expected)
+
+	// configmap-1 is gone when the plan is applied
+	if err := clientset.CoreV1().ConfigMaps(testNamespace).Delete(context.TODO(), "configmap-1", v1.DeleteOptions{}); err != nil {
+		t.Fatalf("Error deleting fake configmap: %v", err)
+	}
+	plan := &Plan{Items: []PlanItem{{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-1", UID: "uid-configmap-1", ResourceVersion: "1"}}}
+	planLog := t.TempDir() + "/plan.jsonl"
+	if _, err := ApplyPlan(context.TODO(), plan, clients, common.Opts{AuditLog: planLog}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected.Reason = "deleted since the plan was made"
+	assertAuditEntry(t, planLog, expected)
+}
+
+// assertAuditEntry checks that the audit log at path has an entry about the
+// object of expected with its action, result, reason and UID
+func assertAuditEntry(t *testing.T, path string, expected AuditEntry) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading audit log: %v", err)
+	}
+	for line := range strings.Lines(string(data)) {
+		var entry AuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Error parsing audit log line %q: %v", line, err)
+		}
+		if entry.Kind != expected.Kind || entry.Namespace != expected.Namespace || entry.Name != expected.Name {
+			continue
+		}
+		entry.Time, entry.User = time.Time{}, ""
+		if entry != expected {
+			t.Errorf("Expected the audit entry %+v, got %+v", expected, entry)
+		}
+		return
+	}
+	t.Errorf("Expected an audit entry about %s %s, got:\n%s", expected.Kind, expected.Name, data)
+}
@@ -0,0 +1,308 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery/cached/memory"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+// backupScheme resolves the apiVersion and kind of the typed objects saved to a backup
+var backupScheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(backupScheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(backupScheme))
+}
+
+// backupArchive is the file the objects are saved to before they are deleted,
+// as a stream of YAML documents. It is created in the --backup-dir on the
+// first object saved, so scans that delete nothing leave no archive behind.
+type backupArchive struct {
+	dir string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// newBackupArchive returns the archive of a deletion, or nil when backups are
+// disabled or it is a dry run, which changes nothing
+func newBackupArchive(opts common.Opts) *backupArchive {
+	if opts.BackupDir == "" || opts.DryRun != "" {
+		return nil
+	}
+	return &backupArchive{dir: opts.BackupDir}
+}
+
+// save appends obj to the archive, without its server-managed fields
+func (b *backupArchive) save(obj runtime.Object) error {
+	u, err := toBackupObject(obj)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(u.Object)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.file == nil {
+		if err := os.MkdirAll(b.dir, 0o700); err != nil {
+			return err
+		}
+		// the archive may hold secrets, it is only readable by its owner
+		b.file, err = os.CreateTemp(b.dir, "kor-backup-"+time.Now().UTC().Format("20060102T150405Z")+"-*.yaml")
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := b.file.Write(append([]byte("---\n"), data...)); err != nil {
+		return err
+	}
+	// the object is deleted right after, it must be on disk first
+	return b.file.Sync()
+}
+
+// Path returns the path of the archive, empty when nothing was saved
+func (b *backupArchive) Path() string {
+	if b == nil || b.file == nil {
+		return ""
+	}
+	return b.file.Name()
+}
+
+func (b *backupArchive) Close() error {
+	if b == nil || b.file == nil {
+		return nil
+	}
+	return b.file.Close()
+}
+
+// fetchForDeletion fetches an object about to be deleted when it is backed up
+// or audited, and saves it to backup. Only a failed backup prevents the
+// deletion, an object that can't be fetched for the audit is returned as nil.
+func fetchForDeletion(backup *backupArchive, audit *auditLog, get func() (runtime.Object, error)) (runtime.Object, error) {
+	if backup == nil && audit == nil {
+		return nil, nil
+	}
+	obj, err := get()
+	if err != nil {
+		if backup == nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if backup != nil {
+		if err := backup.save(obj); err != nil {
+			return obj, err
+		}
+	}
+	return obj, nil
+}
+
+// toUnstructured converts obj to an unstructured copy with its apiVersion
+// and kind set
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.DeepCopy(), nil
+	}
+	gvks, _, err := backupScheme.ObjectKinds(obj)
+	if err != nil {
+		return nil, err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvks[0])
+	return u, nil
+}
+
+// toBackupObject converts obj to an unstructured object that can be created
+// again, with its apiVersion and kind set and its server-managed fields and
+// owner references removed
+func toBackupObject(obj runtime.Object) (*unstructured.Unstructured, error) {
+	u, err := toUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp", "deletionGracePeriodSeconds", "managedFields", "selfLink"} {
+		unstructured.RemoveNestedField(u.Object, "metadata", field)
+	}
+	// the owners are matched by uid, a restored object pointing at an owner
+	// that is gone would be garbage collected right away
+	unstructured.RemoveNestedField(u.Object, "metadata", "ownerReferences")
+	unstructured.RemoveNestedField(u.Object, "status")
+	// cluster IPs are allocated by the API server, the same one may be taken by now
+	if u.GetKind() == "Service" && u.GetAPIVersion() == "v1" {
+		if clusterIP, _, _ := unstructured.NestedString(u.Object, "spec", "clusterIP"); clusterIP != "None" {
+			unstructured.RemoveNestedField(u.Object, "spec", "clusterIP")
+			unstructured.RemoveNestedField(u.Object, "spec", "clusterIPs")
+		}
+	}
+	return u, nil
+}
+
+// RestoreSelection picks the objects of a backup archive to restore. Empty
+// fields match every object; cluster-scoped objects are skipped when
+// Namespaces is set.
+type RestoreSelection struct {
+	// Kinds are resource types, matched like the kor commands, e.g. "cm" or "secret"
+	Kinds      []string
+	Namespaces []string
+	Names      []string
+}
+
+// Outcomes of restoring an object
+const (
+	RestoreCreated = "created"
+	RestoreExists  = "already exists"
+	RestoreFailed  = "failed"
+)
+
+// RestoreResult is the outcome of restoring a single object of an archive
+type RestoreResult struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// readBackupArchive returns the objects of an archive in the order they were saved
+func readBackupArchive(archive string) ([]*unstructured.Unstructured, error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var objects []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(file, 4096)
+	for {
+		var content map[string]any
+		if err := decoder.Decode(&content); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, fmt.Errorf("failed to read backup archive %s: %w", archive, err)
+		}
+		if len(content) != 0 {
+			objects = append(objects, &unstructured.Unstructured{Object: content})
+		}
+	}
+}
+
+// restoreOrder creates namespaces and CRDs ahead of the objects they may hold
+func restoreOrder(u *unstructured.Unstructured) int {
+	switch u.GroupVersionKind().GroupKind().String() {
+	case "Namespace":
+		return 0
+	case "CustomResourceDefinition.apiextensions.k8s.io":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// RestoreBackup creates again the objects of a --backup-dir archive matching
+// selection. Objects that already exist are left untouched. The failures of
+// single objects are reported in their RestoreResult, the returned error being
+// kept for archives that can't be read.
+func RestoreBackup(ctx context.Context, archive string, clients Clients, selection RestoreSelection) ([]RestoreResult, error) {
+	objects, err := readBackupArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(objects, func(a, b *unstructured.Unstructured) int {
+		return restoreOrder(a) - restoreOrder(b)
+	})
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(clients.Clientset.Discovery()))
+	var results []RestoreResult
+	for _, u := range objects {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		gvk := u.GroupVersionKind()
+		result := RestoreResult{Kind: u.GetKind(), Namespace: u.GetNamespace(), Name: u.GetName()}
+
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			if !selection.matches(u, "") {
+				continue
+			}
+			result.Status, result.Error = RestoreFailed, err.Error()
+			results = append(results, result)
+			continue
+		}
+		if !selection.matches(u, mapping.Resource.Resource) {
+			continue
+		}
+
+		resource := clients.Dynamic.Resource(mapping.Resource)
+		var createErr error
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			_, createErr = resource.Namespace(u.GetNamespace()).Create(ctx, u, metav1.CreateOptions{})
+		} else {
+			_, createErr = resource.Create(ctx, u, metav1.CreateOptions{})
+		}
+		switch {
+		case createErr == nil:
+			result.Status = RestoreCreated
+		case apierrors.IsAlreadyExists(createErr):
+			result.Status = RestoreExists
+		default:
+			result.Status, result.Error = RestoreFailed, createErr.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// matches tells whether an object of the archive is selected, resource
+// being its plural resource name when known
+func (s RestoreSelection) matches(u *unstructured.Unstructured, resource string) bool {
+	if len(s.Namespaces) > 0 && !slices.Contains(s.Namespaces, u.GetNamespace()) {
+		return false
+	}
+	if len(s.Names) > 0 && !slices.Contains(s.Names, u.GetName()) {
+		return false
+	}
+	if len(s.Kinds) == 0 {
+		return true
+	}
+	objectDetector, _ := LookupDetector(u.GetKind())
+	for _, kind := range s.Kinds {
+		if strings.EqualFold(kind, u.GetKind()) || strings.EqualFold(kind, resource) {
+			return true
+		}
+		if detector, ok := LookupDetector(kind); ok && detector == objectDetector {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package kor
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestGetUnusedReportBackup(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	secret := CreateTestSecret(testNamespace, "secret-1", AppLabels)
+	secret.UID = "1234"
+	secret.ResourceVersion = "42"
+	secret.Data = map[string][]byte{"password": []byte("hunter2")}
+	_, err = clientset.CoreV1().Secrets(testNamespace).Create(context.TODO(), secret, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake secret: %v", err)
+	}
+
+	backupDir := t.TempDir()
+	opts := common.Opts{DeleteFlag: true, NoInteractive: true, BackupDir: backupDir}
+	report, err := GetUnusedReport(context.TODO(), []Detector{secretDetector}, &filters.Options{}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Errors) != 0 || len(report.Findings) != 1 || !report.Findings[0].Deleted {
+		t.Fatalf("Expected secret-1 to be deleted, got %+v", report)
+	}
+	if report.BackupArchive == "" {
+		t.Fatalf("Expected the backup archive in the report")
+	}
+	if info, err := os.Stat(report.BackupArchive); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected an archive only readable by its owner, got %v, %v", info, err)
+	}
+
+	objects, err := readBackupArchive(report.BackupArchive)
+	if err != nil {
+		t.Fatalf("Error reading backup archive: %v", err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("Expected 1 object in the archive, got %d", len(objects))
+	}
+	saved := objects[0]
+	if saved.GetAPIVersion() != "v1" || saved.GetKind() != "Secret" || saved.GetName() != "secret-1" || saved.GetNamespace() != testNamespace {
+		t.Errorf("Expected v1 Secret %s/secret-1, got %s %s %s/%s", testNamespace, saved.GetAPIVersion(), saved.GetKind(), saved.GetNamespace(), saved.GetName())
+	}
+	if saved.GetUID() != "" || saved.GetResourceVersion() != "" {
+		t.Errorf("Expected server-managed fields to be removed, got uid %q and resourceVersion %q", saved.GetUID(), saved.GetResourceVersion())
+	}
+	if data, _, _ := unstructured.NestedString(saved.Object, "data", "password"); data != "aHVudGVyMg==" {
+		t.Errorf("Expected the secret data to be saved, got %q", data)
+	}
+}
+
+func TestGetUnusedReportBackupDryRun(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, "configmap-1", AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake configmap: %v", err)
+	}
+
+	backupDir := t.TempDir()
+	opts := common.Opts{DeleteFlag: true, DryRun: DryRunClient, BackupDir: backupDir}
+	report, err := GetUnusedReport(context.TODO(), []Detector{configMapDetector}, &filters.Options{}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(backupDir); report.BackupArchive != "" || len(entries) != 0 {
+		t.Errorf("Expected no backup of a dry run, got %q and %v", report.BackupArchive, entries)
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	archive := t.TempDir() + "/archive.yaml"
+	backup := &backupArchive{dir: t.TempDir()}
+	existing := CreateTestConfigmap(testNamespace, "configmap-1", AppLabels)
+	clusterRole := &rbacv1.ClusterRole{ObjectMeta: v1.ObjectMeta{Name: "cluster-role-1"}}
+	for _, obj := range []runtime.Object{
+		existing,
+		CreateTestConfigmap(testNamespace, "configmap-2", AppLabels),
+		CreateTestSecret(testNamespace, "secret-1", AppLabels),
+		clusterRole,
+	} {
+		if err := backup.save(obj); err != nil {
+			t.Fatalf("Error saving %v: %v", obj, err)
+		}
+	}
+	if err := backup.Close(); err != nil {
+		t.Fatalf("Error closing archive: %v", err)
+	}
+	if err := os.Rename(backup.Path(), archive); err != nil {
+		t.Fatalf("Error moving archive: %v", err)
+	}
+
+	clientset := fake.NewClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*v1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []v1.APIResource{
+				{Name: "configmaps", Namespaced: true, Kind: "ConfigMap"},
+				{Name: "secrets", Namespaced: true, Kind: "Secret"},
+			},
+		},
+		{
+			GroupVersion: "rbac.authorization.k8s.io/v1",
+			APIResources: []v1.APIResource{
+				{Name: "clusterroles", Namespaced: false, Kind: "ClusterRole"},
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = rbacv1.AddToScheme(scheme)
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(scheme, existing)
+	clients := Clients{Clientset: clientset, Dynamic: dynamicClient}
+
+	results, err := RestoreBackup(context.TODO(), archive, clients, RestoreSelection{Kinds: []string{"cm", "clusterroles"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []RestoreResult{
+		{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-1", Status: RestoreExists},
+		{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-2", Status: RestoreCreated},
+		{Kind: "ClusterRole", Name: "cluster-role-1", Status: RestoreCreated},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+
+	configmaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	if _, err := dynamicClient.Resource(configmaps).Namespace(testNamespace).Get(context.TODO(), "configmap-2", v1.GetOptions{}); err != nil {
+		t.Errorf("Expected configmap-2 to be restored, got %v", err)
+	}
+	secrets := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	if _, err := dynamicClient.Resource(secrets).Namespace(testNamespace).Get(context.TODO(), "secret-1", v1.GetOptions{}); err == nil {
+		t.Errorf("Expected secret-1 not to be selected")
+	}
+}
+
+func TestRestoreBackupOwnedObject(t *testing.T) {
+	archive := t.TempDir() + "/archive.yaml"
+	backup := &backupArchive{dir: t.TempDir()}
+	replicaSet := CreateTestReplicaSet(testNamespace, "replicaset-1", nil, &appsv1.ReplicaSetStatus{})
+	replicaSet.OwnerReferences = []v1.OwnerReference{
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "deployment-1", UID: "deleted-owner-uid"},
+	}
+	if err := backup.save(replicaSet); err != nil {
+		t.Fatalf("Error saving %v: %v", replicaSet, err)
+	}
+	if err := backup.Close(); err != nil {
+		t.Fatalf("Error closing archive: %v", err)
+	}
+	if err := os.Rename(backup.Path(), archive); err != nil {
+		t.Fatalf("Error moving archive: %v", err)
+	}
+
+	clientset := fake.NewClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*v1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []v1.APIResource{
+				{Name: "replicasets", Namespaced: true, Kind: "ReplicaSet"},
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(scheme)
+	clients := Clients{Clientset: clientset, Dynamic: dynamicClient}
+
+	results, err := RestoreBackup(context.TODO(), archive, clients, RestoreSelection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []RestoreResult{{Kind: "ReplicaSet", Namespace: testNamespace, Name: "replicaset-1", Status: RestoreCreated}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+
+	replicaSets := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"}
+	restored, err := dynamicClient.Resource(replicaSets).Namespace(testNamespace).Get(context.TODO(), "replicaset-1", v1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected replicaset-1 to be restored, got %v", err)
+	}
+	if owners := restored.GetOwnerReferences(); len(owners) != 0 {
+		t.Errorf("Expected the owner references of the deleted owner to be removed, got %v", owners)
+	}
+}
@@ -0,0 +1,382 @@
+package kor
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+// Cascade modes of a deletion, mapped to its propagation policy. The empty
+// string keeps the default policy of each kind.
+const (
+	CascadeBackground = "background"
+	CascadeForeground = "foreground"
+	CascadeOrphan     = "orphan"
+)
+
+// ValidateCascade checks a --cascade mode, the empty string meaning the default policy
+func ValidateCascade(cascade string) error {
+	switch cascade {
+	case "", CascadeBackground, CascadeForeground, CascadeOrphan:
+		return nil
+	default:
+		return fmt.Errorf("invalid cascade mode %q, must be %q, %q or %q", cascade, CascadeBackground, CascadeForeground, CascadeOrphan)
+	}
+}
+
+// propagationPolicy returns the PropagationPolicy field of the delete options
+func propagationPolicy(cascade string) *metav1.DeletionPropagation {
+	var policy metav1.DeletionPropagation
+	switch cascade {
+	case CascadeBackground:
+		policy = metav1.DeletePropagationBackground
+	case CascadeForeground:
+		policy = metav1.DeletePropagationForeground
+	case CascadeOrphan:
+		policy = metav1.DeletePropagationOrphan
+	default:
+		return nil
+	}
+	return &policy
+}
+
+var workloadKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "CronJob", "Job", "Pod"}
+
+// deleteBefore lists, for the kinds kor reports, the kinds to delete before
+// them. Owners go first, as their controllers would recreate what they own,
+// and so do the kinds referring to others, so nothing is left pointing at a
+// deleted object.
+var deleteBefore = map[string][]string{
+	"ReplicaSet":     {"Deployment", "Hpa", "Pdb"},
+	"Job":            {"CronJob"},
+	"Pod":            {"ReplicaSet", "StatefulSet", "DaemonSet", "Job", "Pdb"},
+	"Deployment":     {"Hpa", "Pdb"},
+	"StatefulSet":    {"Hpa", "Pdb"},
+	"Service":        {"Ingress", "StatefulSet", "MutatingWebhookConfiguration", "ValidatingWebhookConfiguration", "HTTPRoute", "GRPCRoute", "TLSRoute"},
+	"Role":           {"RoleBinding"},
+	"ClusterRole":    {"ClusterRoleBinding", "RoleBinding"},
+	"ConfigMap":      workloadKinds,
+	"Secret":         slices.Concat(workloadKinds, []string{"ServiceAccount", "Ingress", "Gateway"}),
+	"ServiceAccount": slices.Concat(workloadKinds, []string{"RoleBinding", "ClusterRoleBinding"}),
+	"Pvc":            workloadKinds,
+	"Pv":             {"Pvc", "VolumeAttachment"},
+	"StorageClass":   {"Pvc", "Pv"},
+	"PriorityClass":  workloadKinds,
+	"Gateway":        {"HTTPRoute", "GRPCRoute", "TLSRoute"},
+	"GatewayClass":   {"Gateway"},
+}
+
+// deletionRank orders the deletion of kinds, lower ranks first. Namespaces
+// go last, deleting one removes everything left in it.
+func deletionRank(kind string) int {
+	if kind == "Namespace" {
+		return math.MaxInt
+	}
+	rank := 0
+	for _, before := range deleteBefore[kind] {
+		rank = max(rank, deletionRank(before)+1)
+	}
+	return rank
+}
+
+// CascadeNode is a resource deleted along with its dependents, the objects
+// the garbage collector removes once their owners are gone
+type CascadeNode struct {
+	Kind       string        `json:"kind"`
+	Namespace  string        `json:"namespace,omitempty"`
+	Name       string        `json:"name"`
+	Dependents []CascadeNode `json:"dependents,omitempty"`
+}
+
+// objectKey identifies a finding or a dependent
+type objectKey struct {
+	kind, namespace, name string
+}
+
+func (n CascadeNode) key() objectKey {
+	return objectKey{n.Kind, n.Namespace, n.Name}
+}
+
+// walk calls fn on the dependents of n, recursively
+func (n CascadeNode) walk(fn func(CascadeNode)) {
+	for _, dependent := range n.Dependents {
+		fn(dependent)
+		dependent.walk(fn)
+	}
+}
+
+func objectList[T any, PT interface {
+	*T
+	metav1.Object
+}](items []T) []metav1.Object {
+	objects := make([]metav1.Object, 0, len(items))
+	for i := range items {
+		objects = append(objects, PT(&items[i]))
+	}
+	return objects
+}
+
+// dependentKinds are the kinds listed for the dependents of deleted
+// resources, named after their detector when they have one
+var dependentKinds = []struct {
+	kind string
+	list func(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]metav1.Object, error)
+}{
+	{"ReplicaSet", func(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]metav1.Object, error) {
+		list, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objectList(list.Items), nil
+	}},
+	{"Pod", func(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]metav1.Object, error) {
+		list, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objectList(list.Items), nil
+	}},
+	{"Job", func(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]metav1.Object, error) {
+		list, err := clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objectList(list.Items), nil
+	}},
+	{"Pvc", func(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]metav1.Object, error) {
+		list, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objectList(list.Items), nil
+	}},
+	{"ControllerRevision", func(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]metav1.Object, error) {
+		list, err := clientset.AppsV1().ControllerRevisions(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objectList(list.Items), nil
+	}},
+	{"EndpointSlice", func(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]metav1.Object, error) {
+		list, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objectList(list.Items), nil
+	}},
+}
+
+// cascadePreview returns, for each finding that owns objects, the tree of
+// dependents the garbage collector removes when it is deleted. A dependent
+// goes away once all its owners are gone. Only dependents in the namespace of
+// their owner are looked up. The findings that can't be previewed are left
+// out, their failures joined in the returned error.
+func cascadePreview(ctx context.Context, clients Clients, findings []Finding) ([]CascadeNode, error) {
+	type object struct {
+		node  CascadeNode
+		owner types.UID
+	}
+	var errs []error
+	removed := make(map[types.UID]bool)
+	var roots []object
+	namespaces := make(map[string]bool)
+	for _, finding := range findings {
+		detector, ok := LookupDetector(finding.Kind)
+		if !ok {
+			continue
+		}
+		obj, err := getObject(ctx, detector, clients, finding.Namespace, finding.Name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		var accessor metav1.Object
+		if err == nil {
+			accessor, err = meta.Accessor(obj)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get %s %s in namespace %s: %w", finding.Kind, finding.Name, finding.Namespace, err))
+			continue
+		}
+		removed[accessor.GetUID()] = true
+		roots = append(roots, object{node: CascadeNode{Kind: finding.Kind, Namespace: finding.Namespace, Name: finding.Name}, owner: accessor.GetUID()})
+		if finding.Namespace != "" {
+			namespaces[finding.Namespace] = true
+		}
+	}
+
+	var owned []metav1.Object
+	kinds := make(map[types.UID]string)
+	for _, namespace := range slices.Sorted(maps.Keys(namespaces)) {
+		for _, dependentKind := range dependentKinds {
+			objects, err := dependentKind.list(ctx, clients.Clientset, namespace)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to list %s in namespace %s: %w", dependentKind.kind, namespace, err))
+				continue
+			}
+			for _, object := range objects {
+				if len(object.GetOwnerReferences()) != 0 {
+					owned = append(owned, object)
+					kinds[object.GetUID()] = dependentKind.kind
+				}
+			}
+		}
+	}
+
+	// an object can be owned by a dependent listed after it
+	for changed := true; changed; {
+		changed = false
+		for _, object := range owned {
+			if removed[object.GetUID()] {
+				continue
+			}
+			if !slices.ContainsFunc(object.GetOwnerReferences(), func(ref metav1.OwnerReference) bool { return !removed[ref.UID] }) {
+				removed[object.GetUID()] = true
+				changed = true
+			}
+		}
+	}
+
+	// each dependent is shown under its controller, or its first owner
+	children := make(map[types.UID][]metav1.Object)
+	for _, object := range owned {
+		if !removed[object.GetUID()] {
+			continue
+		}
+		refs := object.GetOwnerReferences()
+		parent := refs[0].UID
+		if controller := metav1.GetControllerOfNoCopy(object); controller != nil {
+			parent = controller.UID
+		}
+		children[parent] = append(children[parent], object)
+	}
+	var dependents func(owner types.UID) []CascadeNode
+	dependents = func(owner types.UID) []CascadeNode {
+		var nodes []CascadeNode
+		for _, object := range children[owner] {
+			nodes = append(nodes, CascadeNode{
+				Kind:       kinds[object.GetUID()],
+				Namespace:  object.GetNamespace(),
+				Name:       object.GetName(),
+				Dependents: dependents(object.GetUID()),
+			})
+		}
+		slices.SortFunc(nodes, func(a, b CascadeNode) int {
+			return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Name, b.Name))
+		})
+		return nodes
+	}
+
+	var preview []CascadeNode
+	for _, root := range roots {
+		root.node.Dependents = dependents(root.owner)
+		if len(root.node.Dependents) != 0 {
+			preview = append(preview, root.node)
+		}
+	}
+	return preview, errors.Join(errs...)
+}
+
+// deletionCascade previews the dependents removed with the findings a
+// deletion can delete, none when opts.Cascade orphans them. It is shown in the
+// review, before anything is deleted.
+func deletionCascade(ctx context.Context, clients Clients, findings []Finding, opts common.Opts) ([]CascadeNode, error) {
+	if opts.Cascade == CascadeOrphan {
+		return nil, nil
+	}
+	findings = slices.DeleteFunc(slices.Clone(findings), func(finding Finding) bool {
+		return !deletable(finding, opts)
+	})
+	preview, err := cascadePreview(ctx, clients, findings)
+	if err != nil {
+		return preview, fmt.Errorf("failed to preview the dependents removed with the deleted resources: %w", err)
+	}
+	return preview, nil
+}
+
+// deleteTasksInOrder deletes the findings of scan tasks kind by kind in
+// deletion order, updating them in place. Only the findings review selected
+// are deleted, every one of them when it is nil. preview is the cascade of
+// deleting every finding, as returned by deletionCascade. Findings the garbage
+// collector removes along with a deleted owner are marked deleted without
+// being deleted again. Failures are recorded in the report, and the cascade
+// of the deleted findings returned.
+func deleteTasksInOrder(ctx context.Context, report *Report, tasks []scanTask, findings [][]Finding, clients Clients, opts common.Opts, review reviewResult, preview []CascadeNode, backup *backupArchive, audit *auditLog) []CascadeNode {
+	all := slices.Concat(findings...)
+	// a dependent with several owners stays when only some of them are deleted
+	if opts.Cascade != CascadeOrphan && slices.ContainsFunc(all, func(finding Finding) bool {
+		return deletable(finding, opts) && review.action(finding) != reviewDelete
+	}) {
+		var err error
+		selected := slices.DeleteFunc(all, func(finding Finding) bool {
+			return !deletable(finding, opts) || review.action(finding) != reviewDelete
+		})
+		preview, err = cascadePreview(ctx, clients, selected)
+		if err != nil {
+			report.addError("", "", fmt.Errorf("failed to preview the dependents removed with the deleted resources: %w", err))
+		}
+	}
+	owners := make(map[objectKey]CascadeNode, len(preview))
+	for _, node := range preview {
+		owners[node.key()] = node
+	}
+
+	order := make([]int, len(tasks))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(deletionRank(tasks[a].detector.Name()), deletionRank(tasks[b].detector.Name()))
+	})
+
+	var cascade []CascadeNode
+	cascaded := make(map[objectKey]bool)
+	for _, i := range order {
+		task := tasks[i]
+		var pending []Finding
+		var indexes []int
+		for j, finding := range findings[i] {
+			if cascaded[objectKey{finding.Kind, finding.Namespace, finding.Name}] {
+				findings[i][j].Deleted = true
+				findings[i][j].DryRun = opts.DryRun != ""
+				if err := audit.record(ctx, newAuditEntry(AuditDelete, AuditCascaded, finding, nil), nil); err != nil {
+					report.addError(task.detector.Name(), task.namespace, err)
+				}
+				continue
+			}
+			pending = append(pending, finding)
+			indexes = append(indexes, j)
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		pending, err := deleteFindings(ctx, pending, clients, task.detector, opts, review, backup, audit)
+		if err != nil {
+			report.addError(task.detector.Name(), task.namespace, err)
+		}
+		for k, j := range indexes {
+			findings[i][j] = pending[k]
+			node, ok := owners[objectKey{pending[k].Kind, pending[k].Namespace, pending[k].Name}]
+			if !ok || !pending[k].Deleted {
+				continue
+			}
+			cascade = append(cascade, node)
+			node.walk(func(dependent CascadeNode) {
+				cascaded[dependent.key()] = true
+			})
+		}
+	}
+	return cascade
+}
@@ -0,0 +1,202 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestDeletionRank(t *testing.T) {
+	for kind, before := range deleteBefore {
+		if _, ok := LookupDetector(kind); !ok {
+			t.Errorf("Expected %s to be a detector", kind)
+		}
+		for _, other := range before {
+			if _, ok := LookupDetector(other); !ok {
+				t.Errorf("Expected %s, deleted before %s, to be a detector", other, kind)
+			}
+			if deletionRank(other) >= deletionRank(kind) {
+				t.Errorf("Expected %s to be deleted before %s", other, kind)
+			}
+		}
+	}
+
+	for _, order := range [][]string{
+		{"Hpa", "Deployment", "ReplicaSet", "Pod", "ConfigMap"},
+		{"CronJob", "Job", "Pod", "Pvc", "Pv", "StorageClass"},
+		{"ClusterRoleBinding", "ClusterRole"},
+		{"HTTPRoute", "Gateway", "GatewayClass"},
+		{"Secret", "Namespace"},
+	} {
+		for i := 1; i < len(order); i++ {
+			if deletionRank(order[i-1]) >= deletionRank(order[i]) {
+				t.Errorf("Expected %s to be deleted before %s", order[i-1], order[i])
+			}
+		}
+	}
+}
+
+func TestGetUnusedReportCascade(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	deployment := CreateTestDeployment(testNamespace, "deployment-1", 0, AppLabels)
+	deployment.UID = "deployment-1-uid"
+	owned := CreateTestReplicaSet(testNamespace, "deployment-1-abc", ptr.To[int32](0), &appsv1.ReplicaSetStatus{})
+	owned.UID = "deployment-1-abc-uid"
+	owned.OwnerReferences = []v1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: deployment.Name, UID: deployment.UID, Controller: ptr.To(true)}}
+	pod := CreateTestPod(testNamespace, "deployment-1-abc-1", "", nil, AppLabels)
+	pod.OwnerReferences = []v1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: owned.Name, UID: owned.UID, Controller: ptr.To(true)}}
+	orphan := CreateTestReplicaSet(testNamespace, "replicaset-orphan", ptr.To[int32](0), &appsv1.ReplicaSetStatus{})
+
+	for _, obj := range []runtime.Object{deployment, owned, pod, orphan} {
+		if err := clientset.Tracker().Add(obj); err != nil {
+			t.Fatalf("Error adding fake object: %v", err)
+		}
+	}
+
+	var deletes []string
+	clientset.PrependReactor("delete", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deleteAction := action.(k8stesting.DeleteAction)
+		policy := deleteAction.GetDeleteOptions().PropagationPolicy
+		if policy == nil || *policy != v1.DeletePropagationForeground {
+			t.Errorf("Expected a foreground deletion of %s, got %v", deleteAction.GetName(), policy)
+		}
+		deletes = append(deletes, action.GetResource().Resource+"/"+deleteAction.GetName())
+		return false, nil, nil
+	})
+
+	opts := common.Opts{DeleteFlag: true, NoInteractive: true, Cascade: CascadeForeground}
+	report, err := GetUnusedReport(context.TODO(), []Detector{replicaSetDetector, deploymentDetector}, &filters.Options{}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("Expected no errors, got %v", report.Errors)
+	}
+
+	expectedDeletes := []string{"deployments/deployment-1", "replicasets/replicaset-orphan"}
+	if !reflect.DeepEqual(deletes, expectedDeletes) {
+		t.Errorf("Expected the deletes %v, got %v", expectedDeletes, deletes)
+	}
+	for _, finding := range report.Findings {
+		if !finding.Deleted {
+			t.Errorf("Expected %s %s to be deleted", finding.Kind, finding.Name)
+		}
+	}
+	expectedCascade := []CascadeNode{{
+		Kind:      "Deployment",
+		Namespace: testNamespace,
+		Name:      "deployment-1",
+		Dependents: []CascadeNode{{
+			Kind:       "ReplicaSet",
+			Namespace:  testNamespace,
+			Name:       "deployment-1-abc",
+			Dependents: []CascadeNode{{Kind: "Pod", Namespace: testNamespace, Name: "deployment-1-abc-1"}},
+		}},
+	}}
+	if !reflect.DeepEqual(report.Cascade, expectedCascade) {
+		t.Errorf("Expected the cascade %+v, got %+v", expectedCascade, report.Cascade)
+	}
+}
+
+func TestGetUnusedReportCascadeOrphan(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	deployment := CreateTestDeployment(testNamespace, "deployment-1", 0, AppLabels)
+	deployment.UID = "deployment-1-uid"
+	owned := CreateTestReplicaSet(testNamespace, "deployment-1-abc", ptr.To[int32](1), &appsv1.ReplicaSetStatus{})
+	owned.OwnerReferences = []v1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: deployment.Name, UID: deployment.UID}}
+	for _, obj := range []runtime.Object{CreateTestNamespace(testNamespace, AppLabels), deployment, owned} {
+		if err := clientset.Tracker().Add(obj); err != nil {
+			t.Fatalf("Error adding fake object: %v", err)
+		}
+	}
+
+	opts := common.Opts{DeleteFlag: true, NoInteractive: true, Cascade: CascadeOrphan}
+	report, err := GetUnusedReport(context.TODO(), []Detector{deploymentDetector}, &filters.Options{}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Findings) != 1 || !report.Findings[0].Deleted {
+		t.Fatalf("Expected deployment-1 to be deleted, got %+v", report.Findings)
+	}
+	if len(report.Cascade) != 0 {
+		t.Errorf("Expected no cascade when orphaning the dependents, got %+v", report.Cascade)
+	}
+}
+
+// addCascadeTestObjects adds deployment-1, owning the ReplicaSet
+// deployment-1-abc, owning the Pod deployment-1-abc-1
+func addCascadeTestObjects(t *testing.T, clientset *fake.Clientset) {
+	t.Helper()
+	deployment := CreateTestDeployment(testNamespace, "deployment-1", 0, AppLabels)
+	deployment.UID = "deployment-1-uid"
+	owned := CreateTestReplicaSet(testNamespace, "deployment-1-abc", ptr.To[int32](0), &appsv1.ReplicaSetStatus{})
+	owned.UID = "deployment-1-abc-uid"
+	owned.OwnerReferences = []v1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: deployment.Name, UID: deployment.UID, Controller: ptr.To(true)}}
+	pod := CreateTestPod(testNamespace, "deployment-1-abc-1", "", nil, AppLabels)
+	pod.OwnerReferences = []v1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: owned.Name, UID: owned.UID, Controller: ptr.To(true)}}
+	for _, obj := range []runtime.Object{deployment, owned, pod} {
+		if err := clientset.Tracker().Add(obj); err != nil {
+			t.Fatalf("Error adding fake object: %v", err)
+		}
+	}
+}
+
+func TestGetUnusedReportCascadeReview(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	addCascadeTestObjects(t, clientset)
+
+	var deletes []string
+	var reviewed string
+	clientset.PrependReactor("delete", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviewed = reviewOutput.(*bytes.Buffer).String()
+		deletes = append(deletes, action.GetResource().Resource+"/"+action.(k8stesting.DeleteAction).GetName())
+		return false, nil, nil
+	})
+
+	input, output := reviewInput, reviewOutput
+	defer func() { reviewInput, reviewOutput = input, output }()
+	reviewInput = strings.NewReader("1\n\ny\n")
+	reviewOutput = &bytes.Buffer{}
+
+	opts := common.Opts{DeleteFlag: true}
+	report, err := GetUnusedReport(context.TODO(), []Detector{deploymentDetector}, &filters.Options{}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("Expected no errors, got %v", report.Errors)
+	}
+	if !reflect.DeepEqual(deletes, []string{"deployments/deployment-1"}) {
+		t.Fatalf("Expected deployment-1 to be deleted, got %v", deletes)
+	}
+
+	// the dependents are shown before anything is deleted
+	for _, expected := range []string{"ReplicaSet", "deployment-1-abc", "Pod", "deployment-1-abc-1", "Delete 1 along with 2 dependents"} {
+		if !strings.Contains(reviewed, expected) {
+			t.Errorf("Expected the review to show %q before the delete, got:\n%s", expected, reviewed)
+		}
+	}
+}
@@ -1,12 +1,8 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
-	"fmt"
-	"os"
 
 	v1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -20,14 +16,14 @@ import (
 var clusterRoleBindingsConfig []byte
 
 // Check if any valid service accounts exist in the ClusterRoleBinding by querying directly
-func isUsingValidServiceAccountClusterScoped(subjects []v1.Subject, clientset kubernetes.Interface) bool {
+func isUsingValidServiceAccountClusterScoped(ctx context.Context, subjects []v1.Subject, clientset kubernetes.Interface) bool {
 	for _, subject := range subjects {
 		// If we encounter non-ServiceAccount subjects (Users/Groups), assume they exist
 		if subject.Kind != "ServiceAccount" {
 			return true
 		}
 		// Query directly for the service account in its namespace
-		_, err := clientset.CoreV1().ServiceAccounts(subject.Namespace).Get(context.TODO(), subject.Name, metav1.GetOptions{})
+		_, err := clientset.CoreV1().ServiceAccounts(subject.Namespace).Get(ctx, subject.Name, metav1.GetOptions{})
 		if err == nil {
 			return true // At least one ServiceAccount exists
 		}
@@ -43,13 +39,13 @@ func validateClusterRoleReference(crb v1.ClusterRoleBinding, clusterRoleNames ma
 	return nil
 }
 
-func processClusterRoleBindings(clientset kubernetes.Interface, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	clusterRoleBindingsList, err := clientset.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processClusterRoleBindings(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	clusterRoleBindingsList, err := clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
 
-	clusterRoleNames, err := convertNamesToPresenseMap(retrieveClusterRoleNames(clientset, filterOpts))
+	clusterRoleNames, err := convertNamesToPresenseMap(retrieveClusterRoleNames(ctx, clientset, filterOpts))
 	if err != nil {
 		return nil, err
 	}
@@ -90,48 +86,26 @@ func processClusterRoleBindings(clientset kubernetes.Interface, filterOpts *filt
 		}
 
 		// Check if ClusterRoleBinding uses valid subjects (ServiceAccounts and/or Users/Groups)
-		if !isUsingValidServiceAccountClusterScoped(crb.Subjects, clientset) {
+		if !isUsingValidServiceAccountClusterScoped(ctx, crb.Subjects, clientset) {
 			unusedClusterRoleBindingNames = append(unusedClusterRoleBindingNames, ResourceInfo{Name: crb.Name, Reason: "ClusterRoleBinding references a non-existing ServiceAccount"})
 		}
 	}
-	if opts.DeleteFlag {
-		if unusedClusterRoleBindingNames, err = DeleteResource(unusedClusterRoleBindingNames, clientset, "", "ClusterRoleBinding", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete ClusterRoleBinding %s: %v\n", unusedClusterRoleBindingNames, err)
-		}
-	}
 	return unusedClusterRoleBindingNames, nil
 }
 
-func GetUnusedClusterRoleBindings(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	diff, err := processClusterRoleBindings(clientset, filterOpts, opts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to process clusterrolebindings: %v\n", err)
-	}
-	switch opts.GroupBy {
-	case "namespace":
-		resources[""] = make(map[string][]ResourceInfo)
-		resources[""]["ClusterRoleBinding"] = diff
-	case "resource":
-		appendResources(resources, "ClusterRoleBinding", "", diff)
-	}
+func detectClusterRoleBindings(ctx context.Context, clients Clients, _ string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	return processClusterRoleBindings(ctx, clients.Clientset, filterOpts, opts)
+}
 
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var clusterRoleBindingDetector = newDetector("ClusterRoleBinding", []string{"clusterrolebinding", "clusterrolebindings"}, ClusterScoped, detectClusterRoleBindings,
+	func(clients Clients, _ string) typedClient[*v1.ClusterRoleBinding, *v1.ClusterRoleBindingList] {
+		return clients.Clientset.RbacV1().ClusterRoleBindings()
+	})
 
-	unusedClusterRoleBindings, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(clusterRoleBindingDetector)
+}
 
-	return unusedClusterRoleBindings, nil
+func GetUnusedClusterRoleBindings(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{clusterRoleBindingDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
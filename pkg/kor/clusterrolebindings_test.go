@@ -166,7 +166,7 @@ func createTestClusterRoleBindings(t *testing.T) *fake.Clientset {
 func TestProcessClusterRoleBindings(t *testing.T) {
 	clientset := createTestClusterRoleBindings(t)
 
-	unusedClusterRoleBindings, err := processClusterRoleBindings(clientset, &filters.Options{}, common.Opts{})
+	unusedClusterRoleBindings, err := processClusterRoleBindings(context.TODO(), clientset, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -255,7 +255,7 @@ func TestProcessClusterRoleBindingsWithMixedSubjects(t *testing.T) {
 		t.Fatalf("Error creating ClusterRoleBinding: %v", err)
 	}
 
-	unusedClusterRoleBindings, err := processClusterRoleBindings(clientset, &filters.Options{}, common.Opts{})
+	unusedClusterRoleBindings, err := processClusterRoleBindings(context.TODO(), clientset, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -278,7 +278,7 @@ func TestGetUnusedClusterRoleBindingStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedClusterRoleBindings(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedClusterRoleBindings(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedClusterRoleBindingStructured: %v", err)
 	}
@@ -355,7 +355,7 @@ func TestIsUsingValidServiceAccountClusterScoped(t *testing.T) {
 		},
 	}
 
-	if !isUsingValidServiceAccountClusterScoped(subjects, clientset) {
+	if !isUsingValidServiceAccountClusterScoped(context.TODO(), subjects, clientset) {
 		t.Errorf("Expected to find valid ServiceAccount sa1 in namespace1")
 	}
 
@@ -368,7 +368,7 @@ func TestIsUsingValidServiceAccountClusterScoped(t *testing.T) {
 		},
 	}
 
-	if isUsingValidServiceAccountClusterScoped(subjects, clientset) {
+	if isUsingValidServiceAccountClusterScoped(context.TODO(), subjects, clientset) {
 		t.Errorf("Expected NOT to find ServiceAccount non-existing-sa")
 	}
 
@@ -381,7 +381,7 @@ func TestIsUsingValidServiceAccountClusterScoped(t *testing.T) {
 		},
 	}
 
-	if isUsingValidServiceAccountClusterScoped(subjects, clientset) {
+	if isUsingValidServiceAccountClusterScoped(context.TODO(), subjects, clientset) {
 		t.Errorf("Expected NOT to find ServiceAccount sa1 in non-existing-namespace")
 	}
 
@@ -399,7 +399,7 @@ func TestIsUsingValidServiceAccountClusterScoped(t *testing.T) {
 		},
 	}
 
-	if !isUsingValidServiceAccountClusterScoped(subjects, clientset) {
+	if !isUsingValidServiceAccountClusterScoped(context.TODO(), subjects, clientset) {
 		t.Errorf("Expected to find at least one valid ServiceAccount")
 	}
 
@@ -417,7 +417,7 @@ func TestIsUsingValidServiceAccountClusterScoped(t *testing.T) {
 		},
 	}
 
-	if !isUsingValidServiceAccountClusterScoped(subjects, clientset) {
+	if !isUsingValidServiceAccountClusterScoped(context.TODO(), subjects, clientset) {
 		t.Errorf("Expected to find valid ServiceAccount even when Users are present")
 	}
 
@@ -435,7 +435,7 @@ func TestIsUsingValidServiceAccountClusterScoped(t *testing.T) {
 		},
 	}
 
-	if !isUsingValidServiceAccountClusterScoped(subjects, clientset) {
+	if !isUsingValidServiceAccountClusterScoped(context.TODO(), subjects, clientset) {
 		t.Errorf("Expected to find valid subjects when only Users are present (we assume they exist)")
 	}
 }
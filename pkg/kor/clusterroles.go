@@ -1,12 +1,9 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
 	"fmt"
-	"os"
 	"strconv"
 
 	v1 "k8s.io/api/rbac/v1"
@@ -22,19 +19,18 @@ import (
 //go:embed exceptions/clusterroles/clusterroles.json
 var clusterRolesConfig []byte
 
-func retrieveUsedClusterRoles(clientset kubernetes.Interface, filterOpts *filters.Options) ([]string, error) {
+func retrieveUsedClusterRoles(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) (references, error) {
 
 	//Get a list of all namespaces
-	namespaceList, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	namespaceList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to retrieve namespaces: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to list namespaces: %v", err)
 	}
 	roleBindingsAllNameSpaces := make([]v1.RoleBinding, 0)
 
 	for _, ns := range namespaceList.Items {
 		// Get a list of all role bindings in the specified namespace
-		roleBindings, err := clientset.RbacV1().RoleBindings(ns.Name).List(context.TODO(), metav1.ListOptions{})
+		roleBindings, err := clientset.RbacV1().RoleBindings(ns.Name).List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return nil, fmt.Errorf("failed to list role bindings in namespace %s: %v", ns.Name, err)
 		}
@@ -43,16 +39,15 @@ func retrieveUsedClusterRoles(clientset kubernetes.Interface, filterOpts *filter
 	}
 
 	usedClusterRoles := make(map[string]bool)
+	used := references{}
 
 	for _, rb := range roleBindingsAllNameSpaces {
 		usedClusterRoles[rb.RoleRef.Name] = true
-		if rb.RoleRef.Kind == "ClusterRole" {
-			usedClusterRoles[rb.RoleRef.Name] = true
-		}
+		used.add(rb.RoleRef.Name, "RoleBinding", rb.Namespace, rb.Name, "roleRef")
 	}
 
 	// Get a list of all cluster role bindings in the specified namespace
-	clusterRoleBindings, err := clientset.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+	clusterRoleBindings, err := clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to list cluster role bindings %v", err)
@@ -60,10 +55,11 @@ func retrieveUsedClusterRoles(clientset kubernetes.Interface, filterOpts *filter
 
 	for _, crb := range clusterRoleBindings.Items {
 		usedClusterRoles[crb.RoleRef.Name] = true
+		used.add(crb.RoleRef.Name, "ClusterRoleBinding", "", crb.Name, "roleRef")
 	}
 
 	// Get a list of all ClusterRoles
-	clusterRoles, err := clientset.RbacV1().ClusterRoles().List(context.TODO(), metav1.ListOptions{})
+	clusterRoles, err := clientset.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list cluster roles %v", err)
 	}
@@ -93,6 +89,7 @@ func retrieveUsedClusterRoles(clientset kubernetes.Interface, filterOpts *filter
 					if err != nil {
 						return nil, fmt.Errorf("couldn't convert string to bool %v", err)
 					}
+					used.add(clusterRole.Name, "ClusterRole", "", clusterRoleManifest.Name, "aggregationRule.clusterRoleSelectors")
 					if clusterRole.AggregationRule == nil {
 						continue
 					}
@@ -105,16 +102,11 @@ func retrieveUsedClusterRoles(clientset kubernetes.Interface, filterOpts *filter
 			}
 		}
 	}
-	var usedClusterRoleNames []string
-	for role := range usedClusterRoles {
-		usedClusterRoleNames = append(usedClusterRoleNames, role)
-	}
-
-	return usedClusterRoleNames, nil
+	return used, nil
 }
 
-func retrieveClusterRoleNames(clientset kubernetes.Interface, filterOpts *filters.Options) ([]string, []string, error) {
-	clusterRoles, err := clientset.RbacV1().ClusterRoles().List(context.TODO(), metav1.ListOptions{})
+func retrieveClusterRoleNames(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]string, []string, error) {
+	clusterRoles, err := clientset.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -154,22 +146,20 @@ func retrieveClusterRoleNames(clientset kubernetes.Interface, filterOpts *filter
 	return names, unusedClusterRoles, nil
 }
 
-func processClusterRoles(clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	usedClusterRoles, err := retrieveUsedClusterRoles(clientset, filterOpts)
+func processClusterRoles(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	usedClusterRoles, err := retrieveUsedClusterRoles(ctx, clientset, filterOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	usedClusterRoles = RemoveDuplicatesAndSort(usedClusterRoles)
-
-	clusterRoleNames, unusedClusterRoles, err := retrieveClusterRoleNames(clientset, filterOpts)
+	clusterRoleNames, unusedClusterRoles, err := retrieveClusterRoleNames(ctx, clientset, filterOpts)
 	if err != nil {
 		return nil, err
 	}
 
 	var diff []ResourceInfo
 
-	for _, name := range CalculateResourceDifference(usedClusterRoles, clusterRoleNames) {
+	for _, name := range CalculateResourceDifference(usedClusterRoles.names(), clusterRoleNames) {
 		reason := "ClusterRole is not used by any RoleBinding or ClusterRoleBinding"
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
@@ -183,41 +173,15 @@ func processClusterRoles(clientset kubernetes.Interface, filterOpts *filters.Opt
 
 }
 
-func GetUnusedClusterRoles(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	diff, err := processClusterRoles(clientset, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to process cluster role : %v\n", err)
-	}
-	if opts.DeleteFlag {
-		if diff, err = DeleteResource(diff, clientset, "", "ClusterRole", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete clusterRole %s : %v\n", diff, err)
-		}
-	}
-	switch opts.GroupBy {
-	case "namespace":
-		resources[""] = make(map[string][]ResourceInfo)
-		resources[""]["ClusterRole"] = diff
-	case "resource":
-		appendResources(resources, "ClusterRole", "", diff)
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var clusterRoleDetector = newDetector("ClusterRole", []string{"clusterrole", "clusterroles"}, ClusterScoped, clusterDetect(processClusterRoles),
+	func(clients Clients, _ string) typedClient[*v1.ClusterRole, *v1.ClusterRoleList] {
+		return clients.Clientset.RbacV1().ClusterRoles()
+	})
 
-	unusedClusterRoles, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(clusterRoleDetector)
+}
 
-	return unusedClusterRoles, nil
+func GetUnusedClusterRoles(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{clusterRoleDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -125,7 +125,8 @@ func createTestClusterRolesWithOwnerReferences(t *testing.T) *fake.Clientset {
 func TestRetrieveUsedClusterRoles(t *testing.T) {
 	clientset := createTestClusterRoles(t)
 
-	usedClusterRoles, err := retrieveUsedClusterRoles(clientset, &filters.Options{})
+	usedClusterRoleReferences, err := retrieveUsedClusterRoles(context.TODO(), clientset, &filters.Options{})
+	usedClusterRoles := usedClusterRoleReferences.names()
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -147,7 +148,7 @@ func TestRetrieveUsedClusterRoles(t *testing.T) {
 
 func TestRetrieveClusterRoleNames(t *testing.T) {
 	clientset := createTestClusterRoles(t)
-	allRoles, _, err := retrieveClusterRoleNames(clientset, &filters.Options{})
+	allRoles, _, err := retrieveClusterRoleNames(context.TODO(), clientset, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -159,7 +160,7 @@ func TestRetrieveClusterRoleNames(t *testing.T) {
 func TestProcessClusterRoles(t *testing.T) {
 	clientset := createTestClusterRoles(t)
 
-	unusedClusterRoles, err := processClusterRoles(clientset, &filters.Options{})
+	unusedClusterRoles, err := processClusterRoles(context.TODO(), clientset, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -177,7 +178,7 @@ func TestProcessClusterRolesWithOwnerReferences(t *testing.T) {
 	clientset := createTestClusterRolesWithOwnerReferences(t)
 
 	// Test with --ignore-owner-references=false (default behavior)
-	unusedClusterRoles, err := processClusterRoles(clientset, &filters.Options{})
+	unusedClusterRoles, err := processClusterRoles(context.TODO(), clientset, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -188,7 +189,7 @@ func TestProcessClusterRolesWithOwnerReferences(t *testing.T) {
 	}
 
 	// Test with --ignore-owner-references=true
-	unusedClusterRoles, err = processClusterRoles(clientset, &filters.Options{IgnoreOwnerReferences: true})
+	unusedClusterRoles, err = processClusterRoles(context.TODO(), clientset, &filters.Options{IgnoreOwnerReferences: true})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -215,7 +216,7 @@ func TestGetUnusedClusterRolesStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedClusterRoles(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedClusterRoles(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedRolesStructured: %v", err)
 	}
@@ -252,7 +253,7 @@ func TestGetUnusedClusterRolesStructuredWithOwnerReferences(t *testing.T) {
 	}
 
 	// Test with --ignore-owner-references=true
-	output, err := GetUnusedClusterRoles(&filters.Options{IgnoreOwnerReferences: true}, clientset, "json", opts)
+	output, err := GetUnusedClusterRoles(context.TODO(), &filters.Options{IgnoreOwnerReferences: true}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedClusterRolesStructured: %v", err)
 	}
@@ -1,13 +1,11 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
 	"fmt"
-	"os"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -19,72 +17,65 @@ import (
 //go:embed exceptions/configmaps/configmaps.json
 var configMapsConfig []byte
 
-func retrieveUsedCM(clientset kubernetes.Interface, namespace string) ([]string, []string, []string, []string, []string, error) {
-	var volumesCM []string
-	var envCM []string
-	var envFromCM []string
-	var envFromContainerCM []string
-	var envFromInitContainerCM []string
-
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, nil, nil, nil, nil, err
-	}
-
-	for _, pod := range pods.Items {
-		for _, volume := range pod.Spec.Volumes {
-			if volume.ConfigMap != nil {
-				volumesCM = append(volumesCM, volume.ConfigMap.Name)
-			}
-			if volume.Projected != nil {
-				for _, source := range volume.Projected.Sources {
-					if source.ConfigMap != nil {
-						volumesCM = append(volumesCM, source.ConfigMap.Name)
-					}
+// addPodSpecConfigMaps adds the ConfigMaps used by the volumes and containers
+// of a pod spec, source.Field being the path of the spec, e.g.
+// spec.template.spec for a Deployment
+func addPodSpecConfigMaps(used references, spec *corev1.PodSpec, source Reference) {
+	for i, volume := range spec.Volumes {
+		if volume.ConfigMap != nil {
+			used.addReference(volume.ConfigMap.Name, source.at(fmt.Sprintf(".volumes[%d].configMap", i), volume.ConfigMap.Optional))
+		}
+		if volume.Projected != nil {
+			for j, projection := range volume.Projected.Sources {
+				if projection.ConfigMap != nil {
+					used.addReference(projection.ConfigMap.Name, source.at(fmt.Sprintf(".volumes[%d].projected.sources[%d].configMap", i, j), projection.ConfigMap.Optional))
 				}
 			}
 		}
-		for _, container := range pod.Spec.Containers {
-			for _, env := range container.Env {
+	}
+	for _, containers := range []struct {
+		field      string
+		containers []corev1.Container
+	}{{"containers", spec.Containers}, {"initContainers", spec.InitContainers}} {
+		for i, container := range containers.containers {
+			for j, env := range container.Env {
 				if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
-					envCM = append(envCM, env.ValueFrom.ConfigMapKeyRef.Name)
-				}
-			}
-			for _, envFrom := range container.EnvFrom {
-				if envFrom.ConfigMapRef != nil {
-					envFromCM = append(envFromCM, envFrom.ConfigMapRef.Name)
+					used.addReference(env.ValueFrom.ConfigMapKeyRef.Name, source.at(fmt.Sprintf(".%s[%d].env[%d].valueFrom.configMapKeyRef", containers.field, i, j), env.ValueFrom.ConfigMapKeyRef.Optional))
 				}
 			}
-			for _, envFrom := range container.EnvFrom {
+			for j, envFrom := range container.EnvFrom {
 				if envFrom.ConfigMapRef != nil {
-					envFromContainerCM = append(envFromContainerCM, envFrom.ConfigMapRef.Name)
+					used.addReference(envFrom.ConfigMapRef.Name, source.at(fmt.Sprintf(".%s[%d].envFrom[%d].configMapRef", containers.field, i, j), envFrom.ConfigMapRef.Optional))
 				}
 			}
 		}
-		for _, initContainer := range pod.Spec.InitContainers {
-			for _, volume := range initContainer.VolumeMounts {
+	}
+}
+
+func retrieveUsedCM(ctx context.Context, clientset kubernetes.Interface, namespace string) (references, error) {
+	used := references{}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pod := range pods.Items {
+		addPodSpecConfigMaps(used, &pod.Spec, Reference{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name, Field: "spec"})
+		for i, initContainer := range pod.Spec.InitContainers {
+			for j, volume := range initContainer.VolumeMounts {
 				if volume.Name != "" && volume.MountPath != "" {
-					volumesCM = append(volumesCM, volume.Name)
-				}
-			}
-			for _, env := range initContainer.Env {
-				if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
-					envFromInitContainerCM = append(envFromInitContainerCM, env.ValueFrom.ConfigMapKeyRef.Name)
-				}
-			}
-			for _, envFrom := range initContainer.EnvFrom {
-				if envFrom.ConfigMapRef != nil {
-					envFromInitContainerCM = append(envFromInitContainerCM, envFrom.ConfigMapRef.Name)
+					used.add(volume.Name, "Pod", pod.Namespace, pod.Name, fmt.Sprintf("spec.initContainers[%d].volumeMounts[%d]", i, j))
 				}
 			}
 		}
 	}
 
-	return volumesCM, envCM, envFromCM, envFromContainerCM, envFromInitContainerCM, nil
+	return used, nil
 }
 
-func retrieveConfigMapNames(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
-	configmaps, err := clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func retrieveConfigMapNames(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
+	configmaps, err := clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -112,8 +103,8 @@ func retrieveConfigMapNames(clientset kubernetes.Interface, namespace string, fi
 	return names, unusedConfigmapNames, nil
 }
 
-func processNamespaceCM(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	volumesCM, envCM, envFromCM, envFromContainerCM, envFromInitContainerCM, err := retrieveUsedCM(clientset, namespace)
+func processNamespaceCM(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	usedConfigMaps, err := retrieveUsedCM(ctx, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -122,33 +113,14 @@ func processNamespaceCM(clientset kubernetes.Interface, namespace string, filter
 		return nil, err
 	}
 
-	volumesCM = RemoveDuplicatesAndSort(volumesCM)
-	envCM = RemoveDuplicatesAndSort(envCM)
-	envFromCM = RemoveDuplicatesAndSort(envFromCM)
-	envFromContainerCM = RemoveDuplicatesAndSort(envFromContainerCM)
-	envFromInitContainerCM = RemoveDuplicatesAndSort(envFromInitContainerCM)
-
-	configMapNames, unusedConfigmapNames, err := retrieveConfigMapNames(clientset, namespace, filterOpts)
+	configMapNames, unusedConfigmapNames, err := retrieveConfigMapNames(ctx, clientset, namespace, filterOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	var usedConfigMaps []string
-	slicesToAppend := [][]string{
-		volumesCM,
-		envCM,
-		envFromCM,
-		envFromContainerCM,
-		envFromInitContainerCM,
-	}
-
-	for _, slice := range slicesToAppend {
-		usedConfigMaps = append(usedConfigMaps, slice...)
-	}
-
 	var diff []ResourceInfo
 
-	for _, name := range CalculateResourceDifference(usedConfigMaps, configMapNames) {
+	for _, name := range CalculateResourceDifference(usedConfigMaps.names(), configMapNames) {
 		exceptionFound, err := isResourceException(name, namespace, config.ExceptionConfigMaps)
 		if err != nil {
 			return nil, err
@@ -166,48 +138,18 @@ func processNamespaceCM(clientset kubernetes.Interface, namespace string, filter
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
 
-	if opts.DeleteFlag {
-		if diff, err = DeleteResource(diff, clientset, namespace, "ConfigMap", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete ConfigMap %s in namespace %s: %v\n", diff, namespace, err)
-		}
-	}
-
 	return diff, nil
 }
 
-func GetUnusedConfigmaps(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceCM(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["ConfigMap"] = diff
-		case "resource":
-			appendResources(resources, "ConfigMap", namespace, diff)
-		}
-	}
+var configMapDetector = newDetector("ConfigMap", []string{"configmap", "cm", "configmaps"}, NamespaceScoped, namespacedDetect(processNamespaceCM),
+	func(clients Clients, namespace string) typedClient[*corev1.ConfigMap, *corev1.ConfigMapList] {
+		return clients.Clientset.CoreV1().ConfigMaps(namespace)
+	})
 
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
-
-	unusedCMs, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(configMapDetector)
+}
 
-	return unusedCMs, nil
+func GetUnusedConfigmaps(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{configMapDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -146,7 +146,7 @@ func createTestConfigmaps(t *testing.T) *fake.Clientset {
 func TestRetrieveConfigMapNames(t *testing.T) {
 	clientset := createTestConfigmaps(t)
 
-	configMapNames, _, err := retrieveConfigMapNames(clientset, testNamespace, &filters.Options{})
+	configMapNames, _, err := retrieveConfigMapNames(context.TODO(), clientset, testNamespace, &filters.Options{})
 
 	if err != nil {
 		t.Fatalf("Error retrieving configmap names: %v", err)
@@ -166,7 +166,7 @@ func TestRetrieveConfigMapNames(t *testing.T) {
 func TestProcessNamespaceCM(t *testing.T) {
 	clientset := createTestConfigmaps(t)
 
-	diff, err := processNamespaceCM(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	diff, err := processNamespaceCM(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error processing namespace CM: %v", err)
 	}
@@ -183,11 +183,16 @@ func TestProcessNamespaceCM(t *testing.T) {
 func TestRetrieveUsedCM(t *testing.T) {
 	clientset := createTestConfigmaps(t)
 
-	volumesCM, envCM, envFromCM, envFromContainerCM, envFromInitContainerCM, err := retrieveUsedCM(clientset, testNamespace)
+	usedCM, err := retrieveUsedCM(context.TODO(), clientset, testNamespace)
 
 	if err != nil {
 		t.Fatalf("Error retrieving used ConfigMaps: %v", err)
 	}
+	volumesCM := namesReferencedFrom(usedCM, `^spec\.(volumes|initContainers\.volumeMounts)$|\.configMap$`)
+	envCM := namesReferencedFrom(usedCM, `^spec\.containers\.env\.`)
+	envFromCM := namesReferencedFrom(usedCM, `^spec\.containers\.envFrom\.`)
+	envFromContainerCM := namesReferencedFrom(usedCM, `^spec\.containers\.envFrom\.configMapRef$`)
+	envFromInitContainerCM := namesReferencedFrom(usedCM, `^spec\.initContainers\.env(From)?\.`)
 
 	expectedVolumesCM := []string{
 		"configmap-1",
@@ -229,7 +234,7 @@ func TestGetUnusedConfigmapsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedConfigmaps(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedConfigmaps(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedConfigmapsStructured: %v", err)
 	}
@@ -290,7 +295,7 @@ func TestFilterOwnerReferencedConfigMaps(t *testing.T) {
 
 	// Test without filter - should return both
 	filterOptsNoSkip := &filters.Options{IgnoreOwnerReferences: false}
-	unusedWithoutFilter, err := processNamespaceCM(clientset, testNamespace, filterOptsNoSkip, common.Opts{})
+	unusedWithoutFilter, err := processNamespaceCM(context.TODO(), clientset, testNamespace, filterOptsNoSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused configmaps: %v", err)
 	}
@@ -301,7 +306,7 @@ func TestFilterOwnerReferencedConfigMaps(t *testing.T) {
 
 	// Test with filter - should return only standalone
 	filterOptsWithSkip := &filters.Options{IgnoreOwnerReferences: true}
-	unusedWithFilter, err := processNamespaceCM(clientset, testNamespace, filterOptsWithSkip, common.Opts{})
+	unusedWithFilter, err := processNamespaceCM(context.TODO(), clientset, testNamespace, filterOptsWithSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused configmaps: %v", err)
 	}
@@ -1,13 +1,10 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
-	"fmt"
-	"os"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -21,10 +18,10 @@ import (
 //go:embed exceptions/crds/crds.json
 var crdsConfig []byte
 
-func processCrds(apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+func processCrds(ctx context.Context, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
 	var unusedCRDs []ResourceInfo
 
-	crds, err := apiExtClient.ApiextensionsV1().CustomResourceDefinitions().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	crds, err := apiExtClient.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +77,7 @@ func processCrds(apiExtClient apiextensionsclientset.Interface, dynamicClient dy
 				Version:  version,
 				Resource: crd.Spec.Names.Plural,
 			}
-			instances, err := dynamicClient.Resource(gvr).Namespace("").List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+			instances, err := dynamicClient.Resource(gvr).Namespace("").List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 			if err != nil {
 				// If we get an error querying the resource, skip this version
 				continue
@@ -99,36 +96,22 @@ func processCrds(apiExtClient apiextensionsclientset.Interface, dynamicClient dy
 	return unusedCRDs, nil
 }
 
-func GetUnusedCrds(_ *filters.Options, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	diff, err := processCrds(apiExtClient, dynamicClient, &filters.Options{})
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to process crds: %v\n", err)
-	}
-	switch opts.GroupBy {
-	case "namespace":
-		resources[""] = make(map[string][]ResourceInfo)
-		resources[""]["Crd"] = diff
-	case "resource":
-		appendResources(resources, "Crd", "", diff)
+func detectCrds(ctx context.Context, clients Clients, _ string, filterOpts *filters.Options, _ common.Opts) ([]ResourceInfo, error) {
+	if err := requireDynamic(clients); err != nil {
+		return nil, err
 	}
+	return processCrds(ctx, clients.APIExtensions, clients.Dynamic, filterOpts)
+}
 
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var crdDetector = newDetector("Crd", []string{"customresourcedefinition", "crd", "crds", "customresourcedefinitions"}, ClusterScoped, detectCrds,
+	func(clients Clients, _ string) typedClient[*apiextensionsv1.CustomResourceDefinition, *apiextensionsv1.CustomResourceDefinitionList] {
+		return clients.APIExtensions.ApiextensionsV1().CustomResourceDefinitions()
+	}).withRequires(requireAPIExtensions)
 
-	unusedCRDs, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(crdDetector)
+}
 
-	return unusedCRDs, nil
+func GetUnusedCrds(ctx context.Context, filterOpts *filters.Options, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{crdDetector}, filterOpts, Clients{APIExtensions: apiExtClient, Dynamic: dynamicClient}, outputFormat, opts)
 }
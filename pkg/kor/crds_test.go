@@ -1,6 +1,7 @@
 package kor
 
 import (
+	"context"
 	"testing"
 
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
@@ -122,7 +123,7 @@ func TestProcessCrds(t *testing.T) {
 	apiExtClient, dynamicClient := createTestCRDs(t)
 
 	filterOpts := &filters.Options{}
-	unusedCRDs, err := processCrds(apiExtClient, dynamicClient, filterOpts)
+	unusedCRDs, err := processCrds(context.TODO(), apiExtClient, dynamicClient, filterOpts)
 	if err != nil {
 		t.Fatalf("Error processing CRDs: %v", err)
 	}
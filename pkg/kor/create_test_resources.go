@@ -2,10 +2,13 @@ package kor
 
 import (
 	"fmt"
+	"time"
 
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -378,6 +381,21 @@ func CreateTestJob(namespace, name string, status *batchv1.JobStatus, labels map
 	}
 }
 
+func CreateTestCronJob(namespace, name string, suspend bool, status *batchv1.CronJobStatus, labels map[string]string) *batchv1.CronJob {
+	return &batchv1.CronJob{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule: "*/5 * * * *",
+			Suspend:  &suspend,
+		},
+		Status: *status,
+	}
+}
+
 func CreateTestReplicaSet(namespace, name string, specReplicas *int32, status *appsv1.ReplicaSetStatus) *appsv1.ReplicaSet {
 	return &appsv1.ReplicaSet{
 		ObjectMeta: v1.ObjectMeta{
@@ -501,6 +519,15 @@ func CreateTestNode(name string) *corev1.Node {
 	}
 }
 
+func CreateTestNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
 func CreateTestCSIDriver(name string) *storagev1.CSIDriver {
 	return &storagev1.CSIDriver{
 		ObjectMeta: v1.ObjectMeta{Name: name},
@@ -513,3 +540,69 @@ func CreateTestPriorityClass(name string, value int32) *schedulingv1.PriorityCla
 		Value:      value,
 	}
 }
+
+func CreateTestValidatingWebhookConfiguration(name, serviceNamespace, serviceName string, failurePolicy admissionregistrationv1.FailurePolicyType) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: v1.ObjectMeta{Name: name},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{
+				Name: name + ".example.com",
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{Namespace: serviceNamespace, Name: serviceName},
+				},
+				FailurePolicy: &failurePolicy,
+			},
+		},
+	}
+}
+
+func CreateTestMutatingWebhookConfiguration(name, serviceNamespace, serviceName string, failurePolicy admissionregistrationv1.FailurePolicyType) *admissionregistrationv1.MutatingWebhookConfiguration {
+	return &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: v1.ObjectMeta{Name: name},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{
+			{
+				Name: name + ".example.com",
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{Namespace: serviceNamespace, Name: serviceName},
+				},
+				FailurePolicy: &failurePolicy,
+			},
+		},
+	}
+}
+
+func CreateTestResourceQuota(namespace, name string, hard, used corev1.ResourceList) *corev1.ResourceQuota {
+	return &corev1.ResourceQuota{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec:   corev1.ResourceQuotaSpec{Hard: hard},
+		Status: corev1.ResourceQuotaStatus{Hard: hard, Used: used},
+	}
+}
+
+func CreateTestLimitRange(namespace, name string, creationTimestamp v1.Time, limits ...corev1.LimitRangeItem) *corev1.LimitRange {
+	return &corev1.LimitRange{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			CreationTimestamp: creationTimestamp,
+		},
+		Spec: corev1.LimitRangeSpec{Limits: limits},
+	}
+}
+
+func CreateTestLease(namespace, name string, renewTime time.Time) *coordinationv1.Lease {
+	renew := v1.NewMicroTime(renewTime)
+	return &coordinationv1.Lease{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity: ptrToString(name),
+			RenewTime:      &renew,
+		},
+	}
+}
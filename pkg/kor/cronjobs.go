@@ -0,0 +1,145 @@
+package kor
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+//go:embed exceptions/cronjobs/cronjobs.json
+var cronJobsConfig []byte
+
+// DefaultCronJobUnscheduledAge is how long a CronJob may exist without ever
+// being scheduled before it is reported as unused.
+const DefaultCronJobUnscheduledAge = 7 * 24 * time.Hour
+
+// retrieveCronJobJobs groups the Jobs in a namespace by the name of the CronJob owning them
+func retrieveCronJobJobs(ctx context.Context, clientset kubernetes.Interface, namespace string) (map[string][]batchv1.Job, error) {
+	jobsList, err := clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	cronJobJobs := make(map[string][]batchv1.Job)
+	for _, job := range jobsList.Items {
+		for _, owner := range job.OwnerReferences {
+			if owner.Kind == "CronJob" {
+				cronJobJobs[owner.Name] = append(cronJobJobs[owner.Name], job)
+			}
+		}
+	}
+	return cronJobJobs, nil
+}
+
+func isJobFailed(job batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOnlyFailedJobs reports whether a CronJob has produced Jobs and every one of them failed
+func hasOnlyFailedJobs(cronJob batchv1.CronJob, jobs []batchv1.Job) bool {
+	if cronJob.Status.LastSuccessfulTime != nil || len(jobs) == 0 {
+		return false
+	}
+	for _, job := range jobs {
+		if !isJobFailed(job) {
+			return false
+		}
+	}
+	return true
+}
+
+func processNamespaceCronJobs(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	cronJobsList, err := clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := unmarshalConfig(cronJobsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	cronJobJobs, err := retrieveCronJobJobs(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	unscheduledAge := opts.CronJobUnscheduledAge
+	if unscheduledAge == 0 {
+		unscheduledAge = DefaultCronJobUnscheduledAge
+	}
+
+	var unusedCronJobNames []ResourceInfo
+
+	for _, cronJob := range cronJobsList.Items {
+		if pass, _ := filter.SetObject(&cronJob).Run(filterOpts); pass {
+			continue
+		}
+
+		if cronJob.Labels["kor/used"] == "false" {
+			reason := "Marked with unused label"
+			unusedCronJobNames = append(unusedCronJobNames, ResourceInfo{Name: cronJob.Name, Reason: reason})
+			continue
+		}
+
+		// Skip resources with ownerReferences if the general flag is set
+		if filterOpts.IgnoreOwnerReferences && len(cronJob.OwnerReferences) > 0 {
+			continue
+		}
+
+		exceptionFound, err := isResourceException(cronJob.Name, cronJob.Namespace, config.ExceptionCronJobs)
+		if err != nil {
+			return nil, err
+		}
+
+		if exceptionFound {
+			continue
+		}
+
+		if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
+			reason := "CronJob is suspended"
+			unusedCronJobNames = append(unusedCronJobNames, ResourceInfo{Name: cronJob.Name, Reason: reason})
+			continue
+		}
+
+		if cronJob.Status.LastScheduleTime == nil && time.Since(cronJob.CreationTimestamp.Time) > unscheduledAge {
+			reason := fmt.Sprintf("CronJob has not been scheduled since it was created more than %s ago", unscheduledAge)
+			unusedCronJobNames = append(unusedCronJobNames, ResourceInfo{Name: cronJob.Name, Reason: reason})
+			continue
+		}
+
+		if hasOnlyFailedJobs(cronJob, cronJobJobs[cronJob.Name]) {
+			reason := "CronJob has only produced failed Jobs"
+			unusedCronJobNames = append(unusedCronJobNames, ResourceInfo{Name: cronJob.Name, Reason: reason})
+		}
+	}
+
+	return unusedCronJobNames, nil
+}
+
+var cronJobDetector = newDetector("CronJob", []string{"cronjob", "cj", "cronjobs"}, NamespaceScoped, namespacedDetect(processNamespaceCronJobs),
+	func(clients Clients, namespace string) typedClient[*batchv1.CronJob, *batchv1.CronJobList] {
+		return clients.Clientset.BatchV1().CronJobs(namespace)
+	})
+
+func init() {
+	MustRegisterDetector(cronJobDetector)
+}
+
+func GetUnusedCronJobs(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{cronJobDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
+}
@@ -0,0 +1,185 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestCronJobs(t *testing.T) *fake.Clientset {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{Name: testNamespace},
+	}, v1.CreateOptions{})
+
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	lastScheduleTime := &v1.Time{Time: time.Now().Add(-time.Hour)}
+
+	cronJob1 := CreateTestCronJob(testNamespace, "test-cronjob1", true, &batchv1.CronJobStatus{
+		LastScheduleTime: lastScheduleTime,
+	}, AppLabels)
+
+	cronJob2 := CreateTestCronJob(testNamespace, "test-cronjob2", false, &batchv1.CronJobStatus{}, AppLabels)
+	cronJob2.CreationTimestamp = v1.Time{Time: time.Now().Add(-8 * 24 * time.Hour)}
+
+	cronJob3 := CreateTestCronJob(testNamespace, "test-cronjob3", false, &batchv1.CronJobStatus{}, AppLabels)
+	cronJob3.CreationTimestamp = v1.Time{Time: time.Now()}
+
+	cronJob4 := CreateTestCronJob(testNamespace, "test-cronjob4", false, &batchv1.CronJobStatus{
+		LastScheduleTime: lastScheduleTime,
+	}, AppLabels)
+
+	cronJob5 := CreateTestCronJob(testNamespace, "test-cronjob5", false, &batchv1.CronJobStatus{
+		LastScheduleTime:   lastScheduleTime,
+		LastSuccessfulTime: lastScheduleTime,
+	}, AppLabels)
+
+	cronJob6 := CreateTestCronJob(testNamespace, "test-cronjob6", true, &batchv1.CronJobStatus{}, UsedLabels)
+
+	cronJob7 := CreateTestCronJob(testNamespace, "test-cronjob7", false, &batchv1.CronJobStatus{
+		LastScheduleTime:   lastScheduleTime,
+		LastSuccessfulTime: lastScheduleTime,
+	}, UnusedLabels)
+
+	for _, cronJob := range []*batchv1.CronJob{cronJob1, cronJob2, cronJob3, cronJob4, cronJob5, cronJob6, cronJob7} {
+		_, err = clientset.BatchV1().CronJobs(testNamespace).Create(context.TODO(), cronJob, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake cronjob: %v", err)
+		}
+	}
+
+	failedStatus := &batchv1.JobStatus{
+		Failed: 1,
+		Conditions: []batchv1.JobCondition{
+			{
+				Type:    batchv1.JobFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "BackoffLimitExceeded",
+				Message: "Job has reached the specified backoff limit",
+			},
+		},
+	}
+
+	failedJob1 := CreateTestJob(testNamespace, "test-cronjob4-1", failedStatus, AppLabels)
+	failedJob1.OwnerReferences = []v1.OwnerReference{{Kind: "CronJob", Name: "test-cronjob4"}}
+
+	failedJob2 := CreateTestJob(testNamespace, "test-cronjob4-2", failedStatus, AppLabels)
+	failedJob2.OwnerReferences = []v1.OwnerReference{{Kind: "CronJob", Name: "test-cronjob4"}}
+
+	failedJob3 := CreateTestJob(testNamespace, "test-cronjob5-1", failedStatus, AppLabels)
+	failedJob3.OwnerReferences = []v1.OwnerReference{{Kind: "CronJob", Name: "test-cronjob5"}}
+
+	succeededJob := CreateTestJob(testNamespace, "test-cronjob5-2", &batchv1.JobStatus{
+		Succeeded:      1,
+		CompletionTime: &v1.Time{Time: time.Now()},
+	}, AppLabels)
+	succeededJob.OwnerReferences = []v1.OwnerReference{{Kind: "CronJob", Name: "test-cronjob5"}}
+
+	for _, job := range []*batchv1.Job{failedJob1, failedJob2, failedJob3, succeededJob} {
+		_, err = clientset.BatchV1().Jobs(testNamespace).Create(context.TODO(), job, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake job: %v", err)
+		}
+	}
+
+	return clientset
+}
+
+func TestProcessNamespaceCronJobs(t *testing.T) {
+	clientset := createTestCronJobs(t)
+
+	unusedCronJobs, err := processNamespaceCronJobs(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	expectedCronJobs := []ResourceInfo{
+		{Name: "test-cronjob1", Reason: "CronJob is suspended"},
+		{Name: "test-cronjob2", Reason: "CronJob has not been scheduled since it was created more than 168h0m0s ago"},
+		{Name: "test-cronjob4", Reason: "CronJob has only produced failed Jobs"},
+		{Name: "test-cronjob7", Reason: "Marked with unused label"},
+	}
+
+	if len(unusedCronJobs) != len(expectedCronJobs) {
+		t.Fatalf("Expected %d cronjobs unused got %d", len(expectedCronJobs), len(unusedCronJobs))
+	}
+
+	for i, cronJob := range unusedCronJobs {
+		if cronJob != expectedCronJobs[i] {
+			t.Errorf("Expected %v, got %v", expectedCronJobs[i], cronJob)
+		}
+	}
+}
+
+func TestProcessNamespaceCronJobsUnscheduledAge(t *testing.T) {
+	clientset := createTestCronJobs(t)
+
+	unusedCronJobs, err := processNamespaceCronJobs(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{CronJobUnscheduledAge: 30 * 24 * time.Hour})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if resourceInfoContains(unusedCronJobs, "test-cronjob2") {
+		t.Errorf("Expected test-cronjob2 to be considered used with a 30 day unscheduled age")
+	}
+}
+
+func TestGetUnusedCronJobsStructured(t *testing.T) {
+	clientset := createTestCronJobs(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedCronJobs(context.TODO(), &filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedCronJobsStructured: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"CronJob": {
+				"test-cronjob1",
+				"test-cronjob2",
+				"test-cronjob4",
+				"test-cronjob7",
+			},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
+
+func init() {
+	scheme.Scheme = runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme.Scheme)
+}
@@ -1,13 +1,10 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
-	"fmt"
-	"os"
 
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
@@ -18,8 +15,8 @@ import (
 //go:embed exceptions/daemonsets/daemonsets.json
 var daemonsetsConfig []byte
 
-func processNamespaceDaemonSets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	daemonSetsList, err := clientset.AppsV1().DaemonSets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespaceDaemonSets(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	daemonSetsList, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -61,47 +58,18 @@ func processNamespaceDaemonSets(clientset kubernetes.Interface, namespace string
 			daemonSetsWithoutReplicas = append(daemonSetsWithoutReplicas, ResourceInfo{Name: daemonSet.Name, Reason: reason})
 		}
 	}
-	if opts.DeleteFlag {
-		if daemonSetsWithoutReplicas, err = DeleteResource(daemonSetsWithoutReplicas, clientset, namespace, "DaemonSet", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete DaemonSet %s in namespace %s: %v\n", daemonSetsWithoutReplicas, namespace, err)
-		}
-	}
 	return daemonSetsWithoutReplicas, nil
 }
 
-func GetUnusedDaemonSets(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceDaemonSets(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["DaemonSet"] = diff
-		case "resource":
-			appendResources(resources, "DaemonSet", namespace, diff)
-		}
-	}
+var daemonSetDetector = newDetector("DaemonSet", []string{"daemonset", "ds", "daemonsets"}, NamespaceScoped, namespacedDetect(processNamespaceDaemonSets),
+	func(clients Clients, namespace string) typedClient[*appsv1.DaemonSet, *appsv1.DaemonSetList] {
+		return clients.Clientset.AppsV1().DaemonSets(namespace)
+	})
 
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
-
-	unusedDaemonSets, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(daemonSetDetector)
+}
 
-	return unusedDaemonSets, nil
+func GetUnusedDaemonSets(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{daemonSetDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -106,7 +106,7 @@ func createTestDaemonSetsWithOwnerReferences(t *testing.T) *fake.Clientset {
 func TestProcessNamespaceDaemonSets(t *testing.T) {
 	clientset := createTestDaemonSets(t)
 
-	daemonSetsWithoutReplicas, err := processNamespaceDaemonSets(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	daemonSetsWithoutReplicas, err := processNamespaceDaemonSets(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -124,7 +124,7 @@ func TestProcessNamespaceDaemonSetsWithOwnerReferences(t *testing.T) {
 	clientset := createTestDaemonSetsWithOwnerReferences(t)
 
 	// Test with --ignore-owner-references=false (default behavior)
-	daemonSetsWithoutReplicas, err := processNamespaceDaemonSets(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	daemonSetsWithoutReplicas, err := processNamespaceDaemonSets(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -135,7 +135,7 @@ func TestProcessNamespaceDaemonSetsWithOwnerReferences(t *testing.T) {
 	}
 
 	// Test with --ignore-owner-references=true
-	daemonSetsWithoutReplicas, err = processNamespaceDaemonSets(clientset, testNamespace, &filters.Options{IgnoreOwnerReferences: true}, common.Opts{})
+	daemonSetsWithoutReplicas, err = processNamespaceDaemonSets(context.TODO(), clientset, testNamespace, &filters.Options{IgnoreOwnerReferences: true}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -162,7 +162,7 @@ func TestGetUnusedDaemonSetsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedDaemonSets(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedDaemonSets(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedDaemonSetsStructured: %v", err)
 	}
@@ -199,7 +199,7 @@ func TestGetUnusedDaemonSetsStructuredWithOwnerReferences(t *testing.T) {
 	}
 
 	// Test with --ignore-owner-references=true
-	output, err := GetUnusedDaemonSets(&filters.Options{IgnoreOwnerReferences: true}, clientset, "json", opts)
+	output, err := GetUnusedDaemonSets(context.TODO(), &filters.Options{IgnoreOwnerReferences: true}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedDaemonSetsStructured: %v", err)
 	}
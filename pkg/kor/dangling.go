@@ -0,0 +1,287 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/olekukonko/tablewriter"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// DanglingReference is a reference to an object that doesn't exist, from the
+// field of the object holding it
+type DanglingReference struct {
+	Kind        string `json:"kind"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name"`
+	Field       string `json:"field"`
+	MissingKind string `json:"missingKind"`
+	MissingName string `json:"missingName"`
+}
+
+// DanglingReport lists the references to missing objects found by
+// FindDanglingReferences
+type DanglingReport struct {
+	References []DanglingReference `json:"references"`
+	// Errors lists the namespaces that could not be checked
+	Errors []ScanError `json:"errors,omitempty"`
+}
+
+// podSpecSource is the pod spec of a pod or of the pod template of a
+// workload, source.Field being its path in the object
+type podSpecSource struct {
+	spec   *corev1.PodSpec
+	source Reference
+}
+
+// controlledByWorkload tells whether an object is created from the pod
+// template of a workload, which is checked instead of it
+func controlledByWorkload(obj metav1.Object) bool {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil {
+		return false
+	}
+	switch owner.Kind {
+	case "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "Job", "CronJob":
+		return true
+	}
+	return false
+}
+
+// retrievePodSpecs returns the pod specs of the pods and workloads of a
+// namespace, leaving out the pods, ReplicaSets and Jobs created by another
+// workload
+func retrievePodSpecs(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]podSpecSource, error) {
+	var specs []podSpecSource
+	source := func(kind string, obj metav1.Object, field string) Reference {
+		return Reference{Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName(), Field: field}
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %v", err)
+	}
+	for i := range pods.Items {
+		if pod := &pods.Items[i]; !controlledByWorkload(pod) {
+			specs = append(specs, podSpecSource{&pod.Spec, source("Pod", pod, "spec")})
+		}
+	}
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %v", err)
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		specs = append(specs, podSpecSource{&deployment.Spec.Template.Spec, source("Deployment", deployment, "spec.template.spec")})
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %v", err)
+	}
+	for i := range statefulSets.Items {
+		statefulSet := &statefulSets.Items[i]
+		specs = append(specs, podSpecSource{&statefulSet.Spec.Template.Spec, source("StatefulSet", statefulSet, "spec.template.spec")})
+	}
+
+	daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %v", err)
+	}
+	for i := range daemonSets.Items {
+		daemonSet := &daemonSets.Items[i]
+		specs = append(specs, podSpecSource{&daemonSet.Spec.Template.Spec, source("DaemonSet", daemonSet, "spec.template.spec")})
+	}
+
+	replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %v", err)
+	}
+	for i := range replicaSets.Items {
+		if replicaSet := &replicaSets.Items[i]; !controlledByWorkload(replicaSet) {
+			specs = append(specs, podSpecSource{&replicaSet.Spec.Template.Spec, source("ReplicaSet", replicaSet, "spec.template.spec")})
+		}
+	}
+
+	jobs, err := clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %v", err)
+	}
+	for i := range jobs.Items {
+		if job := &jobs.Items[i]; !controlledByWorkload(job) {
+			specs = append(specs, podSpecSource{&job.Spec.Template.Spec, source("Job", job, "spec.template.spec")})
+		}
+	}
+
+	cronJobs, err := clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %v", err)
+	}
+	for i := range cronJobs.Items {
+		cronJob := &cronJobs.Items[i]
+		specs = append(specs, podSpecSource{&cronJob.Spec.JobTemplate.Spec.Template.Spec, source("CronJob", cronJob, "spec.jobTemplate.spec.template.spec")})
+	}
+
+	return specs, nil
+}
+
+// findNamespaceDanglingReferences returns the references of a namespace to
+// objects missing, leaving out the optional ones
+func findNamespaceDanglingReferences(ctx context.Context, clientset kubernetes.Interface, index *objectIndex, namespace string) ([]DanglingReference, error) {
+	specs, err := retrievePodSpecs(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]references{
+		"ConfigMap":             {},
+		"Secret":                {},
+		"PersistentVolumeClaim": {},
+		"ServiceAccount":        {},
+		"PriorityClass":         {},
+	}
+	for _, spec := range specs {
+		addPodSpecConfigMaps(used["ConfigMap"], spec.spec, spec.source)
+		addPodSpecSecrets(used["Secret"], spec.spec, spec.source)
+		addPodSpecPvcs(used["PersistentVolumeClaim"], spec.spec, spec.source)
+		if spec.spec.ServiceAccountName != "" {
+			used["ServiceAccount"].addReference(spec.spec.ServiceAccountName, spec.source.at(".serviceAccountName", nil))
+		}
+		if spec.spec.PriorityClassName != "" {
+			used["PriorityClass"].addReference(spec.spec.PriorityClassName, spec.source.at(".priorityClassName", nil))
+		}
+	}
+
+	tlsSecrets, err := retrieveIngressTLS(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	// an Ingress TLS without a secretName uses the default certificate
+	delete(tlsSecrets, "")
+	used["Secret"].merge(tlsSecrets)
+	if used["Service"], err = retrieveIngressBackends(ctx, clientset, namespace); err != nil {
+		return nil, err
+	}
+	targets, err := retrieveHpaTargets(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for kind, targetReferences := range targets {
+		if used[kind] == nil {
+			used[kind] = references{}
+		}
+		used[kind].merge(targetReferences)
+	}
+
+	var dangling []DanglingReference
+	for _, kind := range slices.Sorted(maps.Keys(used)) {
+		for _, name := range used[kind].names() {
+			exists, err := index.exists(kind, namespace, name)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				continue
+			}
+			for _, reference := range used[kind][name] {
+				if reference.Optional {
+					continue
+				}
+				dangling = append(dangling, DanglingReference{
+					Kind:        reference.Kind,
+					Namespace:   reference.Namespace,
+					Name:        reference.Name,
+					Field:       reference.Field,
+					MissingKind: kind,
+					MissingName: name,
+				})
+			}
+		}
+	}
+	return dangling, nil
+}
+
+// FindDanglingReferences returns the references to objects that don't exist
+// from the pods and pod templates, Ingresses and HPAs of the namespaces
+// selected by filterOpts. Pods reference ConfigMaps, Secrets, PVCs,
+// ServiceAccounts and PriorityClasses, Ingresses their Services and TLS
+// Secrets, and HPAs their scale target. References marked optional are left
+// out. Namespaces that can't be checked are reported in the Errors.
+func FindDanglingReferences(ctx context.Context, clients Clients, filterOpts *filters.Options, opts common.Opts) (*DanglingReport, error) {
+	scanCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		scanCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	clients.Clientset = NewSnapshot(clients.Clientset, len(filterOpts.IncludeNamespaces) == 0)
+	namespaces, err := filterOpts.Namespaces(scanCtx, clients.Clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DanglingReport{References: []DanglingReference{}}
+	index := newObjectIndex(scanCtx, clients)
+	for _, namespace := range slices.Sorted(slices.Values(namespaces)) {
+		if scanCtx.Err() != nil {
+			report.Errors = append(report.Errors, ScanError{Message: fmt.Sprintf("check stopped before completion, results are incomplete: %v", scanCtx.Err())})
+			break
+		}
+		dangling, err := findNamespaceDanglingReferences(scanCtx, clients.Clientset, index, namespace)
+		if err != nil {
+			report.Errors = append(report.Errors, ScanError{Namespace: namespace, Message: err.Error()})
+			continue
+		}
+		report.References = append(report.References, dangling...)
+	}
+	return report, nil
+}
+
+// FormatDanglingReport formats the dangling references as a table per
+// namespace, json or yaml
+func FormatDanglingReport(report *DanglingReport, outputFormat string) (string, error) {
+	switch outputFormat {
+	case "json", "yaml":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		if outputFormat == "yaml" {
+			if data, err = yaml.JSONToYAML(data); err != nil {
+				return "", err
+			}
+		}
+		return string(data) + "\n", nil
+	}
+
+	if len(report.References) == 0 {
+		return "No broken references found\n", nil
+	}
+	byNamespace := make(map[string][]DanglingReference)
+	for _, reference := range report.References {
+		byNamespace[reference.Namespace] = append(byNamespace[reference.Namespace], reference)
+	}
+	var output bytes.Buffer
+	for _, namespace := range slices.Sorted(maps.Keys(byNamespace)) {
+		var buf bytes.Buffer
+		table := tablewriter.NewWriter(&buf)
+		table.SetColWidth(60)
+		table.SetHeader([]string{"#", "RESOURCE TYPE", "RESOURCE NAME", "FIELD", "MISSING"})
+		for i, reference := range byNamespace[namespace] {
+			table.Append([]string{fmt.Sprintf("%d", i+1), reference.Kind, reference.Name, reference.Field, reference.MissingKind + " " + reference.MissingName})
+		}
+		table.Render()
+		fmt.Fprintf(&output, "Broken references in namespace: %q\n%s\n", namespace, buf.String())
+	}
+	return output.String(), nil
+}
@@ -0,0 +1,125 @@
+package kor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestDangling(t *testing.T) *fake.Clientset {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, "existing-cm", AppLabels), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake configmap: %v", err)
+	}
+
+	_, err = clientset.CoreV1().PersistentVolumeClaims(testNamespace).Create(context.TODO(), CreateTestPvc(testNamespace, "existing-pvc", AppLabels, "test-sc"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake pvc: %v", err)
+	}
+
+	pod := CreateTestPod(testNamespace, "test-pod", "missing-sa", []corev1.Volume{*CreateTestVolume("data", "existing-pvc")}, AppLabels)
+	pod.Spec.Containers = []corev1.Container{{
+		EnvFrom: []corev1.EnvFromSource{
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "existing-cm"}}},
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "missing-cm"}}},
+			{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "optional-secret"}, Optional: ptrToBool(true)}},
+		},
+	}}
+	_, err = clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake pod: %v", err)
+	}
+
+	// created by a ReplicaSet, whose template is checked instead
+	ownedPod := CreateTestPod(testNamespace, "owned-pod", "missing-sa", nil, AppLabels)
+	ownedPod.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "test-rs", Controller: ptrToBool(true)}}
+	_, err = clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), ownedPod, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake pod: %v", err)
+	}
+
+	deployment := CreateTestDeployment(testNamespace, "test-deployment", 1, AppLabels)
+	deployment.Spec.Template.Spec.Volumes = []corev1.Volume{{
+		Name:         "certs",
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "missing-secret"}},
+	}}
+	_, err = clientset.AppsV1().Deployments(testNamespace).Create(context.TODO(), deployment, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake deployment: %v", err)
+	}
+
+	_, err = clientset.NetworkingV1().Ingresses(testNamespace).Create(context.TODO(), CreateTestIngress(testNamespace, "test-ingress", "missing-service", "missing-tls", AppLabels), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake ingress: %v", err)
+	}
+
+	_, err = clientset.AutoscalingV2().HorizontalPodAutoscalers(testNamespace).Create(context.TODO(), CreateTestHpa(testNamespace, "test-hpa", "missing-deployment", 1, 2, AppLabels), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake hpa: %v", err)
+	}
+
+	return clientset
+}
+
+func TestFindDanglingReferences(t *testing.T) {
+	clients := Clients{Clientset: createTestDangling(t)}
+
+	report, err := FindDanglingReferences(context.TODO(), clients, &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Error finding dangling references: %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("Expected no errors, got %v", report.Errors)
+	}
+
+	expected := []DanglingReference{
+		{Kind: "Pod", Namespace: testNamespace, Name: "test-pod", Field: "spec.containers[0].envFrom[1].configMapRef", MissingKind: "ConfigMap", MissingName: "missing-cm"},
+		{Kind: "HorizontalPodAutoscaler", Namespace: testNamespace, Name: "test-hpa", Field: "spec.scaleTargetRef", MissingKind: "Deployment", MissingName: "missing-deployment"},
+		{Kind: "Deployment", Namespace: testNamespace, Name: "test-deployment", Field: "spec.template.spec.volumes[0].secret", MissingKind: "Secret", MissingName: "missing-secret"},
+		{Kind: "Ingress", Namespace: testNamespace, Name: "test-ingress", Field: "spec.tls[0].secretName", MissingKind: "Secret", MissingName: "missing-tls"},
+		{Kind: "Ingress", Namespace: testNamespace, Name: "test-ingress", Field: "spec.rules[0].http.paths[0].backend.service", MissingKind: "Service", MissingName: "missing-service"},
+		{Kind: "Pod", Namespace: testNamespace, Name: "test-pod", Field: "spec.serviceAccountName", MissingKind: "ServiceAccount", MissingName: "missing-sa"},
+	}
+	if !reflect.DeepEqual(report.References, expected) {
+		t.Errorf("Expected dangling references:\n%v\ngot:\n%v", expected, report.References)
+	}
+}
+
+func TestFormatDanglingReport(t *testing.T) {
+	report := &DanglingReport{References: []DanglingReference{
+		{Kind: "Pod", Namespace: testNamespace, Name: "test-pod", Field: "spec.serviceAccountName", MissingKind: "ServiceAccount", MissingName: "missing-sa"},
+	}}
+
+	output, err := FormatDanglingReport(report, "table")
+	if err != nil {
+		t.Fatalf("Error formatting report: %v", err)
+	}
+	for _, expected := range []string{`Broken references in namespace: "test-namespace"`, "spec.serviceAccountName", "ServiceAccount missing-sa"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in the output, got:\n%s", expected, output)
+		}
+	}
+
+	output, err = FormatDanglingReport(&DanglingReport{}, "table")
+	if err != nil {
+		t.Fatalf("Error formatting report: %v", err)
+	}
+	if output != "No broken references found\n" {
+		t.Errorf("Expected no broken references, got %q", output)
+	}
+}
@@ -2,107 +2,63 @@ package kor
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"reflect"
-	"strings"
 
-	appsv1 "k8s.io/api/apps/v1"
-	autoscalingv1 "k8s.io/api/autoscaling/v1"
-	batchv1 "k8s.io/api/batch/v1"
-	corev1 "k8s.io/api/core/v1"
-	networkingv1 "k8s.io/api/networking/v1"
-	policyv1beta1 "k8s.io/api/policy/v1beta1"
-	rbacv1 "k8s.io/api/rbac/v1"
-	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
 )
 
-func DeleteResourceCmd() map[string]func(clientset kubernetes.Interface, namespace, name string) error {
-	var deleteResourceApiMap = map[string]func(clientset kubernetes.Interface, namespace, name string) error{
-		"ConfigMap": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"Secret": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().Secrets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"Service": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().Services(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"Deployment": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.AppsV1().Deployments(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"HPA": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"Ingress": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.NetworkingV1().Ingresses(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"PDB": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.PolicyV1beta1().PodDisruptionBudgets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"Role": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.RbacV1().Roles(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"ClusterRole": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.RbacV1().ClusterRoles().Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"ClusterRoleBinding": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.RbacV1().ClusterRoleBindings().Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"PVC": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"StatefulSet": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.AppsV1().StatefulSets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"ServiceAccount": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().ServiceAccounts(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"PV": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().PersistentVolumes().Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"Pod": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.CoreV1().Pods(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"Job": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.BatchV1().Jobs(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"ReplicaSet": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.AppsV1().ReplicaSets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"DaemonSet": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.AppsV1().DaemonSets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"StorageClass": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.StorageV1().StorageClasses().Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"NetworkPolicy": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.NetworkingV1().NetworkPolicies(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"RoleBinding": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.RbacV1().RoleBindings(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"VolumeAttachment": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.StorageV1().VolumeAttachments().Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
-		"PriorityClass": func(clientset kubernetes.Interface, namespace, name string) error {
-			return clientset.SchedulingV1().PriorityClasses().Delete(context.TODO(), name, metav1.DeleteOptions{})
-		},
+// Dry-run modes of --delete. A client dry run only reports what would be
+// deleted, a server dry run sends every delete with DryRun set so admission
+// and RBAC are checked without persisting anything.
+const (
+	DryRunClient = "client"
+	DryRunServer = "server"
+)
+
+// ValidateDryRun checks a --dry-run mode, the empty string meaning no dry run
+func ValidateDryRun(dryRun string) error {
+	switch dryRun {
+	case "", DryRunClient, DryRunServer:
+		return nil
+	default:
+		return fmt.Errorf("invalid dry run mode %q, must be %q or %q", dryRun, DryRunClient, DryRunServer)
+	}
+}
+
+// dryRunOption returns the DryRun field of the delete and patch options
+func dryRunOption(dryRun string) []string {
+	if dryRun == DryRunServer {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+// DeleteResourceCmd returns the delete function of every registered detector,
+// keyed by the detector name. Each kind needs the client it is read with in
+// clients, e.g. APIExtensions for CRDs and Dynamic for the Gateway API kinds.
+func DeleteResourceCmd() map[string]func(ctx context.Context, clients Clients, namespace, name string) error {
+	deleteResourceApiMap := make(map[string]func(ctx context.Context, clients Clients, namespace, name string) error)
+	for _, detector := range Detectors() {
+		deleteResourceApiMap[detector.Name()] = func(ctx context.Context, clients Clients, namespace, name string) error {
+			return detector.Delete(ctx, clients, namespace, name, metav1.DeleteOptions{})
+		}
 	}
 
 	return deleteResourceApiMap
 }
 
-func FlagDynamicResource(dynamicClient dynamic.Interface, namespace string, gvr schema.GroupVersionResource, resourceName string) error {
+func FlagDynamicResource(ctx context.Context, dynamicClient dynamic.Interface, namespace string, gvr schema.GroupVersionResource, resourceName string) error {
 	resource, err := dynamicClient.
 		Resource(gvr).
 		Namespace(namespace).
-		Get(context.TODO(), resourceName, metav1.GetOptions{})
+		Get(ctx, resourceName, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
@@ -116,233 +72,239 @@ func FlagDynamicResource(dynamicClient dynamic.Interface, namespace string, gvr
 	_, err = dynamicClient.
 		Resource(gvr).
 		Namespace(namespace).
-		Update(context.TODO(), resource, metav1.UpdateOptions{})
+		Update(ctx, resource, metav1.UpdateOptions{})
 	return err
 }
 
-func FlagResource(clientset kubernetes.Interface, namespace, resourceType, resourceName string) error {
-	resource, err := getResource(clientset, namespace, resourceType, resourceName)
+func FlagResource(ctx context.Context, clients Clients, namespace, resourceType, resourceName string) error {
+	detector, ok := LookupDetector(resourceType)
+	if !ok {
+		return fmt.Errorf("resource type '%s' is not supported", resourceType)
+	}
+	return detector.Flag(ctx, clients, namespace, resourceName)
+}
+
+// DeleteResourceWithFinalizer removes the finalizers of resources pending
+// deletion, following opts.NoInteractive, opts.DryRun, opts.BackupDir and
+// opts.AuditLog. Audit events need opts.AuditEvents and a clientset, which
+// this function has none of, so they are not created.
+func DeleteResourceWithFinalizer(ctx context.Context, resources []ResourceInfo, dynamicClient dynamic.Interface, namespace string, gvr schema.GroupVersionResource, opts common.Opts) ([]ResourceInfo, error) {
+	if err := ValidateDryRun(opts.DryRun); err != nil {
+		return resources, err
+	}
+	opts.AuditEvents = false
+	audit, err := newAuditLog(opts, nil)
 	if err != nil {
-		return err
+		return resources, err
 	}
 
-	labelField := reflect.ValueOf(resource).Elem().FieldByName("Labels")
-	if labelField.IsValid() {
-		labels := labelField.Interface().(map[string]string)
-		if labels == nil {
-			labels = make(map[string]string)
-		}
-		labels["kor/used"] = "true"
-		labelField.Set(reflect.ValueOf(labels))
-	} else {
-		return fmt.Errorf("unable to set labels for resource type: %s", resourceType)
+	var remainingResources []ResourceInfo
+	findings := newFindings(gvr.Resource, namespace, resources)
+	review, reviewErr := reviewDeletion(ctx, findings, nil, opts, AuditRemoveFinalizers,
+		func(finding Finding) error {
+			return FlagDynamicResource(ctx, dynamicClient, finding.Namespace, gvr, finding.Name)
+		},
+		func(finding Finding) (runtime.Object, error) {
+			return dynamicClient.Resource(gvr).Namespace(finding.Namespace).Get(ctx, finding.Name, metav1.GetOptions{})
+		}, audit)
+	backup := newBackupArchive(opts)
+	findings, err = deleteFinalizerFindings(ctx, findings, dynamicClient, gvr, opts, review, backup, audit)
+	for _, finding := range findings {
+		remainingResources = append(remainingResources, ResourceInfo{Name: finding.displayName(), Reason: finding.Reason})
 	}
 
-	_, err = updateResource(clientset, namespace, resourceType, resource)
-	return err
+	return remainingResources, errors.Join(reviewErr, err, backup.Close(), audit.Close())
 }
 
-func updateResource(clientset kubernetes.Interface, namespace, resourceType string, resource interface{}) (interface{}, error) {
-	switch resourceType {
-	case "ConfigMap":
-		return clientset.CoreV1().ConfigMaps(namespace).Update(context.TODO(), resource.(*corev1.ConfigMap), metav1.UpdateOptions{})
-	case "Secret":
-		return clientset.CoreV1().Secrets(namespace).Update(context.TODO(), resource.(*corev1.Secret), metav1.UpdateOptions{})
-	case "Service":
-		return clientset.CoreV1().Services(namespace).Update(context.TODO(), resource.(*corev1.Service), metav1.UpdateOptions{})
-	case "Deployment":
-		return clientset.AppsV1().Deployments(namespace).Update(context.TODO(), resource.(*appsv1.Deployment), metav1.UpdateOptions{})
-	case "HPA":
-		return clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Update(context.TODO(), resource.(*autoscalingv1.HorizontalPodAutoscaler), metav1.UpdateOptions{})
-	case "Ingress":
-		return clientset.NetworkingV1().Ingresses(namespace).Update(context.TODO(), resource.(*networkingv1.Ingress), metav1.UpdateOptions{})
-	case "PDB":
-		return clientset.PolicyV1beta1().PodDisruptionBudgets(namespace).Update(context.TODO(), resource.(*policyv1beta1.PodDisruptionBudget), metav1.UpdateOptions{})
-	case "Role":
-		return clientset.RbacV1().Roles(namespace).Update(context.TODO(), resource.(*rbacv1.Role), metav1.UpdateOptions{})
-	case "ClusterRole":
-		return clientset.RbacV1().ClusterRoles().Update(context.TODO(), resource.(*rbacv1.ClusterRole), metav1.UpdateOptions{})
-	case "ClusterRoleBinding":
-		return clientset.RbacV1().ClusterRoleBindings().Update(context.TODO(), resource.(*rbacv1.ClusterRoleBinding), metav1.UpdateOptions{})
-	case "PVC":
-		return clientset.CoreV1().PersistentVolumeClaims(namespace).Update(context.TODO(), resource.(*corev1.PersistentVolumeClaim), metav1.UpdateOptions{})
-	case "StatefulSet":
-		return clientset.AppsV1().StatefulSets(namespace).Update(context.TODO(), resource.(*appsv1.StatefulSet), metav1.UpdateOptions{})
-	case "ServiceAccount":
-		return clientset.CoreV1().ServiceAccounts(namespace).Update(context.TODO(), resource.(*corev1.ServiceAccount), metav1.UpdateOptions{})
-	case "PV":
-		return clientset.CoreV1().PersistentVolumes().Update(context.TODO(), resource.(*corev1.PersistentVolume), metav1.UpdateOptions{})
-	case "Pod":
-		return clientset.CoreV1().Pods(namespace).Update(context.TODO(), resource.(*corev1.Pod), metav1.UpdateOptions{})
-	case "Job":
-		return clientset.BatchV1().Jobs(namespace).Update(context.TODO(), resource.(*batchv1.Job), metav1.UpdateOptions{})
-	case "ReplicaSet":
-		return clientset.AppsV1().ReplicaSets(namespace).Update(context.TODO(), resource.(*appsv1.ReplicaSet), metav1.UpdateOptions{})
-	case "DaemonSet":
-		return clientset.AppsV1().DaemonSets(namespace).Update(context.TODO(), resource.(*appsv1.DaemonSet), metav1.UpdateOptions{})
-	case "StorageClass":
-		return clientset.StorageV1().StorageClasses().Update(context.TODO(), resource.(*storagev1.StorageClass), metav1.UpdateOptions{})
-	case "NetworkPolicy":
-		return clientset.NetworkingV1().NetworkPolicies(namespace).Update(context.TODO(), resource.(*networkingv1.NetworkPolicy), metav1.UpdateOptions{})
-	case "RoleBinding":
-		return clientset.RbacV1().RoleBindings(namespace).Update(context.TODO(), resource.(*rbacv1.RoleBinding), metav1.UpdateOptions{})
-	case "VolumeAttachment":
-		return clientset.StorageV1().VolumeAttachments().Update(context.TODO(), resource.(*storagev1.VolumeAttachment), metav1.UpdateOptions{})
+// deleteFinalizerFindings removes the finalizers of resources pending deletion
+// so they can go away, leaving the ones review skipped or flagged as in use
+// with that as their reason. Each resource is saved to backup first, when
+// set, and every action is recorded to audit. The failures are joined in the
+// returned error.
+func deleteFinalizerFindings(ctx context.Context, findings []Finding, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, opts common.Opts, review reviewResult, backup *backupArchive, audit *auditLog) ([]Finding, error) {
+	if opts.DryRun == DryRunClient {
+		return markDryRun(findings, opts), nil
 	}
-	return nil, fmt.Errorf("resource type '%s' is not supported", resourceType)
-}
 
-func getResource(clientset kubernetes.Interface, namespace, resourceType, resourceName string) (interface{}, error) {
-	switch resourceType {
-	case "ConfigMap":
-		return clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "Secret":
-		return clientset.CoreV1().Secrets(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "Service":
-		return clientset.CoreV1().Services(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "Deployment":
-		return clientset.AppsV1().Deployments(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "HPA":
-		return clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "Ingress":
-		return clientset.NetworkingV1().Ingresses(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "PDB":
-		return clientset.PolicyV1beta1().PodDisruptionBudgets(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "Role":
-		return clientset.RbacV1().Roles(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "ClusterRole":
-		return clientset.RbacV1().ClusterRoles().Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "ClusterRoleBinding":
-		return clientset.RbacV1().ClusterRoleBindings().Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "PVC":
-		return clientset.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "StatefulSet":
-		return clientset.AppsV1().StatefulSets(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "ServiceAccount":
-		return clientset.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "PV":
-		return clientset.CoreV1().PersistentVolumes().Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "Pod":
-		return clientset.CoreV1().Pods(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "Job":
-		return clientset.BatchV1().Jobs(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "ReplicaSet":
-		return clientset.AppsV1().ReplicaSets(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "DaemonSet":
-		return clientset.AppsV1().DaemonSets(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "StorageClass":
-		return clientset.StorageV1().StorageClasses().Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "NetworkPolicy":
-		return clientset.NetworkingV1().NetworkPolicies(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "RoleBinding":
-		return clientset.RbacV1().RoleBindings(namespace).Get(context.TODO(), resourceName, metav1.GetOptions{})
-	case "volumeAttachment":
-		return clientset.StorageV1().VolumeAttachments().Get(context.TODO(), resourceName, metav1.GetOptions{})
+	var remainingFindings []Finding
+	var errs []error
+	get := func(finding Finding) func() (runtime.Object, error) {
+		return func() (runtime.Object, error) {
+			return dynamicClient.Resource(gvr).Namespace(finding.Namespace).Get(ctx, finding.Name, metav1.GetOptions{})
+		}
 	}
-	return nil, fmt.Errorf("resource type '%s' is not supported", resourceType)
-}
-
-func DeleteResourceWithFinalizer(resources []ResourceInfo, dynamicClient dynamic.Interface, namespace string, gvr schema.GroupVersionResource, noInteractive bool) ([]ResourceInfo, error) {
-	var remainingResources []ResourceInfo
-	for _, resource := range resources {
-		if !noInteractive {
-			fmt.Printf("Do you want to delete %s %s in namespace %s? (Y/N): ", gvr.Resource, resource.Name, namespace)
-			var confirmation string
-			_, err := fmt.Scanf("%s", &confirmation)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
-				continue
-			}
-
-			if strings.ToLower(confirmation) != "y" && strings.ToLower(confirmation) != "yes" {
-				resource.Reason = "not deleted - user declined"
-				remainingResources = append(remainingResources, resource)
-
-				fmt.Printf("Do you want to flag the resource %s %s in namespace %s as In Use? (Y/N): ", gvr.Resource, resource.Name, namespace)
-				var inUse string
-				_, err = fmt.Scanf("%s", &inUse)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
-					continue
-				}
+	record := func(action, result string, finding Finding, obj runtime.Object, err error) {
+		if err := audit.record(ctx, newAuditEntry(action, result, finding, err), obj); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i, finding := range findings {
+		if ctx.Err() != nil {
+			remainingFindings = append(remainingFindings, findings[i:]...)
+			break
+		}
+		switch review.action(finding) {
+		case reviewSkip:
+			finding.Reason = "not deleted - user declined"
+			remainingFindings = append(remainingFindings, finding)
+			continue
+		case reviewFlag:
+			finding.Reason = "flagged as in use"
+			remainingFindings = append(remainingFindings, finding)
+			continue
+		}
 
-				if strings.ToLower(inUse) == "y" || strings.ToLower(inUse) == "yes" {
-					if err := FlagDynamicResource(dynamicClient, namespace, gvr, resource.Name); err != nil {
-						fmt.Fprintf(os.Stderr, "Failed to flag resource %s %s in namespace %s as In Use: %v\n", gvr.Resource, resource.Name, namespace, err)
-					} else {
-						resource.Reason = "flagged as in use"
-					}
-				}
-				continue
-			}
+		obj, err := fetchForDeletion(backup, audit, get(finding))
+		if err != nil {
+			err = fmt.Errorf("failed to back up %s %s in namespace %s, not deleting it: %w", gvr.Resource, finding.Name, finding.Namespace, err)
+			errs = append(errs, err)
+			record(AuditRemoveFinalizers, AuditFailed, finding, obj, err)
+			remainingFindings = append(remainingFindings, finding)
+			continue
 		}
 
-		fmt.Printf("Deleting %s %s in namespace %s\n", gvr.Resource, resource.Name, namespace)
+		if opts.DryRun == "" {
+			fmt.Printf("Deleting %s %s in namespace %s\n", gvr.Resource, finding.Name, finding.Namespace)
+		}
 		if _, err := dynamicClient.
 			Resource(gvr).
-			Namespace(namespace).
-			Patch(context.TODO(), resource.Name, types.MergePatchType,
+			Namespace(finding.Namespace).
+			Patch(ctx, finding.Name, types.MergePatchType,
 				[]byte(`{"metadata":{"finalizers":null}}`),
-				metav1.PatchOptions{}); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete %s %s in namespace %s: %v\n", gvr.Resource, resource.Name, namespace, err)
+				metav1.PatchOptions{DryRun: dryRunOption(opts.DryRun)}); err != nil {
+			errs = append(errs, deleteError(opts.DryRun, gvr.Resource, finding, err))
+			record(AuditRemoveFinalizers, AuditFailed, finding, obj, err)
+			remainingFindings = append(remainingFindings, finding)
 			continue
 		}
-		resource.Name = resource.Name + "-DELETED"
-		remainingResources = append(remainingResources, resource)
+		record(AuditRemoveFinalizers, AuditSucceeded, finding, obj, nil)
+		finding.Deleted = true
+		finding.DryRun = opts.DryRun != ""
+		remainingFindings = append(remainingFindings, finding)
 	}
 
-	return remainingResources, nil
+	return remainingFindings, errors.Join(errs...)
 }
 
-func DeleteResource(diff []ResourceInfo, clientset kubernetes.Interface, namespace, resourceType string, noInteractive bool) ([]ResourceInfo, error) {
+// DeleteResource deletes unused resources of a type, following
+// opts.NoInteractive, opts.DryRun, opts.Cascade, opts.BackupDir and the audit options
+func DeleteResource(ctx context.Context, diff []ResourceInfo, clients Clients, namespace, resourceType string, opts common.Opts) ([]ResourceInfo, error) {
+	detector, ok := LookupDetector(resourceType)
+	if !ok {
+		return diff, fmt.Errorf("resource type '%s' is not supported", resourceType)
+	}
+	if err := ValidateDryRun(opts.DryRun); err != nil {
+		return diff, err
+	}
+	if err := ValidateCascade(opts.Cascade); err != nil {
+		return diff, err
+	}
+
+	audit, err := newAuditLog(opts, clients.Clientset)
+	if err != nil {
+		return diff, err
+	}
+
 	deletedDiff := []ResourceInfo{}
+	findings := newFindings(detector.Name(), namespace, diff)
+	cascade, cascadeErr := deletionCascade(ctx, clients, findings, opts)
+	review, reviewErr := reviewDeletion(ctx, findings, cascade, opts, AuditDelete,
+		func(finding Finding) error {
+			return detector.Flag(ctx, clients, finding.Namespace, finding.Name)
+		},
+		func(finding Finding) (runtime.Object, error) {
+			return getObject(ctx, detector, clients, finding.Namespace, finding.Name)
+		}, audit)
+	backup := newBackupArchive(opts)
+	findings, err = deleteFindings(ctx, findings, clients, detector, opts, review, backup, audit)
+	for _, finding := range findings {
+		deletedDiff = append(deletedDiff, ResourceInfo{Name: finding.displayName(), Reason: finding.Reason})
+	}
+	return deletedDiff, errors.Join(cascadeErr, reviewErr, err, backup.Close(), audit.Close())
+}
 
-	for _, resource := range diff {
-		deleteFunc, exists := DeleteResourceCmd()[resourceType]
-		if !exists {
-			fmt.Printf("Resource type '%s' is not supported\n", resource.Name)
-			continue
-		}
+// deleteFindings deletes the unused objects found by a detector that review
+// selected for deletion, leaving the ones review skipped or flagged as in use
+// with that as their reason. The other objects that were not deleted are
+// returned unchanged, including the ones left when ctx is cancelled. Each
+// object is saved to backup first, when set, and kept when
+// that fails. Every action is recorded to audit. The failures are joined in
+// the returned error.
+func deleteFindings(ctx context.Context, findings []Finding, clients Clients, detector Detector, opts common.Opts, review reviewResult, backup *backupArchive, audit *auditLog) ([]Finding, error) {
+	if opts.DryRun == DryRunClient {
+		return markDryRun(findings, opts), nil
+	}
 
-		if !noInteractive {
-			fmt.Printf("Do you want to delete %s %s in namespace %s? (Y/N): ", resourceType, resource.Name, namespace)
-			var confirmation string
-			_, err := fmt.Scanf("%s\n", &confirmation)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
-				continue
-			}
+	resourceType := detector.Name()
+	deleteOptions := metav1.DeleteOptions{DryRun: dryRunOption(opts.DryRun), PropagationPolicy: propagationPolicy(opts.Cascade)}
+	var errs []error
+	get := func(finding Finding) func() (runtime.Object, error) {
+		return func() (runtime.Object, error) {
+			return getObject(ctx, detector, clients, finding.Namespace, finding.Name)
+		}
+	}
+	record := func(action, result string, finding Finding, obj runtime.Object, err error) {
+		if err := audit.record(ctx, newAuditEntry(action, result, finding, err), obj); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
-			if strings.ToLower(confirmation) != "y" && strings.ToLower(confirmation) != "yes" {
-				deletedDiff = append(deletedDiff, resource)
+	for i, finding := range findings {
+		if ctx.Err() != nil {
+			break
+		}
+		if !deletable(finding, opts) {
+			continue
+		}
+		switch review.action(finding) {
+		case reviewSkip:
+			findings[i].Reason = "not deleted - user declined"
+			continue
+		case reviewFlag:
+			findings[i].Reason = "flagged as in use"
+			continue
+		}
 
-				fmt.Printf("Do you want flag the resource %s %s in namespace %s as In Use? (Y/N): ", resourceType, resource.Name, namespace)
-				var inUse string
-				_, err := fmt.Scanf("%s\n", &inUse)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
-					continue
-				}
+		obj, err := fetchForDeletion(backup, audit, get(finding))
+		if err != nil {
+			err = fmt.Errorf("failed to back up %s %s in namespace %s, not deleting it: %w", resourceType, finding.Name, finding.Namespace, err)
+			errs = append(errs, err)
+			record(AuditDelete, AuditFailed, finding, obj, err)
+			continue
+		}
 
-				if strings.ToLower(inUse) == "y" || strings.ToLower(inUse) == "yes" {
-					if err := FlagResource(clientset, namespace, resourceType, resource.Name); err != nil {
-						fmt.Fprintf(os.Stderr, "Failed to flag resource %s %s in namespace %s as In Use: %v\n", resourceType, resource.Name, namespace, err)
-					}
-					continue
-				}
-				continue
-			}
+		if opts.DryRun == "" {
+			fmt.Printf("Deleting %s %s in namespace %s\n", resourceType, finding.Name, finding.Namespace)
+		}
+		if err := detector.Delete(ctx, clients, finding.Namespace, finding.Name, deleteOptions); err != nil {
+			errs = append(errs, deleteError(opts.DryRun, resourceType, finding, err))
+			record(AuditDelete, AuditFailed, finding, obj, err)
+			continue
 		}
+		record(AuditDelete, AuditSucceeded, finding, obj, nil)
+		findings[i].Deleted = true
+		findings[i].DryRun = opts.DryRun != ""
+	}
+
+	return findings, errors.Join(errs...)
+}
 
-		fmt.Printf("Deleting %s %s in namespace %s\n", resourceType, resource.Name, namespace)
-		if err := deleteFunc(clientset, namespace, resource.Name); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete %s %s in namespace %s: %v\n", resourceType, resource.Name, namespace, err)
+// markDryRun reports every finding as deleted by a client dry run, without
+// calling the API server
+func markDryRun(findings []Finding, opts common.Opts) []Finding {
+	for i := range findings {
+		if !deletable(findings[i], opts) {
 			continue
 		}
-		deletedResource := resource
-		deletedResource.Name += "-DELETED"
-		deletedDiff = append(deletedDiff, deletedResource)
+		findings[i].Deleted = true
+		findings[i].DryRun = true
 	}
+	return findings
+}
 
-	return deletedDiff, nil
+func deleteError(dryRun, resourceType string, finding Finding, err error) error {
+	if dryRun != "" {
+		return fmt.Errorf("dry run: delete of %s %s in namespace %s would fail: %w", resourceType, finding.Name, finding.Namespace, err)
+	}
+	return fmt.Errorf("failed to delete %s %s in namespace %s: %w", resourceType, finding.Name, finding.Namespace, err)
 }
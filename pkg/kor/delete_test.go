@@ -2,14 +2,22 @@ package kor
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	fakedynamic "k8s.io/client-go/dynamic/fake"
 	fake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
 )
 
 func TestDeleteResource(t *testing.T) {
@@ -50,7 +58,7 @@ func TestDeleteResource(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			deletedDiff, _ := DeleteResource(test.diff, clientset, testNamespace, test.resourceType, true)
+			deletedDiff, _ := DeleteResource(context.TODO(), test.diff, Clients{Clientset: clientset}, testNamespace, test.resourceType, common.Opts{NoInteractive: true})
 			for i, deleted := range deletedDiff {
 				if deleted != test.expectedDiff[i] {
 					t.Errorf("Expected: %s, Got: %s", test.expectedDiff[i], deleted)
@@ -102,7 +110,7 @@ func TestDeleteDeleteResourceWithFinalizer(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			deletedDiff, _ := DeleteResourceWithFinalizer(test.diff, dynamicClient, testNamespace, gvr, true)
+			deletedDiff, _ := DeleteResourceWithFinalizer(context.TODO(), test.diff, dynamicClient, testNamespace, gvr, common.Opts{NoInteractive: true})
 
 			for i, deleted := range deletedDiff {
 				if deleted.Name != test.expectedDiff[i] {
@@ -169,7 +177,7 @@ func TestFlagDynamicResource(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := FlagDynamicResource(dynamicClient, testNamespace, gvr, test.resourceName)
+			err := FlagDynamicResource(context.TODO(), dynamicClient, testNamespace, gvr, test.resourceName)
 
 			if (err != nil) != test.expectedError {
 				t.Errorf("Expected error: %v, Got: %v", test.expectedError, err)
@@ -189,3 +197,79 @@ func TestFlagDynamicResource(t *testing.T) {
 		})
 	}
 }
+
+func TestGetUnusedReportClientDryRun(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, "configmap-1", AppLabels), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake configmap: %v", err)
+	}
+	clientset.ClearActions()
+
+	opts := common.Opts{GroupBy: "namespace", DeleteFlag: true, DryRun: DryRunClient}
+	report, err := GetUnusedReport(context.TODO(), []Detector{configMapDetector}, &filters.Options{}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(report.Findings) != 1 || !report.Findings[0].Deleted || !report.Findings[0].DryRun {
+		t.Errorf("Expected configmap-1 to be reported as deleted by a dry run, got %v", report.Findings)
+	}
+	if name := report.Grouped("namespace")[testNamespace]["ConfigMap"][0].Name; name != "configmap-1-DELETED (dry run)" {
+		t.Errorf("Expected the dry run to be shown in the output, got %s", name)
+	}
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "delete" {
+			t.Errorf("Expected no delete request in a client dry run, got %v", action)
+		}
+	}
+}
+
+func TestGetUnusedReportServerDryRun(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	for _, name := range []string{"configmap-1", "configmap-2"} {
+		_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, name, AppLabels), metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+
+	// the fake clientset doesn't implement dry runs, the API server is simulated
+	clientset.PrependReactor("delete", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deleteAction := action.(k8stesting.DeleteAction)
+		if !reflect.DeepEqual(deleteAction.GetDeleteOptions().DryRun, []string{metav1.DryRunAll}) {
+			t.Errorf("Expected a dry run delete, got %v", deleteAction.GetDeleteOptions())
+		}
+		if deleteAction.GetName() == "configmap-2" {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "configmap-2", errors.New("denied by admission webhook"))
+		}
+		return true, nil, nil
+	})
+
+	opts := common.Opts{DeleteFlag: true, DryRun: DryRunServer}
+	report, err := GetUnusedReport(context.TODO(), []Detector{configMapDetector}, &filters.Options{}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedFindings := []Finding{
+		{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-1", Reason: "ConfigMap is not used in any pod or container", Deleted: true, DryRun: true},
+		{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-2", Reason: "ConfigMap is not used in any pod or container"},
+	}
+	if !reflect.DeepEqual(report.Findings, expectedFindings) {
+		t.Errorf("Expected %v, got %v", expectedFindings, report.Findings)
+	}
+	if len(report.Errors) != 1 || !strings.Contains(report.Errors[0].Message, "configmap-2 in namespace test-namespace would fail") || !strings.Contains(report.Errors[0].Message, "denied by admission webhook") {
+		t.Errorf("Expected the failing delete to be reported with its cause, got %v", report.Errors)
+	}
+}
@@ -1,13 +1,10 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
-	"fmt"
-	"os"
 
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
@@ -18,8 +15,8 @@ import (
 //go:embed exceptions/deployments/deployments.json
 var deploymentsConfig []byte
 
-func processNamespaceDeployments(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	deploymentsList, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespaceDeployments(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	deploymentsList, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -61,48 +58,19 @@ func processNamespaceDeployments(clientset kubernetes.Interface, namespace strin
 			deploymentsWithoutReplicas = append(deploymentsWithoutReplicas, ResourceInfo{Name: deployment.Name, Reason: reason})
 		}
 	}
-	if opts.DeleteFlag {
-		if deploymentsWithoutReplicas, err = DeleteResource(deploymentsWithoutReplicas, clientset, namespace, "Deployment", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete Deployment %s in namespace %s: %v\n", deploymentsWithoutReplicas, namespace, err)
-		}
-	}
 
 	return deploymentsWithoutReplicas, nil
 }
 
-func GetUnusedDeployments(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceDeployments(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["Deployment"] = diff
-		case "resource":
-			appendResources(resources, "Deployment", namespace, diff)
-		}
-	}
+var deploymentDetector = newDetector("Deployment", []string{"deployment", "deploy", "deployments"}, NamespaceScoped, namespacedDetect(processNamespaceDeployments),
+	func(clients Clients, namespace string) typedClient[*appsv1.Deployment, *appsv1.DeploymentList] {
+		return clients.Clientset.AppsV1().Deployments(namespace)
+	})
 
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
-
-	unusedDeployments, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(deploymentDetector)
+}
 
-	return unusedDeployments, nil
+func GetUnusedDeployments(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{deploymentDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -58,7 +58,7 @@ func createTestDeployments(t *testing.T) *fake.Clientset {
 func TestProcessNamespaceDeployments(t *testing.T) {
 	clientset := createTestDeployments(t)
 
-	deploymentsWithoutReplicas, err := processNamespaceDeployments(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	deploymentsWithoutReplicas, err := processNamespaceDeployments(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -84,7 +84,7 @@ func TestGetUnusedDeploymentsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedDeployments(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedDeployments(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedDeploymentsStructured: %v", err)
 	}
@@ -145,7 +145,7 @@ func TestFilterOwnerReferencedDeployments(t *testing.T) {
 
 	// Test without filter - should return both
 	filterOptsNoSkip := &filters.Options{IgnoreOwnerReferences: false}
-	unusedWithoutFilter, err := processNamespaceDeployments(clientset, testNamespace, filterOptsNoSkip, common.Opts{})
+	unusedWithoutFilter, err := processNamespaceDeployments(context.TODO(), clientset, testNamespace, filterOptsNoSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused deployments: %v", err)
 	}
@@ -156,7 +156,7 @@ func TestFilterOwnerReferencedDeployments(t *testing.T) {
 
 	// Test with filter - should return only standalone
 	filterOptsWithSkip := &filters.Options{IgnoreOwnerReferences: true}
-	unusedWithFilter, err := processNamespaceDeployments(clientset, testNamespace, filterOptsWithSkip, common.Opts{})
+	unusedWithFilter, err := processNamespaceDeployments(context.TODO(), clientset, testNamespace, filterOptsWithSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused deployments: %v", err)
 	}
@@ -0,0 +1,410 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// Scope tells whether a Detector looks at namespaced or cluster-scoped objects
+type Scope int
+
+const (
+	NamespaceScoped Scope = iota
+	ClusterScoped
+)
+
+// Errors returned by the detectors when Clients lacks a client they use
+var (
+	errClientsetNotConfigured           = errors.New("kubernetes clientset not configured")
+	errAPIExtensionsClientNotConfigured = errors.New("apiextensions client not configured")
+	errDynamicClientNotConfigured       = errors.New("dynamic client not configured")
+)
+
+func requireClientset(clients Clients) error {
+	if clients.Clientset == nil {
+		return errClientsetNotConfigured
+	}
+	return nil
+}
+
+func requireAPIExtensions(clients Clients) error {
+	if clients.APIExtensions == nil {
+		return errAPIExtensionsClientNotConfigured
+	}
+	return nil
+}
+
+func requireDynamic(clients Clients) error {
+	if clients.Dynamic == nil {
+		return errDynamicClientNotConfigured
+	}
+	return nil
+}
+
+func requireClientsetAndDynamic(clients Clients) error {
+	if err := requireClientset(clients); err != nil {
+		return err
+	}
+	return requireDynamic(clients)
+}
+
+// Clients bundles the API clients a Detector may use
+type Clients struct {
+	Clientset     kubernetes.Interface
+	APIExtensions apiextensionsclientset.Interface
+	Dynamic       dynamic.Interface
+}
+
+// Detector finds unused objects of a single resource kind and acts on them.
+// Every kind kor knows about is a Detector in the registry; the CLI commands,
+// the multi and all scans, deletion and the exporter are all driven by it.
+type Detector interface {
+	// Name is the kind reported in the results, e.g. "ConfigMap"
+	Name() string
+	// Aliases are the names the kind can be requested by, the first one being the command name
+	Aliases() []string
+	// Scope tells whether Detect is called once per namespace or once for the cluster
+	Scope() Scope
+	// Detect returns the unused objects in namespace, which is empty for cluster-scoped detectors
+	Detect(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error)
+	// Delete deletes a single object, honoring options such as DryRun
+	Delete(ctx context.Context, clients Clients, namespace, name string, options metav1.DeleteOptions) error
+	// Flag marks a single object as in use with the kor/used=true label
+	Flag(ctx context.Context, clients Clients, namespace, name string) error
+}
+
+// Getter is implemented by the detectors able to fetch a single object. It is
+// needed to back up objects to --backup-dir before deleting them, and by kor
+// plan, explain and the cascade preview.
+type Getter interface {
+	// Get returns a single object
+	Get(ctx context.Context, clients Clients, namespace, name string) (runtime.Object, error)
+}
+
+// Lister is implemented by the detectors able to list their objects, which
+// kor reap, the dangling reference checks and the namespace detector need
+type Lister interface {
+	// List returns the objects in namespace matching a label selector, such as the ones kor quarantined
+	List(ctx context.Context, clients Clients, namespace, labelSelector string) ([]metav1.Object, error)
+	// Resource is the API resource of the listed objects
+	Resource() schema.GroupResource
+}
+
+// Patcher is implemented by the detectors able to patch an object, which kor
+// quarantine needs to set and remove its label
+type Patcher interface {
+	// Patch applies a JSON merge patch to a single object
+	Patch(ctx context.Context, clients Clients, namespace, name string, patch []byte) error
+}
+
+// getObject fetches a single object through d, when it implements Getter
+func getObject(ctx context.Context, d Detector, clients Clients, namespace, name string) (runtime.Object, error) {
+	getter, ok := d.(Getter)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s detector can't get objects", errors.ErrUnsupported, d.Name())
+	}
+	return getter.Get(ctx, clients, namespace, name)
+}
+
+// listObjects lists the objects of d, when it implements Lister
+func listObjects(ctx context.Context, d Detector, clients Clients, namespace, labelSelector string) ([]metav1.Object, error) {
+	lister, ok := d.(Lister)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s detector can't list objects", errors.ErrUnsupported, d.Name())
+	}
+	return lister.List(ctx, clients, namespace, labelSelector)
+}
+
+// patchObject patches a single object through d, when it implements Patcher
+func patchObject(ctx context.Context, d Detector, clients Clients, namespace, name string, patch []byte) error {
+	patcher, ok := d.(Patcher)
+	if !ok {
+		return fmt.Errorf("%w: %s detector can't patch objects", errors.ErrUnsupported, d.Name())
+	}
+	return patcher.Patch(ctx, clients, namespace, name, patch)
+}
+
+var (
+	registryMu      sync.RWMutex
+	detectors       []Detector
+	detectorAliases = make(map[string]Detector)
+)
+
+// RegisterDetector adds a Detector to the registry. Names and aliases are
+// matched case-insensitively and must not collide with an existing detector.
+func RegisterDetector(d Detector) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	keys := make(map[string]bool)
+	for _, name := range append([]string{d.Name()}, d.Aliases()...) {
+		key := strings.ToLower(name)
+		if existing, ok := detectorAliases[key]; ok {
+			return fmt.Errorf("detector name %q of %s is already used by %s", name, d.Name(), existing.Name())
+		}
+		keys[key] = true
+	}
+	for key := range keys {
+		detectorAliases[key] = d
+	}
+	detectors = append(detectors, d)
+	return nil
+}
+
+// MustRegisterDetector is like RegisterDetector but panics on error
+func MustRegisterDetector(d Detector) {
+	if err := RegisterDetector(d); err != nil {
+		panic(err)
+	}
+}
+
+// Detectors returns the registered detectors in registration order
+func Detectors() []Detector {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Detector(nil), detectors...)
+}
+
+// DetectorsByScope returns the registered detectors of the given scope in registration order
+func DetectorsByScope(scope Scope) []Detector {
+	var scoped []Detector
+	for _, d := range Detectors() {
+		if d.Scope() == scope {
+			scoped = append(scoped, d)
+		}
+	}
+	return scoped
+}
+
+// LookupDetector finds a detector by its name, one of its aliases, or any
+// name the API server knows the resource by
+func LookupDetector(name string) (Detector, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if d, ok := detectorAliases[strings.ToLower(name)]; ok {
+		return d, true
+	}
+	d, ok := detectorAliases[getCanonicalResourceType(name)]
+	return d, ok
+}
+
+type detectFunc func(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error)
+
+// typedClient is the part of a typed client-go resource client needed to act on unused objects
+type typedClient[T, L runtime.Object] interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (T, error)
+	List(ctx context.Context, opts metav1.ListOptions) (L, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (T, error)
+}
+
+var usedLabelPatch = []byte(`{"metadata":{"labels":{"kor/used":"true"}}}`)
+
+// resourceDetector is the Detector implementation of the built-in kinds
+type resourceDetector struct {
+	name    string
+	aliases []string
+	scope   Scope
+	// resource is the API resource of the objects, empty when it is not known
+	resource schema.GroupResource
+	// requires returns an error when a client the detector uses is missing from Clients
+	requires func(clients Clients) error
+	detect   detectFunc
+	get      func(ctx context.Context, clients Clients, namespace, name string) (runtime.Object, error)
+	list     func(ctx context.Context, clients Clients, namespace, labelSelector string) ([]metav1.Object, error)
+	delete   func(ctx context.Context, clients Clients, namespace, name string, options metav1.DeleteOptions) error
+	patch    func(ctx context.Context, clients Clients, namespace, name string, patch []byte) error
+}
+
+// newDetector builds a Detector whose objects are deleted and flagged through
+// a typed client of the clientset
+func newDetector[T, L runtime.Object](name string, aliases []string, scope Scope, detect detectFunc, client func(clients Clients, namespace string) typedClient[T, L]) *resourceDetector {
+	return &resourceDetector{
+		name:     name,
+		aliases:  aliases,
+		scope:    scope,
+		resource: typedResource[T](),
+		requires: requireClientset,
+		detect:   detect,
+		get: func(ctx context.Context, clients Clients, namespace, name string) (runtime.Object, error) {
+			return client(clients, namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, clients Clients, namespace, labelSelector string) ([]metav1.Object, error) {
+			list, err := client(clients, namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+			if err != nil {
+				return nil, err
+			}
+			items, err := meta.ExtractList(list)
+			if err != nil {
+				return nil, err
+			}
+			objects := make([]metav1.Object, 0, len(items))
+			for _, item := range items {
+				object, err := meta.Accessor(item)
+				if err != nil {
+					return nil, err
+				}
+				objects = append(objects, object)
+			}
+			return objects, nil
+		},
+		delete: func(ctx context.Context, clients Clients, namespace, name string, options metav1.DeleteOptions) error {
+			return client(clients, namespace).Delete(ctx, name, options)
+		},
+		patch: func(ctx context.Context, clients Clients, namespace, name string, patch []byte) error {
+			_, err := client(clients, namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+			return err
+		},
+	}
+}
+
+// newDynamicDetector builds a Detector whose objects are deleted and flagged
+// through the dynamic client, for the kinds client-go has no typed client for
+func newDynamicDetector(name string, aliases []string, scope Scope, detect detectFunc, gvr schema.GroupVersionResource) *resourceDetector {
+	return &resourceDetector{
+		name:     name,
+		aliases:  aliases,
+		scope:    scope,
+		resource: gvr.GroupResource(),
+		requires: requireDynamic,
+		detect:   detect,
+		get: func(ctx context.Context, clients Clients, namespace, name string) (runtime.Object, error) {
+			return clients.Dynamic.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, clients Clients, namespace, labelSelector string) ([]metav1.Object, error) {
+			list, err := clients.Dynamic.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+			if err != nil {
+				return nil, err
+			}
+			objects := make([]metav1.Object, 0, len(list.Items))
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+			return objects, nil
+		},
+		delete: func(ctx context.Context, clients Clients, namespace, name string, options metav1.DeleteOptions) error {
+			return clients.Dynamic.Resource(gvr).Namespace(namespace).Delete(ctx, name, options)
+		},
+		patch: func(ctx context.Context, clients Clients, namespace, name string, patch []byte) error {
+			_, err := clients.Dynamic.Resource(gvr).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+			return err
+		},
+	}
+}
+
+// typedResource returns the API resource of the objects of type T as
+// registered in the client-go scheme, or an empty one for the types it
+// doesn't know
+func typedResource[T runtime.Object]() schema.GroupResource {
+	obj, ok := reflect.New(reflect.TypeFor[T]().Elem()).Interface().(runtime.Object)
+	if !ok {
+		return schema.GroupResource{}
+	}
+	gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+	if err != nil || len(gvks) == 0 {
+		return schema.GroupResource{}
+	}
+	plural, _ := meta.UnsafeGuessKindToResource(gvks[0])
+	return plural.GroupResource()
+}
+
+// namespacedDetect adapts a processNamespace* function to a detectFunc
+func namespacedDetect(process func(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error)) detectFunc {
+	return func(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+		return process(ctx, clients.Clientset, namespace, filterOpts, opts)
+	}
+}
+
+// clusterDetect adapts a cluster-scoped process* function to a detectFunc
+func clusterDetect(process func(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error)) detectFunc {
+	return func(ctx context.Context, clients Clients, _ string, filterOpts *filters.Options, _ common.Opts) ([]ResourceInfo, error) {
+		return process(ctx, clients.Clientset, filterOpts)
+	}
+}
+
+func (d *resourceDetector) Name() string {
+	return d.name
+}
+
+func (d *resourceDetector) Aliases() []string {
+	return d.aliases
+}
+
+func (d *resourceDetector) Scope() Scope {
+	return d.scope
+}
+
+func (d *resourceDetector) Resource() schema.GroupResource {
+	return d.resource
+}
+
+// withRequires replaces the clients check of d, for the detectors using
+// another client than the one they were built with
+func (d *resourceDetector) withRequires(requires func(clients Clients) error) *resourceDetector {
+	d.requires = requires
+	return d
+}
+
+func (d *resourceDetector) checkClients(clients Clients) error {
+	if d.requires == nil {
+		return nil
+	}
+	return d.requires(clients)
+}
+
+func (d *resourceDetector) Detect(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	if err := d.checkClients(clients); err != nil {
+		return nil, err
+	}
+	return d.detect(ctx, clients, namespace, filterOpts, opts)
+}
+
+func (d *resourceDetector) Get(ctx context.Context, clients Clients, namespace, name string) (runtime.Object, error) {
+	if err := d.checkClients(clients); err != nil {
+		return nil, err
+	}
+	return d.get(ctx, clients, namespace, name)
+}
+
+func (d *resourceDetector) Delete(ctx context.Context, clients Clients, namespace, name string, options metav1.DeleteOptions) error {
+	if err := d.checkClients(clients); err != nil {
+		return err
+	}
+	return d.delete(ctx, clients, namespace, name, options)
+}
+
+func (d *resourceDetector) Flag(ctx context.Context, clients Clients, namespace, name string) error {
+	return d.Patch(ctx, clients, namespace, name, usedLabelPatch)
+}
+
+func (d *resourceDetector) List(ctx context.Context, clients Clients, namespace, labelSelector string) ([]metav1.Object, error) {
+	if err := d.checkClients(clients); err != nil {
+		return nil, err
+	}
+	return d.list(ctx, clients, namespace, labelSelector)
+}
+
+func (d *resourceDetector) Patch(ctx context.Context, clients Clients, namespace, name string, patch []byte) error {
+	if err := d.checkClients(clients); err != nil {
+		return err
+	}
+	return d.patch(ctx, clients, namespace, name, patch)
+}
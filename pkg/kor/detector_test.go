@@ -0,0 +1,185 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestLookupDetector(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "cm", expected: "ConfigMap"},
+		{name: "ConfigMaps", expected: "ConfigMap"},
+		{name: "HPA", expected: "Hpa"},
+		{name: "persistentvolumeclaim", expected: "Pvc"},
+		{name: "crd", expected: "Crd"},
+		{name: "ns", expected: "Namespace"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			detector, ok := LookupDetector(test.name)
+			if !ok {
+				t.Fatalf("Expected a detector for %q", test.name)
+			}
+			if detector.Name() != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, detector.Name())
+			}
+		})
+	}
+
+	if _, ok := LookupDetector("unknown"); ok {
+		t.Errorf("Expected no detector for an unknown resource type")
+	}
+}
+
+func TestRegisterDetectorDuplicateAlias(t *testing.T) {
+	detector := &resourceDetector{name: "OtherConfigMap", aliases: []string{"cm"}, scope: NamespaceScoped}
+	if err := RegisterDetector(detector); err == nil {
+		t.Fatalf("Expected an error registering a detector with a taken alias")
+	}
+
+	if _, ok := LookupDetector("OtherConfigMap"); ok {
+		t.Errorf("Expected a rejected detector not to be registered")
+	}
+}
+
+func TestDetectorsByScope(t *testing.T) {
+	for _, detector := range DetectorsByScope(ClusterScoped) {
+		if detector.Scope() != ClusterScoped {
+			t.Errorf("Expected only cluster-scoped detectors, got %s", detector.Name())
+		}
+	}
+
+}
+
+func TestDetectorFlag(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	priorityClass := CreateTestPriorityClass("test-priority-class", 1000)
+	priorityClass.Labels = map[string]string{"test": "true"}
+	_, err := clientset.SchedulingV1().PriorityClasses().Create(context.TODO(), priorityClass, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake priorityclass: %v", err)
+	}
+
+	if err := FlagResource(context.TODO(), Clients{Clientset: clientset}, "", "PriorityClass", priorityClass.Name); err != nil {
+		t.Fatalf("Expected no error flagging priorityclass, got %v", err)
+	}
+
+	flagged, err := clientset.SchedulingV1().PriorityClasses().Get(context.TODO(), priorityClass.Name, v1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting priorityclass: %v", err)
+	}
+	if flagged.Labels["kor/used"] != "true" {
+		t.Errorf("Expected priorityclass flagged as used, got labels %v", flagged.Labels)
+	}
+	if flagged.Labels["test"] != "true" {
+		t.Errorf("Expected existing labels to be kept, got %v", flagged.Labels)
+	}
+}
+
+func TestDetectorDeleteCrd(t *testing.T) {
+	crds := []*apiextensionsv1.CustomResourceDefinition{
+		{ObjectMeta: v1.ObjectMeta{Name: "tests.example.com"}},
+		{ObjectMeta: v1.ObjectMeta{Name: "others.example.com"}},
+	}
+	apiExtClient := apiextensionsfake.NewClientset(crds[0], crds[1])
+	clients := Clients{APIExtensions: apiExtClient}
+
+	deletedDiff, err := DeleteResource(context.TODO(), []ResourceInfo{{Name: crds[0].Name}}, clients, "", "crd", common.Opts{NoInteractive: true})
+	if err != nil {
+		t.Fatalf("Expected no error deleting crd, got %v", err)
+	}
+	if len(deletedDiff) != 1 || deletedDiff[0].Name != crds[0].Name+"-DELETED" {
+		t.Errorf("Expected crd to be deleted, got %v", deletedDiff)
+	}
+
+	if err := DeleteResourceCmd()["Crd"](context.TODO(), clients, "", crds[1].Name); err != nil {
+		t.Fatalf("Expected no error deleting crd, got %v", err)
+	}
+
+	list, err := apiExtClient.ApiextensionsV1().CustomResourceDefinitions().List(context.TODO(), v1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Error listing crds: %v", err)
+	}
+	if len(list.Items) != 0 {
+		t.Errorf("Expected no crds left, got %d", len(list.Items))
+	}
+}
+
+func TestDetectorMissingClients(t *testing.T) {
+	clients := Clients{Clientset: fake.NewClientset()}
+	tests := []struct {
+		resourceType string
+		expected     error
+	}{
+		{resourceType: "crd", expected: errAPIExtensionsClientNotConfigured},
+		{resourceType: "apiservice", expected: errDynamicClientNotConfigured},
+		{resourceType: "gateway", expected: errDynamicClientNotConfigured},
+		{resourceType: "httproute", expected: errDynamicClientNotConfigured},
+	}
+
+	for _, test := range tests {
+		t.Run(test.resourceType, func(t *testing.T) {
+			detector, _ := LookupDetector(test.resourceType)
+			if err := DeleteResourceCmd()[detector.Name()](context.TODO(), clients, testNamespace, "object"); !errors.Is(err, test.expected) {
+				t.Errorf("Expected %v deleting, got %v", test.expected, err)
+			}
+			if err := FlagResource(context.TODO(), clients, testNamespace, test.resourceType, "object"); !errors.Is(err, test.expected) {
+				t.Errorf("Expected %v flagging, got %v", test.expected, err)
+			}
+			if _, err := detector.Detect(context.TODO(), clients, testNamespace, &filters.Options{}, common.Opts{}); !errors.Is(err, test.expected) {
+				t.Errorf("Expected %v detecting, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+// minimalDetector implements Detector only, without the optional Getter,
+// Lister and Patcher
+type minimalDetector struct{}
+
+func (minimalDetector) Name() string      { return "Minimal" }
+func (minimalDetector) Aliases() []string { return []string{"minimal"} }
+func (minimalDetector) Scope() Scope      { return NamespaceScoped }
+func (minimalDetector) Detect(context.Context, Clients, string, *filters.Options, common.Opts) ([]ResourceInfo, error) {
+	return nil, nil
+}
+func (minimalDetector) Delete(context.Context, Clients, string, string, v1.DeleteOptions) error {
+	return nil
+}
+func (minimalDetector) Flag(context.Context, Clients, string, string) error { return nil }
+
+func TestMinimalDetector(t *testing.T) {
+	var detector Detector = minimalDetector{}
+
+	if _, err := getObject(context.TODO(), detector, Clients{}, testNamespace, "object"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Expected get to be unsupported, got %v", err)
+	}
+	if _, err := listObjects(context.TODO(), detector, Clients{}, testNamespace, ""); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Expected list to be unsupported, got %v", err)
+	}
+	if err := patchObject(context.TODO(), detector, Clients{}, testNamespace, "object", usedLabelPatch); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Expected patch to be unsupported, got %v", err)
+	}
+
+	findings, err := deleteFindings(context.TODO(), []Finding{{Kind: "Minimal", Namespace: testNamespace, Name: "object"}}, Clients{}, detector, common.Opts{NoInteractive: true}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error deleting without a backup, got %v", err)
+	}
+	if len(findings) != 1 || !findings[0].Deleted {
+		t.Errorf("Expected the object to be deleted, got %v", findings)
+	}
+}
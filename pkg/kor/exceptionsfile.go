@@ -0,0 +1,119 @@
+package kor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Exception is a resource never reported as unused, listed in the
+// --exceptions-file. With MatchRegex, Namespace and Name are regular
+// expressions, as in the built-in exceptions.
+type Exception struct {
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	MatchRegex bool   `json:"matchRegex,omitempty"`
+}
+
+// ExceptionsFile is the content of the --exceptions-file, YAML or JSON
+type ExceptionsFile struct {
+	Exceptions []Exception `json:"exceptions"`
+}
+
+// ReadExceptions loads an exceptions file, a missing file having no
+// exceptions. Kinds are resolved like the kor commands, e.g. "cm".
+func ReadExceptions(path string) (*ExceptionsFile, error) {
+	exceptions := &ExceptionsFile{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return exceptions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, exceptions); err != nil {
+		return nil, fmt.Errorf("invalid exceptions file %s: %w", path, err)
+	}
+	for i, exception := range exceptions.Exceptions {
+		detector, ok := LookupDetector(exception.Kind)
+		if !ok {
+			return nil, fmt.Errorf("invalid exceptions file %s: resource type %q is not supported", path, exception.Kind)
+		}
+		exceptions.Exceptions[i].Kind = detector.Name()
+		if !exception.MatchRegex {
+			continue
+		}
+		for _, pattern := range []string{exception.Namespace, exception.Name} {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("invalid exceptions file %s: %w", path, err)
+			}
+		}
+	}
+	return exceptions, nil
+}
+
+// AddException appends an exception to the exceptions file at path, creating
+// it when missing. An exception already listed is not added twice.
+func AddException(path string, exception Exception) error {
+	exceptions, err := ReadExceptions(path)
+	if err != nil {
+		return err
+	}
+	detector, ok := LookupDetector(exception.Kind)
+	if !ok {
+		return fmt.Errorf("resource type %q is not supported", exception.Kind)
+	}
+	exception.Kind = detector.Name()
+	for _, existing := range exceptions.Exceptions {
+		if existing == exception {
+			return nil
+		}
+	}
+
+	exceptions.Exceptions = append(exceptions.Exceptions, exception)
+	data, err := yaml.Marshal(exceptions)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// excepted tells whether a finding is listed in the exceptions file
+func (e *ExceptionsFile) excepted(finding Finding) bool {
+	if e == nil {
+		return false
+	}
+	for _, exception := range e.Exceptions {
+		if exception.Kind != finding.Kind {
+			continue
+		}
+		// the patterns were compiled when the file was read
+		match, _ := isResourceException(finding.Name, finding.Namespace, []ExceptionResource{{
+			Namespace:    exception.Namespace,
+			ResourceName: exception.Name,
+			MatchRegex:   exception.MatchRegex,
+		}})
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// filter drops the findings listed in the exceptions file
+func (e *ExceptionsFile) filter(findings []Finding) []Finding {
+	if e == nil {
+		return findings
+	}
+	kept := findings[:0]
+	for _, finding := range findings {
+		if !e.excepted(finding) {
+			kept = append(kept, finding)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,93 @@
+package kor
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestAddException(t *testing.T) {
+	path := t.TempDir() + "/exceptions.yaml"
+
+	exceptions, err := ReadExceptions(path)
+	if err != nil || len(exceptions.Exceptions) != 0 {
+		t.Fatalf("Expected no exceptions in a missing file, got %v, %v", exceptions, err)
+	}
+	for _, exception := range []Exception{
+		{Kind: "cm", Namespace: testNamespace, Name: "configmap-1"},
+		{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-1"},
+		{Kind: "secret", Namespace: ".*", Name: "legacy-.*", MatchRegex: true},
+	} {
+		if err := AddException(path, exception); err != nil {
+			t.Fatalf("Error adding exception %v: %v", exception, err)
+		}
+	}
+
+	exceptions, err = ReadExceptions(path)
+	if err != nil {
+		t.Fatalf("Error reading exceptions: %v", err)
+	}
+	expected := []Exception{
+		{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-1"},
+		{Kind: "Secret", Namespace: ".*", Name: "legacy-.*", MatchRegex: true},
+	}
+	if !reflect.DeepEqual(exceptions.Exceptions, expected) {
+		t.Errorf("Expected the exceptions %v, got %v", expected, exceptions.Exceptions)
+	}
+
+	if err := AddException(path, Exception{Kind: "unknown", Name: "x"}); err == nil {
+		t.Error("Expected an error for an unsupported kind")
+	}
+}
+
+func TestReadExceptionsInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"unsupported kind": "exceptions:\n- kind: unknown\n  name: x\n",
+		"invalid regex":    "exceptions:\n- kind: ConfigMap\n  name: \"(\"\n  matchRegex: true\n",
+		"invalid yaml":     "exceptions: [",
+	} {
+		path := t.TempDir() + "/exceptions.yaml"
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Error writing exceptions file: %v", err)
+		}
+		if _, err := ReadExceptions(path); err == nil {
+			t.Errorf("Expected an error for an exceptions file with an %s", name)
+		}
+	}
+}
+
+func TestGetUnusedReportExceptionsFile(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	for _, name := range []string{"configmap-1", "legacy-1", "legacy-2"} {
+		_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, name, AppLabels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+
+	path := t.TempDir() + "/exceptions.yaml"
+	if err := AddException(path, Exception{Kind: "ConfigMap", Namespace: testNamespace, Name: "legacy-.*", MatchRegex: true}); err != nil {
+		t.Fatalf("Error adding exception: %v", err)
+	}
+
+	opts := common.Opts{ExceptionsFile: path}
+	report, err := GetUnusedReport(context.TODO(), []Detector{configMapDetector}, &filters.Options{}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].Name != "configmap-1" {
+		t.Errorf("Expected only configmap-1 to be reported, got %+v", report.Findings)
+	}
+}
@@ -0,0 +1,284 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"text/tabwriter"
+
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// fieldIndexes matches the indexes of a field path, e.g. [0] in spec.volumes[0]
+var fieldIndexes = regexp.MustCompile(`\[\d+\]`)
+
+// usageRule is a place kor looks for references to a resource, matching the
+// kind of the objects using it and their field paths without indexes
+type usageRule struct {
+	description string
+	kind        string
+	field       *regexp.Regexp
+}
+
+func (r usageRule) matches(reference Reference) bool {
+	return reference.Kind == r.kind && r.field.MatchString(fieldIndexes.ReplaceAllString(reference.Field, ""))
+}
+
+func newUsageRule(description, kind, field string) usageRule {
+	return usageRule{description: description, kind: kind, field: regexp.MustCompile(field)}
+}
+
+// referenceExplainer finds the references to the resources of a kind that is
+// unused when nothing references it
+type referenceExplainer struct {
+	references func(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options) (references, error)
+	rules      []usageRule
+}
+
+func namespacedReferences(retrieve func(ctx context.Context, clientset kubernetes.Interface, namespace string) (references, error)) func(context.Context, Clients, string, *filters.Options) (references, error) {
+	return func(ctx context.Context, clients Clients, namespace string, _ *filters.Options) (references, error) {
+		return retrieve(ctx, clients.Clientset, namespace)
+	}
+}
+
+func clusterReferences(retrieve func(ctx context.Context, clientset kubernetes.Interface) (references, error)) func(context.Context, Clients, string, *filters.Options) (references, error) {
+	return func(ctx context.Context, clients Clients, _ string, _ *filters.Options) (references, error) {
+		return retrieve(ctx, clients.Clientset)
+	}
+}
+
+// referenceExplainers are keyed by detector name, the other kinds are
+// detected from their own state, e.g. the replicas of a Deployment
+var referenceExplainers = map[string]referenceExplainer{
+	"ConfigMap": {
+		references: namespacedReferences(retrieveUsedCM),
+		rules: []usageRule{
+			newUsageRule("Mounted as a volume or projected volume of a pod", "Pod", `^spec\.volumes\.(projected\.sources\.)?configMap$`),
+			newUsageRule("Referenced by a container env var", "Pod", `^spec\.containers\.env\.valueFrom\.configMapKeyRef$`),
+			newUsageRule("Referenced by a container envFrom", "Pod", `^spec\.containers\.envFrom\.configMapRef$`),
+			newUsageRule("Referenced by an init container env var or envFrom", "Pod", `^spec\.initContainers\.env(From)?\.`),
+			newUsageRule("Named like a volume mounted by an init container", "Pod", `^spec\.initContainers\.volumeMounts$`),
+		},
+	},
+	"Secret": {
+		references: func(ctx context.Context, clients Clients, namespace string, _ *filters.Options) (references, error) {
+			return retrieveUsedSecret(ctx, clients.Clientset, clients.Dynamic, namespace)
+		},
+		rules: []usageRule{
+			newUsageRule("Mounted as a volume or projected volume of a pod", "Pod", `^spec\.volumes\.(projected\.sources\.)?secret$`),
+			newUsageRule("Referenced by a container env var", "Pod", `^spec\.containers\.env\.valueFrom\.secretKeyRef$`),
+			newUsageRule("Referenced by a container envFrom", "Pod", `^spec\.containers\.envFrom\.secretRef$`),
+			newUsageRule("Referenced by an init container env var or envFrom", "Pod", `^spec\.initContainers\.env(From)?\.`),
+			newUsageRule("Listed in the imagePullSecrets of a pod", "Pod", `^spec\.imagePullSecrets$`),
+			newUsageRule("Referenced by the TLS of an Ingress", "Ingress", `^spec\.tls\.secretName$`),
+			newUsageRule("Referenced by the certificateRefs of a Gateway listener", "Gateway", `^spec\.listeners\.tls\.certificateRefs$`),
+		},
+	},
+	"ServiceAccount": {
+		references: namespacedReferences(retrieveUsedSA),
+		rules: []usageRule{
+			newUsageRule("Set as the serviceAccountName of a pod", "Pod", `^spec\.serviceAccountName$`),
+			newUsageRule("A subject of a RoleBinding", "RoleBinding", `^subjects$`),
+			newUsageRule("A subject of a ClusterRoleBinding", "ClusterRoleBinding", `^subjects$`),
+		},
+	},
+	"Pvc": {
+		references: namespacedReferences(retrieveUsedPvcs),
+		rules: []usageRule{
+			newUsageRule("Mounted as a volume of a pod", "Pod", `^spec\.volumes\.persistentVolumeClaim$`),
+			newUsageRule("Created for an ephemeral volume of a pod", "Pod", `^spec\.volumes\.ephemeral$`),
+		},
+	},
+	"Role": {
+		references: namespacedReferences(retrieveUsedRoles),
+		rules: []usageRule{
+			newUsageRule("Referenced by the roleRef of a RoleBinding", "RoleBinding", `^roleRef$`),
+		},
+	},
+	"ClusterRole": {
+		references: func(ctx context.Context, clients Clients, _ string, filterOpts *filters.Options) (references, error) {
+			return retrieveUsedClusterRoles(ctx, clients.Clientset, filterOpts)
+		},
+		rules: []usageRule{
+			newUsageRule("Referenced by the roleRef of a RoleBinding", "RoleBinding", `^roleRef$`),
+			newUsageRule("Referenced by the roleRef of a ClusterRoleBinding", "ClusterRoleBinding", `^roleRef$`),
+			newUsageRule("Aggregated into a ClusterRole in use", "ClusterRole", `^aggregationRule\.`),
+		},
+	},
+	"PriorityClass": {
+		references: clusterReferences(retrieveUsedPriorityClasses),
+		rules: []usageRule{
+			newUsageRule("Set as the priorityClassName of a pod", "Pod", `^spec\.priorityClassName$`),
+		},
+	},
+	"StorageClass": {
+		references: clusterReferences(retrieveUsedStorageClasses),
+		rules: []usageRule{
+			newUsageRule("Set as the storageClassName of a PersistentVolume", "PersistentVolume", `^spec\.storageClassName$`),
+			newUsageRule("Set as the storageClassName of a PersistentVolumeClaim", "PersistentVolumeClaim", `^spec\.storageClassName$`),
+		},
+	},
+}
+
+// RuleCheck is a rule kor checked to tell whether a resource is used, passed
+// when a reference matching it was found
+type RuleCheck struct {
+	Rule   string `json:"rule"`
+	Passed bool   `json:"passed"`
+}
+
+// Explanation tells why a resource is reported as unused or not
+type Explanation struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Unused    bool   `json:"unused"`
+	// Reason is the reason of the finding of an unused resource, or why a
+	// resource nothing references is not reported
+	Reason     string      `json:"reason,omitempty"`
+	References []Reference `json:"references,omitempty"`
+	Rules      []RuleCheck `json:"rules,omitempty"`
+}
+
+// Explain tells why a resource is reported as unused or not. The kinds kor
+// finds unused when nothing references them are explained by the references
+// found to the resource and the rules checked, the others by the reason of
+// their finding only.
+func Explain(ctx context.Context, clients Clients, kind, namespace, name string, filterOpts *filters.Options, opts common.Opts) (*Explanation, error) {
+	detector, ok := LookupDetector(kind)
+	if !ok {
+		return nil, fmt.Errorf("resource type %q is not supported", kind)
+	}
+	if detector.Scope() == ClusterScoped {
+		namespace = ""
+	}
+	obj, err := getObject(ctx, detector, clients, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	explanation := &Explanation{Kind: detector.Name(), Namespace: namespace, Name: name}
+
+	diff, err := detector.Detect(ctx, clients, namespace, filterOpts, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range diff {
+		if info.Name == name {
+			explanation.Unused = true
+			explanation.Reason = info.Reason
+		}
+	}
+	if explanation.Unused && opts.ExceptionsFile != "" {
+		exceptions, err := ReadExceptions(opts.ExceptionsFile)
+		if err != nil {
+			return nil, err
+		}
+		if exceptions.excepted(Finding{Kind: explanation.Kind, Namespace: namespace, Name: name}) {
+			explanation.Unused = false
+			explanation.Reason = "Listed in the exceptions file " + opts.ExceptionsFile
+		}
+	}
+
+	explainer, ok := referenceExplainers[detector.Name()]
+	if !ok {
+		return explanation, nil
+	}
+	used, err := explainer.references(ctx, clients, namespace, filterOpts)
+	if err != nil {
+		return nil, err
+	}
+	explanation.References = used[name]
+	for _, rule := range explainer.rules {
+		check := RuleCheck{Rule: rule.description}
+		for _, reference := range explanation.References {
+			if rule.matches(reference) {
+				check.Passed = true
+				break
+			}
+		}
+		explanation.Rules = append(explanation.Rules, check)
+	}
+
+	if !explanation.Unused && explanation.Reason == "" && len(explanation.References) == 0 {
+		switch {
+		case filters.KorLabelFilter(obj, filterOpts):
+			explanation.Reason = "Marked with used label"
+		case filters.LabelFilter(obj, filterOpts):
+			explanation.Reason = "Excluded by --exclude-labels"
+		case filters.AgeFilter(obj, filterOpts):
+			explanation.Reason = "Excluded by --older-than or --newer-than"
+		default:
+			explanation.Reason = "Not referenced, but skipped by the built-in exceptions of its kind"
+		}
+	}
+	return explanation, nil
+}
+
+// FormatExplanation formats an explanation as text, json or yaml
+func FormatExplanation(explanation *Explanation, outputFormat string) (string, error) {
+	switch outputFormat {
+	case "json", "yaml":
+		data, err := json.MarshalIndent(explanation, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		if outputFormat == "yaml" {
+			if data, err = yaml.JSONToYAML(data); err != nil {
+				return "", err
+			}
+		}
+		return string(data) + "\n", nil
+	}
+
+	var buf bytes.Buffer
+	name := explanation.Name
+	if explanation.Namespace != "" {
+		name = explanation.Namespace + "/" + explanation.Name
+	}
+	verdict := "is in use"
+	if explanation.Unused {
+		verdict = "is unused"
+	}
+	fmt.Fprintf(&buf, "%s %s %s", explanation.Kind, name, verdict)
+	if explanation.Reason != "" {
+		fmt.Fprintf(&buf, ": %s", explanation.Reason)
+	}
+	fmt.Fprintln(&buf)
+
+	if len(explanation.References) > 0 {
+		fmt.Fprintln(&buf, "\nReferenced by:")
+		w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		for _, reference := range explanation.References {
+			referenceName := reference.Name
+			if reference.Namespace != "" {
+				referenceName = reference.Namespace + "/" + reference.Name
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", reference.Kind, referenceName, reference.Field)
+		}
+		if err := w.Flush(); err != nil {
+			return "", err
+		}
+	}
+
+	if len(explanation.Rules) > 0 {
+		fmt.Fprintln(&buf, "\nRules checked:")
+		w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		for _, check := range explanation.Rules {
+			result := "failed"
+			if check.Passed {
+				result = "passed"
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", check.Rule, result)
+		}
+		if err := w.Flush(); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
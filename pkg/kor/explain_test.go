@@ -0,0 +1,132 @@
+package kor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestExplain(t *testing.T) {
+	clients := Clients{Clientset: createTestConfigmaps(t)}
+
+	used, err := Explain(context.TODO(), clients, "cm", testNamespace, "configmap-2", &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Error explaining configmap-2: %v", err)
+	}
+	if used.Kind != "ConfigMap" || used.Unused || used.Reason != "" {
+		t.Errorf("Expected configmap-2 to be a ConfigMap in use, got %+v", used)
+	}
+	expectedReferences := []Reference{
+		{Kind: "Pod", Namespace: testNamespace, Name: "pod-3", Field: "spec.containers[0].envFrom[0].configMapRef"},
+		{Kind: "Pod", Namespace: testNamespace, Name: "pod-4", Field: "spec.initContainers[0].env[0].valueFrom.configMapKeyRef"},
+	}
+	if !reflect.DeepEqual(used.References, expectedReferences) {
+		t.Errorf("Expected references %v, got %v", expectedReferences, used.References)
+	}
+	var passed []string
+	for _, check := range used.Rules {
+		if check.Passed {
+			passed = append(passed, check.Rule)
+		}
+	}
+	expectedPassed := []string{"Referenced by a container envFrom", "Referenced by an init container env var or envFrom"}
+	if !reflect.DeepEqual(passed, expectedPassed) {
+		t.Errorf("Expected the rules %v to pass, got %v", expectedPassed, passed)
+	}
+
+	unused, err := Explain(context.TODO(), clients, "configmap", testNamespace, "configmap-3", &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Error explaining configmap-3: %v", err)
+	}
+	if !unused.Unused || unused.Reason != "ConfigMap is not used in any pod or container" || len(unused.References) != 0 {
+		t.Errorf("Expected configmap-3 to be unused without references, got %+v", unused)
+	}
+	if len(unused.Rules) != len(referenceExplainers["ConfigMap"].rules) {
+		t.Errorf("Expected every ConfigMap rule to be checked, got %v", unused.Rules)
+	}
+	for _, check := range unused.Rules {
+		if check.Passed {
+			t.Errorf("Expected rule %q to fail for configmap-3", check.Rule)
+		}
+	}
+
+	flagged, err := Explain(context.TODO(), clients, "configmap", testNamespace, "configmap-4", &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Error explaining configmap-4: %v", err)
+	}
+	if flagged.Unused || flagged.Reason != "Marked with used label" {
+		t.Errorf("Expected configmap-4 to be marked with used label, got %+v", flagged)
+	}
+
+	if _, err := Explain(context.TODO(), clients, "configmap", testNamespace, "missing", &filters.Options{}, common.Opts{}); err == nil {
+		t.Error("Expected an error explaining a ConfigMap that doesn't exist")
+	}
+	if _, err := Explain(context.TODO(), clients, "unknown", testNamespace, "configmap-3", &filters.Options{}, common.Opts{}); err == nil {
+		t.Error("Expected an error explaining an unsupported resource type")
+	}
+}
+
+func TestExplainStateDetectedKind(t *testing.T) {
+	clientset := createTestConfigmaps(t)
+	deployment := CreateTestDeployment(testNamespace, "test-deployment", 0, AppLabels)
+	if _, err := clientset.AppsV1().Deployments(testNamespace).Create(context.TODO(), deployment, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating fake deployment: %v", err)
+	}
+
+	explanation, err := Explain(context.TODO(), Clients{Clientset: clientset}, "deploy", testNamespace, "test-deployment", &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Error explaining test-deployment: %v", err)
+	}
+	if !explanation.Unused || explanation.Reason == "" {
+		t.Errorf("Expected test-deployment to be unused with a reason, got %+v", explanation)
+	}
+	if explanation.References != nil || explanation.Rules != nil {
+		t.Errorf("Expected no references nor rules for a Deployment, got %+v", explanation)
+	}
+}
+
+func TestFormatExplanation(t *testing.T) {
+	explanation := &Explanation{
+		Kind:      "ConfigMap",
+		Namespace: testNamespace,
+		Name:      "configmap-2",
+		References: []Reference{
+			{Kind: "Pod", Namespace: testNamespace, Name: "pod-3", Field: "spec.containers[0].envFrom[0].configMapRef"},
+		},
+		Rules: []RuleCheck{
+			{Rule: "Mounted as a volume or projected volume of a pod"},
+			{Rule: "Referenced by a container envFrom", Passed: true},
+		},
+	}
+
+	output, err := FormatExplanation(explanation, "table")
+	if err != nil {
+		t.Fatalf("Error formatting explanation: %v", err)
+	}
+	expected := `ConfigMap test-namespace/configmap-2 is in use
+
+Referenced by:
+  Pod  test-namespace/pod-3  spec.containers[0].envFrom[0].configMapRef
+
+Rules checked:
+  Mounted as a volume or projected volume of a pod  failed
+  Referenced by a container envFrom                 passed
+`
+	if output != expected {
+		t.Errorf("Expected output:\n%s\ngot:\n%s", expected, output)
+	}
+
+	output, err = FormatExplanation(explanation, "json")
+	if err != nil {
+		t.Fatalf("Error formatting explanation: %v", err)
+	}
+	if !strings.Contains(output, `"field": "spec.containers[0].envFrom[0].configMapRef"`) {
+		t.Errorf("Expected the field of the reference in the json output, got %s", output)
+	}
+}
@@ -1,7 +1,8 @@
 package kor
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -34,56 +35,69 @@ func init() {
 }
 
 // TODO: add option to change port / url !?
-func Exporter(filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, resourceList []string) {
-	http.Handle("/metrics", promhttp.Handler())
-	fmt.Println("Server listening on :8080")
-	go exportMetrics(filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts, resourceList) // Start exporting metrics in the background
-	if err := http.ListenAndServe(":8080", nil); err != nil {
-		fmt.Println(err)
-	}
-}
-
-func exportMetrics(filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, resourceList []string) {
+func Exporter(ctx context.Context, filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, resourceList []string) error {
 	exporterInterval := os.Getenv("EXPORTER_INTERVAL")
 	if exporterInterval == "" {
 		exporterInterval = "10"
 	}
 	exporterIntervalValue, err := strconv.Atoi(exporterInterval)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		return fmt.Errorf("invalid EXPORTER_INTERVAL: %w", err)
+	}
+	interval := time.Duration(exporterIntervalValue) * time.Minute
+
+	http.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{Addr: ":8080"}
+	go func() {
+		<-ctx.Done()
+		_ = server.Shutdown(context.Background())
+	}()
+
+	fmt.Println("Server listening on :8080")
+	go exportMetrics(ctx, filterOptions, clientset, apiExtClient, dynamicClient, opts, resourceList, interval) // Start exporting metrics in the background
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
+	return nil
+}
 
+func exportMetrics(ctx context.Context, filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, opts common.Opts, resourceList []string, interval time.Duration) {
+	clients := Clients{Clientset: clientset, APIExtensions: apiExtClient, Dynamic: dynamicClient}
 	for {
 		fmt.Println("collecting unused resources")
-		if korOutput, err := getUnusedResources(filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts, resourceList); err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+		report, err := getUnusedResources(ctx, filterOptions, clients, opts, resourceList)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// a failed or incomplete scan keeps the previous metrics rather than
+		// dropping the ones of the resources it missed
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to collect unused resources: %v\n", err)
 		} else {
-			var data map[string]map[string][]string
-			if err := json.Unmarshal([]byte(korOutput), &data); err != nil {
-				fmt.Println("Error parsing JSON:", err)
-				return
+			for _, scanError := range report.Errors {
+				fmt.Fprintf(os.Stderr, "Error collecting unused resources: %s\n", scanError)
 			}
-
+		}
+		if err == nil && !report.Incomplete {
 			orphanedResourcesCounter.Reset()
 
-			for namespace, resources := range data {
-				for kind, resourceList := range resources {
-					for _, resourceName := range resourceList {
-						orphanedResourcesCounter.WithLabelValues(kind, namespace, resourceName).Set(1)
-					}
-				}
+			for _, finding := range report.Findings {
+				orphanedResourcesCounter.WithLabelValues(finding.Kind, finding.Namespace, finding.Name).Set(1)
 			}
-			time.Sleep(time.Duration(exporterIntervalValue) * time.Minute)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
 		}
 	}
 }
 
-func getUnusedResources(filterOptions *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts, resourceList []string) (string, error) {
+func getUnusedResources(ctx context.Context, filterOptions *filters.Options, clients Clients, opts common.Opts, resourceList []string) (*Report, error) {
 	if len(resourceList) == 0 || (len(resourceList) == 1 && resourceList[0] == "all") {
-		return GetUnusedAll(filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts)
+		return GetUnusedAllReport(ctx, filterOptions, clients, opts)
 	}
-	return GetUnusedMulti(strings.Join(resourceList, ","), filterOptions, clientset, apiExtClient, dynamicClient, outputFormat, opts)
-
+	return GetUnusedMultiReport(ctx, strings.Join(resourceList, ","), filterOptions, clients, opts)
 }
@@ -1,14 +1,15 @@
 package kor
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"sort"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -25,8 +26,9 @@ func CheckFinalizers(finalizers []string, deletionTimestamp *metav1.Time) bool {
 	return false
 }
 
-func retrievePendingDeletionResources(resourceTypes []*metav1.APIResourceList, dynamicClient dynamic.Interface, filterOpts *filters.Options) (map[string]map[schema.GroupVersionResource][]ResourceInfo, error) {
+func retrievePendingDeletionResources(ctx context.Context, resourceTypes []*metav1.APIResourceList, dynamicClient dynamic.Interface, filterOpts *filters.Options) (map[string]map[schema.GroupVersionResource][]ResourceInfo, error) {
 	pendingDeletionResources := make(map[string]map[schema.GroupVersionResource][]ResourceInfo) //map[namespace]map[gvr][]resourceNames
+	var errs []error
 
 	for _, apiResourceList := range resourceTypes {
 		gv, err := schema.ParseGroupVersion(apiResourceList.GroupVersion)
@@ -42,9 +44,12 @@ func retrievePendingDeletionResources(resourceTypes []*metav1.APIResourceList, d
 				resourceList, err := dynamicClient.
 					Resource(gvr).
 					Namespace(metav1.NamespaceAll).
-					List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+					List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 				if err != nil {
-					fmt.Printf("Error listing resources for GVR %s: %v\n", apiResourceList.GroupVersion, err)
+					if ctx.Err() != nil {
+						return pendingDeletionResources, ctx.Err()
+					}
+					errs = append(errs, fmt.Errorf("failed to list %s: %w", gvr, err))
 					continue
 				}
 				for _, item := range resourceList.Items {
@@ -65,65 +70,141 @@ func retrievePendingDeletionResources(resourceTypes []*metav1.APIResourceList, d
 			}
 		}
 	}
-	return pendingDeletionResources, nil
+	return pendingDeletionResources, errors.Join(errs...)
 }
 
-func getResourcesWithFinalizersPendingDeletion(clientset kubernetes.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) (map[string]map[schema.GroupVersionResource][]ResourceInfo, error) {
+func getResourcesWithFinalizersPendingDeletion(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, filterOpts *filters.Options) (map[string]map[schema.GroupVersionResource][]ResourceInfo, error) {
 	// Use the discovery client to fetch API resources
 	resourceTypes, err := clientset.Discovery().ServerPreferredResources()
+	var discoveryErr error
 	if err != nil {
-		fmt.Printf("Error fetching server resources: %v\n", err)
-		os.Exit(1)
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, fmt.Errorf("failed to fetch server resources: %w", err)
+		}
+		// the resources of the groups that were discovered are still scanned
+		discoveryErr = fmt.Errorf("failed to fetch some server resources: %w", err)
 	}
 
-	return retrievePendingDeletionResources(resourceTypes, dynamicClient, filterOpts)
+	pendingDeletionResources, err := retrievePendingDeletionResources(ctx, resourceTypes, dynamicClient, filterOpts)
+	return pendingDeletionResources, errors.Join(discoveryErr, err)
 }
 
-func GetUnusedfinalizers(filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient *dynamic.DynamicClient, outputFormat string, opts common.Opts) (string, error) {
-	var outputBuffer bytes.Buffer
-	namespaces := filterOpts.Namespaces(clientset)
-	response := make(map[string]map[string][]ResourceInfo)
-	pendingDeletionDiffs, err := getResourcesWithFinalizersPendingDeletion(clientset, dynamicClient, filterOpts)
-
+// GetUnusedFinalizersReport reports the resources stuck waiting for their finalizers, removing the finalizers if requested
+func GetUnusedFinalizersReport(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, opts common.Opts) (*Report, error) {
+	thresholds, err := newThresholds(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateDryRun(opts.DryRun); err != nil {
+		return nil, err
+	}
+	audit, err := newAuditLog(opts, clientset)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to process resources waiting for finalizers: %v\n", err)
+		return nil, err
+	}
+
+	report := &Report{}
+	scanCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		scanCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
 	}
 
-	allDiffs := make(map[string][]ResourceInfo)
+	namespaces, err := filterOpts.Namespaces(scanCtx, clientset)
+	if err != nil {
+		report.addError("", "", err)
+	}
+	pendingDeletionDiffs, err := getResourcesWithFinalizersPendingDeletion(scanCtx, clientset, dynamicClient, filterOpts)
+	if scanCtx.Err() != nil {
+		report.Incomplete = true
+		report.addError("", "", fmt.Errorf("scan stopped before completion, results are incomplete: %w", scanCtx.Err()))
+	} else if err != nil {
+		report.addError("", "", err)
+	}
 
-	for namespace, resourceType := range pendingDeletionDiffs {
+	pendingNamespaces := make([]string, 0, len(pendingDeletionDiffs))
+	for namespace := range pendingDeletionDiffs {
 		if slices.Contains(namespaces, namespace) || namespace == metav1.NamespaceAll {
-			for gvr, resourceDiff := range resourceType {
-				if opts.DeleteFlag {
-					if resourceDiff, err = DeleteResourceWithFinalizer(resourceDiff, dynamicClient, namespace, gvr, opts.NoInteractive); err != nil {
-						fmt.Fprintf(os.Stderr, "Failed to delete objects waiting for Finalizers %s in namespace %s: %v\n", resourceDiff, namespace, err)
-					}
-				}
-				allDiffs[gvr.Resource] = resourceDiff
-			}
+			pendingNamespaces = append(pendingNamespaces, namespace)
+		}
+	}
+	sort.Strings(pendingNamespaces)
 
-			output := formatOutputForNamespace(namespace, allDiffs, opts)
-			outputBuffer.WriteString(output)
+	type pendingGroup struct {
+		namespace string
+		gvr       schema.GroupVersionResource
+		findings  []Finding
+	}
+	var groups []pendingGroup
+	gvrOf := make(map[objectKey]schema.GroupVersionResource)
+	for _, namespace := range pendingNamespaces {
+		gvrs := make([]schema.GroupVersionResource, 0, len(pendingDeletionDiffs[namespace]))
+		for gvr := range pendingDeletionDiffs[namespace] {
+			gvrs = append(gvrs, gvr)
+		}
+		sort.Slice(gvrs, func(i, j int) bool { return gvrs[i].String() < gvrs[j].String() })
 
-			response[namespace] = allDiffs
+		for _, gvr := range gvrs {
+			findings := newFindings(gvr.Resource, namespace, pendingDeletionDiffs[namespace][gvr])
+			for _, finding := range findings {
+				gvrOf[objectKey{finding.Kind, finding.Namespace, finding.Name}] = gvr
+			}
+			groups = append(groups, pendingGroup{namespace: namespace, gvr: gvr, findings: findings})
 		}
 	}
 
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(response, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(response, "", "  "); err != nil {
-			return "", err
+	var review reviewResult
+	if opts.DeleteFlag && !report.Incomplete {
+		var all []Finding
+		for _, group := range groups {
+			all = append(all, group.findings...)
+		}
+		review, err = reviewDeletion(ctx, all, nil, opts, AuditRemoveFinalizers,
+			func(finding Finding) error {
+				gvr := gvrOf[objectKey{finding.Kind, finding.Namespace, finding.Name}]
+				return FlagDynamicResource(ctx, dynamicClient, finding.Namespace, gvr, finding.Name)
+			},
+			func(finding Finding) (runtime.Object, error) {
+				gvr := gvrOf[objectKey{finding.Kind, finding.Namespace, finding.Name}]
+				return dynamicClient.Resource(gvr).Namespace(finding.Namespace).Get(ctx, finding.Name, metav1.GetOptions{})
+			}, audit)
+		if err != nil {
+			report.addError("", "", err)
 		}
 	}
 
-	unusedFinalizers, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
+	backup := newBackupArchive(opts)
+	for _, namespace := range pendingNamespaces {
+		report.addNamespace(namespace)
 	}
+	for _, group := range groups {
+		findings := group.findings
+		if opts.DeleteFlag && !report.Incomplete {
+			var err error
+			findings, err = deleteFinalizerFindings(ctx, findings, dynamicClient, group.gvr, opts, review, backup, audit)
+			if err != nil {
+				report.addError(group.gvr.Resource, group.namespace, err)
+			}
+		}
+		report.Findings = append(report.Findings, findings...)
+	}
+	if err := backup.Close(); err != nil {
+		report.addError("", "", fmt.Errorf("failed to close backup archive: %w", err))
+	}
+	report.BackupArchive = backup.Path()
+	if err := audit.Close(); err != nil {
+		report.addError("", "", fmt.Errorf("failed to close audit log: %w", err))
+	}
+	report.Violations = thresholds.check(report.Findings)
 
-	return unusedFinalizers, nil
+	return report, nil
+}
+
+func GetUnusedfinalizers(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient *dynamic.DynamicClient, outputFormat string, opts common.Opts) (string, error) {
+	report, err := GetUnusedFinalizersReport(ctx, filterOpts, clientset, dynamicClient, opts)
+	if err != nil {
+		return "", err
+	}
+	return formatReportWithThresholds(report, outputFormat, opts)
 }
@@ -1,6 +1,7 @@
 package kor
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -87,7 +88,7 @@ func TestRetrievePendingDeletionResources(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result, err := retrievePendingDeletionResources(test.apiResourceLists, dynamicClient, &filters.Options{})
+			result, err := retrievePendingDeletionResources(context.TODO(), test.apiResourceLists, dynamicClient, &filters.Options{})
 			if (err != nil) != test.expectedError {
 				t.Errorf("Expected error: %v, Got: %v", test.expectedError, err)
 			}
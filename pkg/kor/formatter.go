@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/olekukonko/tablewriter"
@@ -25,7 +27,17 @@ func getTableRow(index int, columns ...string) []string {
 	return row
 }
 
-func unusedResourceFormatter(outputFormat string, outputBuffer bytes.Buffer, opts common.Opts, jsonResponse []byte) (string, error) {
+// The json and yaml output keep the scan status next to the namespace or
+// kind keys, under keys starting with an underscore, which no namespace or
+// kind name can start with
+const (
+	// scanErrorsKey holds the scan errors
+	scanErrorsKey = "_errors"
+	// scanIncompleteKey is true when the scan stopped before completion
+	scanIncompleteKey = "_incomplete"
+)
+
+func unusedResourceFormatter(outputFormat string, outputBuffer bytes.Buffer, opts common.Opts, jsonResponse []byte, scanErrors []ScanError, incomplete bool) (string, error) {
 	switch outputFormat {
 	case "table":
 		if opts.WebhookURL == "" && (opts.Channel == "" || opts.Token == "") {
@@ -40,6 +52,7 @@ func unusedResourceFormatter(outputFormat string, outputBuffer bytes.Buffer, opt
 			return "", err
 		}
 
+		output := make(map[string]any, len(resources)+2)
 		if !opts.ShowReason {
 			// Create a map of namespaces with their corresponding maps of resource types and lists of resource names
 			namespaces := make(map[string]map[string][]string)
@@ -53,21 +66,22 @@ func unusedResourceFormatter(outputFormat string, outputBuffer bytes.Buffer, opt
 					}
 				}
 			}
-			// Marshal the map to JSON format
-			modifiedJSONResponse, err := json.MarshalIndent(namespaces, "", "  ")
-			if err != nil {
-				return "", err
+			for namespace, resourceMap := range namespaces {
+				output[namespace] = resourceMap
 			}
-			if outputFormat == "yaml" {
-				modifiedJSONResponse, err = yaml.JSONToYAML(modifiedJSONResponse)
-				if err != nil {
-					return "", err
-				}
+		} else {
+			for namespace, resourceMap := range resources {
+				output[namespace] = resourceMap
 			}
-			return string(modifiedJSONResponse), nil
+		}
+		if len(scanErrors) != 0 {
+			output[scanErrorsKey] = scanErrors
+		}
+		if incomplete {
+			output[scanIncompleteKey] = true
 		}
 
-		modifiedJSONResponse, err := json.MarshalIndent(resources, "", "  ")
+		modifiedJSONResponse, err := json.MarshalIndent(output, "", "  ")
 		if err != nil {
 			return "", err
 		}
@@ -84,16 +98,49 @@ func unusedResourceFormatter(outputFormat string, outputBuffer bytes.Buffer, opt
 	return "", fmt.Errorf("unsupported output format: %s", outputFormat)
 }
 
+// formatUnusedResources renders the grouped unused resources and the scan
+// status in the requested output format
+func formatUnusedResources(resources map[string]map[string][]ResourceInfo, scanErrors []ScanError, incomplete bool, outputFormat string, opts common.Opts) (string, error) {
+	var outputBuffer bytes.Buffer
+	var jsonResponse []byte
+	switch outputFormat {
+	case "table":
+		outputBuffer = FormatOutput(resources, opts)
+		if len(scanErrors) != 0 {
+			outputBuffer.WriteString(formatScanErrors(scanErrors))
+		}
+	case "json", "yaml":
+		var err error
+		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
+			return "", err
+		}
+	}
+
+	return unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse, scanErrors, incomplete)
+}
+
+func formatScanErrors(scanErrors []ScanError) string {
+	var buf strings.Builder
+	table := tablewriter.NewWriter(&buf)
+	table.SetColWidth(60)
+	table.SetHeader([]string{"#", "RESOURCE TYPE", "NAMESPACE", "ERROR"})
+	for index, scanError := range scanErrors {
+		table.Append(getTableRow(index, scanError.Kind, scanError.Namespace, scanError.Message))
+	}
+	table.Render()
+	return fmt.Sprintf("Errors during the scan:\n%s\n", buf.String())
+}
+
 func FormatOutput(resources map[string]map[string][]ResourceInfo, opts common.Opts) bytes.Buffer {
 	var output bytes.Buffer
 	switch opts.GroupBy {
 	case "namespace":
-		for namespace, diffs := range resources {
-			output.WriteString(formatOutputForNamespace(namespace, diffs, opts))
+		for _, namespace := range slices.Sorted(maps.Keys(resources)) {
+			output.WriteString(formatOutputForNamespace(namespace, resources[namespace], opts))
 		}
 	case "resource":
-		for resource, diffs := range resources {
-			output.WriteString(formatOutputForResource(resource, diffs, opts))
+		for _, resource := range slices.Sorted(maps.Keys(resources)) {
+			output.WriteString(formatOutputForResource(resource, resources[resource], opts))
 		}
 	}
 	return output
@@ -106,8 +153,8 @@ func formatOutputForNamespace(namespace string, resources map[string][]ResourceI
 	table.SetHeader(getTableHeader(opts.GroupBy, opts.ShowReason))
 	allEmpty := true
 	var index int
-	for resourceType, diff := range resources {
-		for _, info := range diff {
+	for _, resourceType := range slices.Sorted(maps.Keys(resources)) {
+		for _, info := range resources[resourceType] {
 			row := getTableRow(index, resourceType, info.Name)
 			if opts.ShowReason && info.Reason != "" {
 				row = append(row, info.Reason)
@@ -140,8 +187,8 @@ func formatOutputForResource(resource string, resources map[string][]ResourceInf
 	table.SetColWidth(60)
 	table.SetHeader(getTableHeader(opts.GroupBy, opts.ShowReason))
 	var index int
-	for ns, infos := range resources {
-		for _, info := range infos {
+	for _, ns := range slices.Sorted(maps.Keys(resources)) {
+		for _, info := range resources[ns] {
 			row := getTableRow(index, ns, info.Name)
 			if opts.ShowReason && info.Reason != "" {
 				row = append(row, info.Reason)
@@ -0,0 +1,365 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// gatewayAPIGroup is the group of the Gateway API kinds. Their CRDs are
+// optional, so they are read through the dynamic client and the kinds that
+// are not installed have no objects.
+const gatewayAPIGroup = "gateway.networking.k8s.io"
+
+var (
+	gatewayClassGVR = schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1", Resource: "gatewayclasses"}
+	gatewayGVR      = schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1", Resource: "gateways"}
+	httpRouteGVR    = schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1", Resource: "httproutes"}
+	grpcRouteGVR    = schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1", Resource: "grpcroutes"}
+	tlsRouteGVR     = schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1alpha2", Resource: "tlsroutes"}
+)
+
+// routeGVRs are the routes attached to Gateways by their parentRefs
+var routeGVRs = []schema.GroupVersionResource{httpRouteGVR, grpcRouteGVR, tlsRouteGVR}
+
+// listGatewayAPI lists the objects of a Gateway API kind in namespace, or in
+// all namespaces when it is empty. There are none when the kind is not installed.
+func listGatewayAPI(ctx context.Context, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, namespace, labelSelector string) ([]unstructured.Unstructured, error) {
+	list, err := dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %v", gvr.Resource, err)
+	}
+	return list.Items, nil
+}
+
+// listGatewayAPIReferrers lists the objects of a Gateway API kind that may
+// reference the objects of namespace, which may be in any namespace. Only the
+// ones of namespace are listed when kor is not allowed to list them all.
+func listGatewayAPIReferrers(ctx context.Context, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, namespace string) ([]unstructured.Unstructured, error) {
+	items, err := listGatewayAPI(ctx, dynamicClient, gvr, metav1.NamespaceAll, "")
+	if err != nil && namespace != metav1.NamespaceAll && apierrors.IsForbidden(err) {
+		return listGatewayAPI(ctx, dynamicClient, gvr, namespace, "")
+	}
+	return items, err
+}
+
+// gatewayAPIRefs returns the objects of a kind referenced by a list of
+// Gateway API object references, whose group, kind and namespace default to
+// the ones given
+func gatewayAPIRefs(refs []interface{}, group, kind, namespace string) []types.NamespacedName {
+	var objects []types.NamespacedName
+	for _, item := range refs {
+		ref, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		refGroup, found, _ := unstructured.NestedString(ref, "group")
+		if !found {
+			refGroup = group
+		}
+		refKind, found, _ := unstructured.NestedString(ref, "kind")
+		if !found {
+			refKind = kind
+		}
+		if refGroup != group || refKind != kind {
+			continue
+		}
+		refNamespace, _, _ := unstructured.NestedString(ref, "namespace")
+		if refNamespace == "" {
+			refNamespace = namespace
+		}
+		name, _, _ := unstructured.NestedString(ref, "name")
+		objects = append(objects, types.NamespacedName{Namespace: refNamespace, Name: name})
+	}
+	return objects
+}
+
+// routeParentGateways returns the Gateways a route is attached to, leaving
+// out its other parents such as the Services of a service mesh
+func routeParentGateways(route unstructured.Unstructured) []types.NamespacedName {
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	return gatewayAPIRefs(parentRefs, gatewayAPIGroup, "Gateway", route.GetNamespace())
+}
+
+// routeBackendServices returns the Services the rules of a route forward to
+func routeBackendServices(route unstructured.Unstructured) []types.NamespacedName {
+	var services []types.NamespacedName
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	for _, item := range rules {
+		rule, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		backendRefs, _, _ := unstructured.NestedSlice(rule, "backendRefs")
+		services = append(services, gatewayAPIRefs(backendRefs, "", "Service", route.GetNamespace())...)
+	}
+	return services
+}
+
+// routeReason returns why a route is unused: none of its parent Gateways
+// exist, or one of its backend Services doesn't. It is an empty string for
+// the routes in use. The parents are looked up in the Gateway lists of their
+// namespaces, which a DynamicSnapshot lists once per scan.
+func routeReason(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, route unstructured.Unstructured) (string, error) {
+	parents := routeParentGateways(route)
+	var missingParents []string
+	for _, parent := range parents {
+		gateways, err := listGatewayAPI(ctx, dynamicClient, gatewayGVR, parent.Namespace, "")
+		if err != nil {
+			return "", err
+		}
+		exists := slices.ContainsFunc(gateways, func(gateway unstructured.Unstructured) bool {
+			return gateway.GetName() == parent.Name
+		})
+		if !exists {
+			missingParents = append(missingParents, parent.String())
+		}
+	}
+	if len(parents) > 0 && len(missingParents) == len(parents) {
+		if len(missingParents) == 1 {
+			return fmt.Sprintf("Parent Gateway %s does not exist", missingParents[0]), nil
+		}
+		return fmt.Sprintf("None of the parent Gateways %s exist", strings.Join(missingParents, ", ")), nil
+	}
+
+	for _, service := range routeBackendServices(route) {
+		_, err := clientset.CoreV1().Services(service.Namespace).Get(ctx, service.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("Backend Service %s does not exist", service), nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get service %s: %v", service, err)
+		}
+	}
+	return "", nil
+}
+
+func processNamespaceRoutes(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	routes, err := listGatewayAPI(ctx, dynamicClient, gvr, namespace, filterOpts.IncludeLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedRoutes []ResourceInfo
+
+	for _, route := range routes {
+		// Skip resources with ownerReferences if the general flag is set
+		if filterOpts.IgnoreOwnerReferences && len(route.GetOwnerReferences()) > 0 {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&route).Run(filterOpts); pass {
+			continue
+		}
+
+		if route.GetLabels()["kor/used"] == "false" {
+			reason := "Marked with unused label"
+			unusedRoutes = append(unusedRoutes, ResourceInfo{Name: route.GetName(), Reason: reason})
+			continue
+		}
+
+		reason, err := routeReason(ctx, clientset, dynamicClient, route)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			unusedRoutes = append(unusedRoutes, ResourceInfo{Name: route.GetName(), Reason: reason})
+		}
+	}
+
+	return unusedRoutes, nil
+}
+
+// routeDetect adapts processNamespaceRoutes to the detectFunc of a kind of route
+func routeDetect(gvr schema.GroupVersionResource) detectFunc {
+	return func(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options, _ common.Opts) ([]ResourceInfo, error) {
+		if err := requireClientsetAndDynamic(clients); err != nil {
+			return nil, err
+		}
+		return processNamespaceRoutes(ctx, clients.Clientset, clients.Dynamic, gvr, namespace, filterOpts)
+	}
+}
+
+func processNamespaceGateways(ctx context.Context, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	gateways, err := listGatewayAPI(ctx, dynamicClient, gatewayGVR, namespace, filterOpts.IncludeLabels)
+	if err != nil || len(gateways) == 0 {
+		return nil, err
+	}
+
+	// routes may attach to the Gateways of other namespaces
+	attached := make(map[types.NamespacedName]bool)
+	for _, gvr := range routeGVRs {
+		routes, err := listGatewayAPIReferrers(ctx, dynamicClient, gvr, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, route := range routes {
+			for _, parent := range routeParentGateways(route) {
+				attached[parent] = true
+			}
+		}
+	}
+
+	var unusedGateways []ResourceInfo
+
+	for _, gateway := range gateways {
+		// Skip resources with ownerReferences if the general flag is set
+		if filterOpts.IgnoreOwnerReferences && len(gateway.GetOwnerReferences()) > 0 {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&gateway).Run(filterOpts); pass {
+			continue
+		}
+
+		if gateway.GetLabels()["kor/used"] == "false" {
+			reason := "Marked with unused label"
+			unusedGateways = append(unusedGateways, ResourceInfo{Name: gateway.GetName(), Reason: reason})
+			continue
+		}
+
+		if !attached[types.NamespacedName{Namespace: gateway.GetNamespace(), Name: gateway.GetName()}] {
+			reason := "Gateway has no attached routes"
+			unusedGateways = append(unusedGateways, ResourceInfo{Name: gateway.GetName(), Reason: reason})
+		}
+	}
+
+	return unusedGateways, nil
+}
+
+func detectGateways(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options, _ common.Opts) ([]ResourceInfo, error) {
+	if err := requireDynamic(clients); err != nil {
+		return nil, err
+	}
+	return processNamespaceGateways(ctx, clients.Dynamic, namespace, filterOpts)
+}
+
+func processGatewayClasses(ctx context.Context, dynamicClient dynamic.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	gatewayClasses, err := listGatewayAPI(ctx, dynamicClient, gatewayClassGVR, metav1.NamespaceAll, filterOpts.IncludeLabels)
+	if err != nil || len(gatewayClasses) == 0 {
+		return nil, err
+	}
+
+	gateways, err := listGatewayAPI(ctx, dynamicClient, gatewayGVR, metav1.NamespaceAll, "")
+	if err != nil {
+		return nil, err
+	}
+	usedClasses := make(map[string]bool)
+	for _, gateway := range gateways {
+		className, _, _ := unstructured.NestedString(gateway.Object, "spec", "gatewayClassName")
+		usedClasses[className] = true
+	}
+
+	var unusedGatewayClasses []ResourceInfo
+
+	for _, gatewayClass := range gatewayClasses {
+		// Skip resources with ownerReferences if the general flag is set
+		if filterOpts.IgnoreOwnerReferences && len(gatewayClass.GetOwnerReferences()) > 0 {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&gatewayClass).Run(filterOpts); pass {
+			continue
+		}
+
+		if gatewayClass.GetLabels()["kor/used"] == "false" {
+			reason := "Marked with unused label"
+			unusedGatewayClasses = append(unusedGatewayClasses, ResourceInfo{Name: gatewayClass.GetName(), Reason: reason})
+			continue
+		}
+
+		if !usedClasses[gatewayClass.GetName()] {
+			reason := "GatewayClass is not used by any Gateway"
+			unusedGatewayClasses = append(unusedGatewayClasses, ResourceInfo{Name: gatewayClass.GetName(), Reason: reason})
+		}
+	}
+
+	return unusedGatewayClasses, nil
+}
+
+func detectGatewayClasses(ctx context.Context, clients Clients, _ string, filterOpts *filters.Options, _ common.Opts) ([]ResourceInfo, error) {
+	if err := requireDynamic(clients); err != nil {
+		return nil, err
+	}
+	return processGatewayClasses(ctx, clients.Dynamic, filterOpts)
+}
+
+// retrieveGatewayCertificates returns the Secrets of a namespace referenced
+// by the certificateRefs of Gateway listeners
+func retrieveGatewayCertificates(ctx context.Context, dynamicClient dynamic.Interface, namespace string) (references, error) {
+	used := references{}
+	gateways, err := listGatewayAPIReferrers(ctx, dynamicClient, gatewayGVR, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, gateway := range gateways {
+		listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+		for i, item := range listeners {
+			listener, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			certificateRefs, _, _ := unstructured.NestedSlice(listener, "tls", "certificateRefs")
+			for j, secret := range gatewayAPIRefs(certificateRefs, "", "Secret", gateway.GetNamespace()) {
+				if secret.Namespace == namespace {
+					used.add(secret.Name, "Gateway", gateway.GetNamespace(), gateway.GetName(), fmt.Sprintf("spec.listeners[%d].tls.certificateRefs[%d]", i, j))
+				}
+			}
+		}
+	}
+
+	return used, nil
+}
+
+var (
+	gatewayClassDetector = newDynamicDetector("GatewayClass", []string{"gatewayclass", "gatewayclasses"}, ClusterScoped, detectGatewayClasses, gatewayClassGVR)
+	gatewayDetector      = newDynamicDetector("Gateway", []string{"gateway", "gateways"}, NamespaceScoped, detectGateways, gatewayGVR)
+	httpRouteDetector    = newDynamicDetector("HTTPRoute", []string{"httproute", "httproutes"}, NamespaceScoped, routeDetect(httpRouteGVR), httpRouteGVR)
+	grpcRouteDetector    = newDynamicDetector("GRPCRoute", []string{"grpcroute", "grpcroutes"}, NamespaceScoped, routeDetect(grpcRouteGVR), grpcRouteGVR)
+	tlsRouteDetector     = newDynamicDetector("TLSRoute", []string{"tlsroute", "tlsroutes"}, NamespaceScoped, routeDetect(tlsRouteGVR), tlsRouteGVR)
+)
+
+func init() {
+	MustRegisterDetector(gatewayClassDetector)
+	MustRegisterDetector(gatewayDetector)
+	MustRegisterDetector(httpRouteDetector)
+	MustRegisterDetector(grpcRouteDetector)
+	MustRegisterDetector(tlsRouteDetector)
+}
+
+func GetUnusedGatewayClasses(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{gatewayClassDetector}, filterOpts, Clients{Clientset: clientset, Dynamic: dynamicClient}, outputFormat, opts)
+}
+
+func GetUnusedGateways(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{gatewayDetector}, filterOpts, Clients{Clientset: clientset, Dynamic: dynamicClient}, outputFormat, opts)
+}
+
+func GetUnusedHTTPRoutes(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{httpRouteDetector}, filterOpts, Clients{Clientset: clientset, Dynamic: dynamicClient}, outputFormat, opts)
+}
+
+func GetUnusedGRPCRoutes(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{grpcRouteDetector}, filterOpts, Clients{Clientset: clientset, Dynamic: dynamicClient}, outputFormat, opts)
+}
+
+func GetUnusedTLSRoutes(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{tlsRouteDetector}, filterOpts, Clients{Clientset: clientset, Dynamic: dynamicClient}, outputFormat, opts)
+}
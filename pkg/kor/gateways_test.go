@@ -0,0 +1,333 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+const otherTestNamespace = "other-namespace"
+
+var gatewayAPIListKinds = map[schema.GroupVersionResource]string{
+	gatewayClassGVR: "GatewayClassList",
+	gatewayGVR:      "GatewayList",
+	httpRouteGVR:    "HTTPRouteList",
+	grpcRouteGVR:    "GRPCRouteList",
+	tlsRouteGVR:     "TLSRouteList",
+}
+
+func createTestGateway(namespace, name, className string, certificateRefs ...map[string]interface{}) *unstructured.Unstructured {
+	gateway := CreateTestUnstructered("Gateway", "gateway.networking.k8s.io/v1", namespace, name)
+	refs := make([]interface{}, 0, len(certificateRefs))
+	for _, ref := range certificateRefs {
+		refs = append(refs, ref)
+	}
+	gateway.Object["spec"] = map[string]interface{}{
+		"gatewayClassName": className,
+		"listeners": []interface{}{
+			map[string]interface{}{"name": "http", "port": int64(80), "protocol": "HTTP"},
+			map[string]interface{}{
+				"name":     "https",
+				"port":     int64(443),
+				"protocol": "HTTPS",
+				"tls":      map[string]interface{}{"certificateRefs": refs},
+			},
+		},
+	}
+	return gateway
+}
+
+func createTestRoute(kind, apiVersion, namespace, name string, parentRefs []map[string]interface{}, backends ...string) *unstructured.Unstructured {
+	route := CreateTestUnstructered(kind, apiVersion, namespace, name)
+	parents := make([]interface{}, 0, len(parentRefs))
+	for _, ref := range parentRefs {
+		parents = append(parents, ref)
+	}
+	backendRefs := make([]interface{}, 0, len(backends))
+	for _, backend := range backends {
+		backendRefs = append(backendRefs, map[string]interface{}{"name": backend, "port": int64(80)})
+	}
+	route.Object["spec"] = map[string]interface{}{
+		"parentRefs": parents,
+		"rules":      []interface{}{map[string]interface{}{"backendRefs": backendRefs}},
+	}
+	return route
+}
+
+func createTestGatewayAPI(t *testing.T) (*fake.Clientset, *dynamicfake.FakeDynamicClient) {
+	clientset := fake.NewClientset()
+
+	for _, namespace := range []string{testNamespace, otherTestNamespace} {
+		_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(namespace, AppLabels), metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating namespace %s: %v", namespace, err)
+		}
+	}
+	_, err := clientset.CoreV1().Services(testNamespace).Create(context.TODO(), CreateTestService(testNamespace, "web"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake service: %v", err)
+	}
+	for _, name := range []string{"web-tls", "shared-tls", "unused-tls"} {
+		_, err = clientset.CoreV1().Secrets(testNamespace).Create(context.TODO(), CreateTestSecret(testNamespace, name, AppLabels), metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake secret %s: %v", name, err)
+		}
+	}
+
+	gateway := func(name string) map[string]interface{} {
+		return map[string]interface{}{"name": name}
+	}
+	unusedLabelRoute := createTestRoute("HTTPRoute", "gateway.networking.k8s.io/v1", testNamespace, "unused-label-route", []map[string]interface{}{gateway("web")}, "web")
+	unusedLabelRoute.SetLabels(UnusedLabels)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gatewayAPIListKinds)
+	objects := []struct {
+		gvr    schema.GroupVersionResource
+		object *unstructured.Unstructured
+	}{
+		{gatewayClassGVR, CreateTestUnstructered("GatewayClass", "gateway.networking.k8s.io/v1", "", "used-class")},
+		{gatewayClassGVR, CreateTestUnstructered("GatewayClass", "gateway.networking.k8s.io/v1", "", "unused-class")},
+		{gatewayGVR, createTestGateway(testNamespace, "web", "used-class", map[string]interface{}{"name": "web-tls"})},
+		{gatewayGVR, createTestGateway(testNamespace, "idle", "used-class")},
+		{gatewayGVR, createTestGateway(otherTestNamespace, "shared", "used-class",
+			map[string]interface{}{"kind": "Secret", "name": "shared-tls", "namespace": testNamespace})},
+		{httpRouteGVR, createTestRoute("HTTPRoute", "gateway.networking.k8s.io/v1", testNamespace, "web-route", []map[string]interface{}{gateway("web")}, "web")},
+		{httpRouteGVR, createTestRoute("HTTPRoute", "gateway.networking.k8s.io/v1", testNamespace, "orphaned-route", []map[string]interface{}{gateway("deleted")}, "web")},
+		{httpRouteGVR, createTestRoute("HTTPRoute", "gateway.networking.k8s.io/v1", testNamespace, "missing-backend-route", []map[string]interface{}{gateway("web")}, "web", "deleted")},
+		// still attached to the Gateway that exists
+		{httpRouteGVR, createTestRoute("HTTPRoute", "gateway.networking.k8s.io/v1", testNamespace, "partly-orphaned-route", []map[string]interface{}{gateway("web"), gateway("deleted")}, "web")},
+		// the parents of mesh routes are Services, not Gateways
+		{httpRouteGVR, createTestRoute("HTTPRoute", "gateway.networking.k8s.io/v1", testNamespace, "mesh-route", []map[string]interface{}{{"group": "", "kind": "Service", "name": "web"}}, "web")},
+		{httpRouteGVR, unusedLabelRoute},
+		{grpcRouteGVR, createTestRoute("GRPCRoute", "gateway.networking.k8s.io/v1", testNamespace, "grpc-route", []map[string]interface{}{gateway("deleted")})},
+		{tlsRouteGVR, createTestRoute("TLSRoute", "gateway.networking.k8s.io/v1alpha2", otherTestNamespace, "tls-route", []map[string]interface{}{{"name": "shared"}}, "web")},
+	}
+	for _, object := range objects {
+		_, err = dynamicClient.Resource(object.gvr).Namespace(object.object.GetNamespace()).Create(context.TODO(), object.object, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s %s: %v", object.object.GetKind(), object.object.GetName(), err)
+		}
+	}
+
+	return clientset, dynamicClient
+}
+
+func TestProcessNamespaceRoutes(t *testing.T) {
+	clientset, dynamicClient := createTestGatewayAPI(t)
+
+	unused, err := processNamespaceRoutes(context.TODO(), clientset, dynamicClient, httpRouteGVR, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Error retrieving unused HTTPRoutes: %v", err)
+	}
+	expected := []ResourceInfo{
+		{Name: "missing-backend-route", Reason: "Backend Service test-namespace/deleted does not exist"},
+		{Name: "orphaned-route", Reason: "Parent Gateway test-namespace/deleted does not exist"},
+		{Name: "unused-label-route", Reason: "Marked with unused label"},
+	}
+	sort.Slice(unused, func(i, j int) bool { return unused[i].Name < unused[j].Name })
+	if !reflect.DeepEqual(unused, expected) {
+		t.Errorf("Expected unused HTTPRoutes %v, got %v", expected, unused)
+	}
+
+	unused, err = processNamespaceRoutes(context.TODO(), clientset, dynamicClient, tlsRouteGVR, otherTestNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Error retrieving unused TLSRoutes: %v", err)
+	}
+	expected = []ResourceInfo{{Name: "tls-route", Reason: "Backend Service other-namespace/web does not exist"}}
+	if !reflect.DeepEqual(unused, expected) {
+		t.Errorf("Expected unused TLSRoutes %v, got %v", expected, unused)
+	}
+}
+
+func TestProcessNamespaceGateways(t *testing.T) {
+	_, dynamicClient := createTestGatewayAPI(t)
+
+	unused, err := processNamespaceGateways(context.TODO(), dynamicClient, testNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Error retrieving unused Gateways: %v", err)
+	}
+	expected := []ResourceInfo{{Name: "idle", Reason: "Gateway has no attached routes"}}
+	if !reflect.DeepEqual(unused, expected) {
+		t.Errorf("Expected unused Gateways %v, got %v", expected, unused)
+	}
+
+	// attached to by the TLSRoute of its namespace
+	unused, err = processNamespaceGateways(context.TODO(), dynamicClient, otherTestNamespace, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Error retrieving unused Gateways: %v", err)
+	}
+	if len(unused) != 0 {
+		t.Errorf("Expected no unused Gateways in %s, got %v", otherTestNamespace, unused)
+	}
+}
+
+func TestProcessGatewayClasses(t *testing.T) {
+	_, dynamicClient := createTestGatewayAPI(t)
+
+	unused, err := processGatewayClasses(context.TODO(), dynamicClient, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Error retrieving unused GatewayClasses: %v", err)
+	}
+	expected := []ResourceInfo{{Name: "unused-class", Reason: "GatewayClass is not used by any Gateway"}}
+	if !reflect.DeepEqual(unused, expected) {
+		t.Errorf("Expected unused GatewayClasses %v, got %v", expected, unused)
+	}
+}
+
+func TestProcessGatewayAPINotInstalled(t *testing.T) {
+	clientset := fake.NewClientset()
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gatewayAPIListKinds)
+	dynamicClient.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), "")
+	})
+
+	unused, err := processNamespaceRoutes(context.TODO(), clientset, dynamicClient, httpRouteGVR, testNamespace, &filters.Options{})
+	if err != nil || len(unused) != 0 {
+		t.Errorf("Expected no unused HTTPRoutes without the Gateway API, got %v, %v", unused, err)
+	}
+	unused, err = processGatewayClasses(context.TODO(), dynamicClient, &filters.Options{})
+	if err != nil || len(unused) != 0 {
+		t.Errorf("Expected no unused GatewayClasses without the Gateway API, got %v, %v", unused, err)
+	}
+	used, err := retrieveGatewayCertificates(context.TODO(), dynamicClient, testNamespace)
+	if err != nil || len(used) != 0 {
+		t.Errorf("Expected no Gateway certificates without the Gateway API, got %v, %v", used, err)
+	}
+}
+
+func TestRetrieveUsedSecretGatewayCertificates(t *testing.T) {
+	clientset, dynamicClient := createTestGatewayAPI(t)
+
+	usedSecrets, err := retrieveUsedSecret(context.TODO(), clientset, dynamicClient, testNamespace)
+	if err != nil {
+		t.Fatalf("Error retrieving used secrets: %v", err)
+	}
+	for _, name := range []string{"web-tls", "shared-tls"} {
+		if _, ok := usedSecrets[name]; !ok {
+			t.Errorf("Expected secret %s referenced by a Gateway to be used, got %v", name, usedSecrets)
+		}
+	}
+	if _, ok := usedSecrets["unused-tls"]; ok {
+		t.Errorf("Expected secret unused-tls not to be used")
+	}
+
+	unused, err := processNamespaceSecret(context.TODO(), clientset, dynamicClient, testNamespace, &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Error retrieving unused secrets: %v", err)
+	}
+	expected := []ResourceInfo{{Name: "unused-tls", Reason: "Secret is not used in any pod, container, ingress, or Gateway listener"}}
+	if !reflect.DeepEqual(unused, expected) {
+		t.Errorf("Expected unused secrets %v, got %v", expected, unused)
+	}
+}
+
+func TestGetUnusedHTTPRoutes(t *testing.T) {
+	clientset, dynamicClient := createTestGatewayAPI(t)
+
+	opts := common.Opts{
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedHTTPRoutes(context.TODO(), &filters.Options{}, clientset, dynamicClient, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedHTTPRoutes: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"HTTPRoute": {"missing-backend-route", "orphaned-route", "unused-label-route"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+	sort.Strings(actualOutput[testNamespace]["HTTPRoute"])
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output %+v, but got %+v", expectedOutput, actualOutput)
+	}
+}
+
+func TestGetUnusedAllNamespacedGatewayAPI(t *testing.T) {
+	clientset, dynamicClient := createTestGatewayAPI(t)
+
+	opts := common.Opts{
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedAllNamespaced(context.TODO(), &filters.Options{}, clientset, dynamicClient, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedAllNamespaced: %v", err)
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+	if gateways := actualOutput[testNamespace]["Gateway"]; !reflect.DeepEqual(gateways, []string{"idle"}) {
+		t.Errorf("Expected the unused Gateway idle, got %v", gateways)
+	}
+
+	// without a dynamic client the Gateway API kinds fail instead of the scan
+	report, err := GetUnusedReport(context.TODO(), DetectorsByScope(NamespaceScoped), &filters.Options{IncludeNamespaces: []string{testNamespace}}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Error scanning without a dynamic client: %v", err)
+	}
+	failed := make(map[string]bool)
+	for _, scanError := range report.Errors {
+		if scanError.Message == errDynamicClientNotConfigured.Error() {
+			failed[scanError.Kind] = true
+		}
+	}
+	for _, kind := range []string{"Gateway", "HTTPRoute", "GRPCRoute", "TLSRoute"} {
+		if !failed[kind] {
+			t.Errorf("Expected %s to fail with %v, got %v", kind, errDynamicClientNotConfigured, report.Errors)
+		}
+	}
+}
+
+func TestGetUnusedReportListsGatewayAPIOnce(t *testing.T) {
+	clientset, dynamicClient := createTestGatewayAPI(t)
+	dynamicClient.ClearActions()
+
+	detectors, err := resolveDetectors([]string{"gatewayclass", "gateway", "httproute", "grpcroute", "tlsroute", "secret"})
+	if err != nil {
+		t.Fatalf("Expected all resource types to be supported, got %v", err)
+	}
+	report, err := GetUnusedReport(context.TODO(), detectors, &filters.Options{}, Clients{Clientset: clientset, Dynamic: dynamicClient}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("Expected no errors, got %v", report.Errors)
+	}
+
+	for gvr := range gatewayAPIListKinds {
+		if count := countListActions(dynamicClient, gvr.Resource); count != 1 {
+			t.Errorf("Expected %s to be listed once per scan, got %d lists", gvr.Resource, count)
+		}
+	}
+	if count := countActions(dynamicClient, "get", "gateways"); count != 0 {
+		t.Errorf("Expected the parent Gateways to be looked up in the cached list, got %d gets", count)
+	}
+}
@@ -0,0 +1,331 @@
+package kor
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// graphKinds are the detectors whose unused objects are highlighted in the
+// graph, the kinds its edges go from and to
+var graphKinds = []string{
+	"ConfigMap", "Secret", "ServiceAccount", "Pvc", "Pv", "StorageClass", "Role", "ClusterRole", "RoleBinding",
+	"ClusterRoleBinding", "PriorityClass", "Pod", "Ingress", "Service", "Hpa", "Deployment", "StatefulSet",
+}
+
+// GraphNode is a resource of the reference graph, Kind being the kind kor
+// reports it as, e.g. "Pvc"
+type GraphNode struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Unused    bool   `json:"unused"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// GraphEdge is a resource using another, from the field of the resource
+// using it
+type GraphEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Field string `json:"field"`
+}
+
+// Graph is the references between resources kor works out to tell whether
+// they are used, with the unused resources highlighted
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+	// Incomplete is set when the graph was cut short by the timeout, its
+	// unused resources and edges then only covering what was scanned in time
+	Incomplete bool `json:"incomplete,omitempty"`
+	// Errors lists the scan errors, the unused resources then being incomplete
+	Errors []ScanError `json:"errors,omitempty"`
+}
+
+type graphBuilder struct {
+	ctx       context.Context
+	clients   Clients
+	nodes     map[string]*GraphNode
+	edges     map[GraphEdge]bool
+	index     *objectIndex
+	restricts bool
+	// namespaces are the namespaces the graph is restricted to
+	namespaces map[string]bool
+}
+
+func graphNodeID(kind, namespace, name string) string {
+	if namespace == "" {
+		return kind + "/" + name
+	}
+	return kind + "/" + namespace + "/" + name
+}
+
+// graphKind returns the kind kor reports a Kubernetes kind as, and whether it
+// is cluster-scoped
+func graphKind(kind string) (string, bool) {
+	detector, ok := LookupDetector(kind)
+	if !ok {
+		return kind, false
+	}
+	return detector.Name(), detector.Scope() == ClusterScoped
+}
+
+func (b *graphBuilder) node(kind, namespace, name string) *GraphNode {
+	kind, clusterScoped := graphKind(kind)
+	if clusterScoped {
+		namespace = ""
+	}
+	id := graphNodeID(kind, namespace, name)
+	if node, ok := b.nodes[id]; ok {
+		return node
+	}
+	node := &GraphNode{ID: id, Kind: kind, Namespace: namespace, Name: name}
+	b.nodes[id] = node
+	return node
+}
+
+// addReferences adds an edge for each reference to a resource of kind that
+// exists. When the graph is restricted to some namespaces, the references to
+// cluster-scoped resources are only kept from the resources of these
+// namespaces, or from the cluster-scoped resources already in the graph.
+func (b *graphBuilder) addReferences(kind, namespace string, used references) error {
+	_, clusterScoped := graphKind(kind)
+	if clusterScoped {
+		namespace = ""
+	}
+	for _, name := range used.names() {
+		for _, reference := range used[name] {
+			if b.restricts && clusterScoped {
+				sourceKind, sourceClusterScoped := graphKind(reference.Kind)
+				if sourceClusterScoped || reference.Namespace == "" {
+					if _, ok := b.nodes[graphNodeID(sourceKind, "", reference.Name)]; !ok {
+						continue
+					}
+				} else if !b.namespaces[reference.Namespace] {
+					continue
+				}
+			}
+			exists, err := b.index.exists(kind, namespace, name)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				continue
+			}
+			from := b.node(reference.Kind, reference.Namespace, reference.Name)
+			to := b.node(kind, namespace, name)
+			b.edges[GraphEdge{From: from.ID, To: to.ID, Field: reference.Field}] = true
+		}
+	}
+	return nil
+}
+
+// BuildGraph returns the references between the resources of the namespaces
+// selected by filterOpts, or of the whole cluster when no namespace is
+// included, with the resources kor finds unused highlighted. Only the
+// references to resources that exist are edges. The scan and the edges share
+// a Snapshot, so each kind is listed once, and opts.Timeout covers both. A
+// graph cut short by it is marked Incomplete.
+func BuildGraph(ctx context.Context, clients Clients, filterOpts *filters.Options, opts common.Opts) (*Graph, error) {
+	detectors := make([]Detector, 0, len(graphKinds))
+	for _, kind := range graphKinds {
+		detector, _ := LookupDetector(kind)
+		detectors = append(detectors, detector)
+	}
+	// the graph only reads, whatever the flags
+	opts.DeleteFlag = false
+	opts.Quarantine = ""
+
+	scanCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		scanCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+		opts.Timeout = 0
+	}
+	clients.Clientset = NewSnapshot(clients.Clientset, len(filterOpts.IncludeNamespaces) == 0)
+	report, err := GetUnusedReport(scanCtx, detectors, filterOpts, clients, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}, Incomplete: report.Incomplete, Errors: report.Errors}
+	b := &graphBuilder{
+		ctx:        scanCtx,
+		clients:    clients,
+		nodes:      map[string]*GraphNode{},
+		edges:      map[GraphEdge]bool{},
+		index:      newObjectIndex(scanCtx, clients),
+		restricts:  len(filterOpts.IncludeNamespaces) > 0,
+		namespaces: map[string]bool{},
+	}
+	if !report.Incomplete {
+		if err := b.addEdges(filterOpts); err != nil {
+			if scanCtx.Err() == nil {
+				return nil, err
+			}
+			graph.Incomplete = true
+			graph.Errors = append(graph.Errors, ScanError{Message: fmt.Sprintf("graph stopped before completion, edges are incomplete: %v", scanCtx.Err())})
+		}
+	}
+
+	for _, finding := range report.Findings {
+		node := b.node(finding.Kind, finding.Namespace, finding.Name)
+		node.Unused = true
+		node.Reason = finding.Reason
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(b.nodes)) {
+		graph.Nodes = append(graph.Nodes, *b.nodes[id])
+	}
+	graph.Edges = slices.SortedFunc(maps.Keys(b.edges), func(a, b GraphEdge) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To), cmp.Compare(a.Field, b.Field))
+	})
+	return graph, nil
+}
+
+// addEdges adds the references found in the namespaces selected by
+// filterOpts, and in the cluster-scoped resources
+func (b *graphBuilder) addEdges(filterOpts *filters.Options) error {
+	ctx, clients := b.ctx, b.clients
+	namespaces, err := filterOpts.Namespaces(ctx, clients.Clientset)
+	if err != nil {
+		return err
+	}
+	for _, namespace := range namespaces {
+		b.namespaces[namespace] = true
+	}
+
+	for _, namespace := range slices.Sorted(slices.Values(namespaces)) {
+		for _, kind := range slices.Sorted(maps.Keys(referenceExplainers)) {
+			if _, clusterScoped := graphKind(kind); clusterScoped {
+				continue
+			}
+			used, err := referenceExplainers[kind].references(ctx, clients, namespace, filterOpts)
+			if err != nil {
+				return err
+			}
+			if err := b.addReferences(kind, namespace, used); err != nil {
+				return err
+			}
+		}
+		volumes, err := retrievePvcVolumes(ctx, clients.Clientset, namespace)
+		if err != nil {
+			return err
+		}
+		if err := b.addReferences("Pv", namespace, volumes); err != nil {
+			return err
+		}
+		backends, err := retrieveIngressBackends(ctx, clients.Clientset, namespace)
+		if err != nil {
+			return err
+		}
+		if err := b.addReferences("Service", namespace, backends); err != nil {
+			return err
+		}
+		targets, err := retrieveHpaTargets(ctx, clients.Clientset, namespace)
+		if err != nil {
+			return err
+		}
+		for _, kind := range slices.Sorted(maps.Keys(targets)) {
+			if err := b.addReferences(kind, namespace, targets[kind]); err != nil {
+				return err
+			}
+		}
+	}
+	for _, kind := range slices.Sorted(maps.Keys(referenceExplainers)) {
+		if _, clusterScoped := graphKind(kind); !clusterScoped {
+			continue
+		}
+		used, err := referenceExplainers[kind].references(ctx, clients, "", filterOpts)
+		if err != nil {
+			return err
+		}
+		if err := b.addReferences(kind, "", used); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n GraphNode) label() string {
+	if n.Namespace == "" {
+		return n.Kind + "\n" + n.Name
+	}
+	return n.Kind + "\n" + n.Namespace + "/" + n.Name
+}
+
+// FormatGraph formats a graph as Graphviz DOT, Mermaid, json or yaml
+func FormatGraph(graph *Graph, outputFormat string) (string, error) {
+	var buf bytes.Buffer
+	switch outputFormat {
+	case "dot":
+		buf.WriteString("digraph kor {\n  rankdir=LR;\n  node [shape=box];\n")
+		for _, node := range graph.Nodes {
+			attributes := fmt.Sprintf("label=%s", dotQuote(node.label()))
+			if node.Unused {
+				attributes += fmt.Sprintf(", style=filled, fillcolor=\"#f8d7da\", color=\"#dc3545\", tooltip=%s", dotQuote(node.Reason))
+			}
+			fmt.Fprintf(&buf, "  %s [%s];\n", dotQuote(node.ID), attributes)
+		}
+		for _, edge := range graph.Edges {
+			fmt.Fprintf(&buf, "  %s -> %s [label=%s];\n", dotQuote(edge.From), dotQuote(edge.To), dotQuote(edge.Field))
+		}
+		buf.WriteString("}\n")
+	case "mermaid":
+		buf.WriteString("flowchart LR\n")
+		ids := make(map[string]string, len(graph.Nodes))
+		var unused []string
+		for i, node := range graph.Nodes {
+			ids[node.ID] = fmt.Sprintf("n%d", i)
+			fmt.Fprintf(&buf, "  %s[\"%s\"]\n", ids[node.ID], mermaidEscape(strings.ReplaceAll(node.label(), "\n", "<br/>")))
+			if node.Unused {
+				unused = append(unused, ids[node.ID])
+			}
+		}
+		for _, edge := range graph.Edges {
+			fmt.Fprintf(&buf, "  %s -->|\"%s\"| %s\n", ids[edge.From], mermaidEscape(edge.Field), ids[edge.To])
+		}
+		if len(unused) > 0 {
+			buf.WriteString("  classDef unused fill:#f8d7da,stroke:#dc3545\n")
+			fmt.Fprintf(&buf, "  class %s unused\n", strings.Join(unused, ","))
+		}
+	case "json", "yaml":
+		data, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		if outputFormat == "yaml" {
+			if data, err = yaml.JSONToYAML(data); err != nil {
+				return "", err
+			}
+		}
+		buf.Write(data)
+		buf.WriteString("\n")
+	default:
+		return "", fmt.Errorf("unsupported graph format %q, use dot, mermaid, json or yaml", outputFormat)
+	}
+	return buf.String(), nil
+}
+
+// dotQuote quotes a DOT identifier, escaping its quotes and newlines
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// mermaidEscape escapes the quotes of a Mermaid label
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
@@ -0,0 +1,225 @@
+package kor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestGraph(t *testing.T) *fake.Clientset {
+	clientset := fake.NewClientset()
+
+	for _, namespace := range []string{testNamespace, "other-namespace"} {
+		if _, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(namespace, AppLabels), metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating namespace %s: %v", namespace, err)
+		}
+	}
+
+	pod := CreateTestPod(testNamespace, "test-pod", "", []corev1.Volume{*CreateTestVolume("data", "test-pvc")}, AppLabels)
+	pod.Spec.Containers = []corev1.Container{{
+		EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "used-cm"}}}},
+	}}
+	pvc := CreateTestPvc(testNamespace, "test-pvc", AppLabels, "test-sc")
+	pvc.Spec.VolumeName = "test-pv"
+	otherPod := CreateTestPod("other-namespace", "other-pod", "", nil, AppLabels)
+	otherPod.Spec.PriorityClassName = "test-pc"
+
+	objects := []struct {
+		create func() error
+		name   string
+	}{
+		{func() error {
+			_, err := clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+			return err
+		}, "pod"},
+		{func() error {
+			_, err := clientset.CoreV1().Pods("other-namespace").Create(context.TODO(), otherPod, metav1.CreateOptions{})
+			return err
+		}, "pod"},
+		{func() error {
+			_, err := clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, "used-cm", AppLabels), metav1.CreateOptions{})
+			return err
+		}, "configmap"},
+		{func() error {
+			_, err := clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, "unused-cm", AppLabels), metav1.CreateOptions{})
+			return err
+		}, "configmap"},
+		{func() error {
+			_, err := clientset.CoreV1().PersistentVolumeClaims(testNamespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+			return err
+		}, "pvc"},
+		{func() error {
+			_, err := clientset.CoreV1().PersistentVolumes().Create(context.TODO(), CreateTestPv("test-pv", "Bound", AppLabels, "test-sc"), metav1.CreateOptions{})
+			return err
+		}, "pv"},
+		{func() error {
+			_, err := clientset.StorageV1().StorageClasses().Create(context.TODO(), CreateTestStorageClass("test-sc", "test-provisioner"), metav1.CreateOptions{})
+			return err
+		}, "storageclass"},
+		{func() error {
+			_, err := clientset.SchedulingV1().PriorityClasses().Create(context.TODO(), CreateTestPriorityClass("test-pc", 100), metav1.CreateOptions{})
+			return err
+		}, "priorityclass"},
+		{func() error {
+			_, err := clientset.NetworkingV1().Ingresses(testNamespace).Create(context.TODO(), CreateTestIngress(testNamespace, "test-ingress", "test-service", "missing-secret", AppLabels), metav1.CreateOptions{})
+			return err
+		}, "ingress"},
+		{func() error {
+			_, err := clientset.CoreV1().Services(testNamespace).Create(context.TODO(), CreateTestService(testNamespace, "test-service"), metav1.CreateOptions{})
+			return err
+		}, "service"},
+	}
+	for _, object := range objects {
+		if err := object.create(); err != nil {
+			t.Fatalf("Error creating fake %s: %v", object.name, err)
+		}
+	}
+
+	return clientset
+}
+
+func graphEdgeKeys(graph *Graph) []string {
+	var keys []string
+	for _, edge := range graph.Edges {
+		keys = append(keys, edge.From+" -> "+edge.To)
+	}
+	return keys
+}
+
+func TestBuildGraph(t *testing.T) {
+	clients := Clients{Clientset: createTestGraph(t)}
+
+	graph, err := BuildGraph(context.TODO(), clients, &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Error building graph: %v", err)
+	}
+
+	expectedEdges := []string{
+		"Ingress/test-namespace/test-ingress -> Service/test-namespace/test-service",
+		"Pod/other-namespace/other-pod -> PriorityClass/test-pc",
+		"Pod/test-namespace/test-pod -> ConfigMap/test-namespace/used-cm",
+		"Pod/test-namespace/test-pod -> Pvc/test-namespace/test-pvc",
+		"Pv/test-pv -> StorageClass/test-sc",
+		"Pvc/test-namespace/test-pvc -> Pv/test-pv",
+		"Pvc/test-namespace/test-pvc -> StorageClass/test-sc",
+	}
+	if keys := graphEdgeKeys(graph); !reflect.DeepEqual(keys, expectedEdges) {
+		t.Errorf("Expected edges %v, got %v", expectedEdges, keys)
+	}
+	if field := graph.Edges[2].Field; field != "spec.containers[0].envFrom[0].configMapRef" {
+		t.Errorf("Expected the edge to used-cm from spec.containers[0].envFrom[0].configMapRef, got %s", field)
+	}
+
+	nodes := make(map[string]GraphNode)
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = node
+	}
+	if node := nodes["ConfigMap/test-namespace/unused-cm"]; !node.Unused || node.Reason == "" {
+		t.Errorf("Expected unused-cm to be an unused node, got %+v", node)
+	}
+	if node := nodes["ConfigMap/test-namespace/used-cm"]; node.Unused {
+		t.Errorf("Expected used-cm not to be unused, got %+v", node)
+	}
+	if _, ok := nodes["Secret/test-namespace/missing-secret"]; ok {
+		t.Error("Expected no node for a Secret that doesn't exist")
+	}
+}
+
+func TestBuildGraphNamespace(t *testing.T) {
+	clients := Clients{Clientset: createTestGraph(t)}
+
+	graph, err := BuildGraph(context.TODO(), clients, &filters.Options{IncludeNamespaces: []string{testNamespace}}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Error building graph: %v", err)
+	}
+	for _, key := range graphEdgeKeys(graph) {
+		if strings.Contains(key, "other-namespace") || strings.Contains(key, "PriorityClass") {
+			t.Errorf("Expected no edge from other namespaces, got %s", key)
+		}
+	}
+	if keys := graphEdgeKeys(graph); !strings.Contains(strings.Join(keys, "\n"), "Pv/test-pv -> StorageClass/test-sc") {
+		t.Errorf("Expected the StorageClass of the PV of the namespace, got %v", keys)
+	}
+}
+
+func TestBuildGraphListsOncePerKind(t *testing.T) {
+	clientset := createTestGraph(t)
+	clientset.ClearActions()
+
+	if _, err := BuildGraph(context.TODO(), Clients{Clientset: clientset}, &filters.Options{}, common.Opts{}); err != nil {
+		t.Fatalf("Error building graph: %v", err)
+	}
+	for _, resource := range []string{"pods", "configmaps", "persistentvolumeclaims", "ingresses"} {
+		if count := countListActions(clientset, resource); count != 1 {
+			t.Errorf("Expected %s to be listed once for the scan and the edges, got %d lists", resource, count)
+		}
+	}
+}
+
+func TestBuildGraphTimeout(t *testing.T) {
+	clients := Clients{Clientset: createTestGraph(t)}
+
+	graph, err := BuildGraph(context.TODO(), clients, &filters.Options{}, common.Opts{Timeout: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("Expected the graph found in time, got %v", err)
+	}
+	if !graph.Incomplete || len(graph.Errors) == 0 {
+		t.Errorf("Expected a graph cut short by the timeout to be incomplete with an error, got %+v", graph)
+	}
+}
+
+func TestFormatGraph(t *testing.T) {
+	graph := &Graph{
+		Nodes: []GraphNode{
+			{ID: "ConfigMap/test-namespace/test-cm", Kind: "ConfigMap", Namespace: testNamespace, Name: "test-cm", Unused: true, Reason: "ConfigMap is not used in any pod or container"},
+			{ID: "Pod/test-namespace/test-pod", Kind: "Pod", Namespace: testNamespace, Name: "test-pod"},
+		},
+		Edges: []GraphEdge{
+			{From: "Pod/test-namespace/test-pod", To: "ConfigMap/test-namespace/test-cm", Field: "spec.volumes[0].configMap"},
+		},
+	}
+
+	dot, err := FormatGraph(graph, "dot")
+	if err != nil {
+		t.Fatalf("Error formatting graph: %v", err)
+	}
+	expectedDot := `digraph kor {
+  rankdir=LR;
+  node [shape=box];
+  "ConfigMap/test-namespace/test-cm" [label="ConfigMap\ntest-namespace/test-cm", style=filled, fillcolor="#f8d7da", color="#dc3545", tooltip="ConfigMap is not used in any pod or container"];
+  "Pod/test-namespace/test-pod" [label="Pod\ntest-namespace/test-pod"];
+  "Pod/test-namespace/test-pod" -> "ConfigMap/test-namespace/test-cm" [label="spec.volumes[0].configMap"];
+}
+`
+	if dot != expectedDot {
+		t.Errorf("Expected dot:\n%s\ngot:\n%s", expectedDot, dot)
+	}
+
+	mermaid, err := FormatGraph(graph, "mermaid")
+	if err != nil {
+		t.Fatalf("Error formatting graph: %v", err)
+	}
+	expectedMermaid := `flowchart LR
+  n0["ConfigMap<br/>test-namespace/test-cm"]
+  n1["Pod<br/>test-namespace/test-pod"]
+  n1 -->|"spec.volumes[0].configMap"| n0
+  classDef unused fill:#f8d7da,stroke:#dc3545
+  class n0 unused
+`
+	if mermaid != expectedMermaid {
+		t.Errorf("Expected mermaid:\n%s\ngot:\n%s", expectedMermaid, mermaid)
+	}
+
+	if _, err := FormatGraph(graph, "table"); err == nil {
+		t.Error("Expected an error for an unsupported graph format")
+	}
+}
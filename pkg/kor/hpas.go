@@ -1,12 +1,10 @@
 package kor
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
 
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -16,8 +14,8 @@ import (
 	"github.com/yonahd/kor/pkg/filters"
 )
 
-func getDeploymentNames(clientset kubernetes.Interface, namespace string) ([]string, error) {
-	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+func getDeploymentNames(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, error) {
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -28,8 +26,8 @@ func getDeploymentNames(clientset kubernetes.Interface, namespace string) ([]str
 	return names, nil
 }
 
-func getStatefulSetNames(clientset kubernetes.Interface, namespace string) ([]string, error) {
-	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
+func getStatefulSetNames(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]string, error) {
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -40,18 +38,18 @@ func getStatefulSetNames(clientset kubernetes.Interface, namespace string) ([]st
 	return names, nil
 }
 
-func processNamespaceHpas(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	deploymentNames, err := getDeploymentNames(clientset, namespace)
+func processNamespaceHpas(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	deploymentNames, err := getDeploymentNames(ctx, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	statefulsetNames, err := getStatefulSetNames(clientset, namespace)
+	statefulsetNames, err := getStatefulSetNames(ctx, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	hpas, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	hpas, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -83,47 +81,36 @@ func processNamespaceHpas(clientset kubernetes.Interface, namespace string, filt
 			}
 		}
 	}
-	if opts.DeleteFlag {
-		if unusedHpas, err = DeleteResource(unusedHpas, clientset, namespace, "HPA", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete HPA %s in namespace %s: %v\n", unusedHpas, namespace, err)
-		}
-	}
 	return unusedHpas, nil
 }
 
-func GetUnusedHpas(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceHpas(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["Hpa"] = diff
-		case "resource":
-			appendResources(resources, "Hpa", namespace, diff)
-		}
+// retrieveHpaTargets references the workloads scaled by the HPAs of a
+// namespace, keyed by the kind of the workload
+func retrieveHpaTargets(ctx context.Context, clientset kubernetes.Interface, namespace string) (map[string]references, error) {
+	hpas, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list HPAs: %v", err)
 	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
+	used := map[string]references{}
+	for _, hpa := range hpas.Items {
+		target := hpa.Spec.ScaleTargetRef
+		if used[target.Kind] == nil {
+			used[target.Kind] = references{}
 		}
+		used[target.Kind].add(target.Name, "HorizontalPodAutoscaler", hpa.Namespace, hpa.Name, "spec.scaleTargetRef")
 	}
+	return used, nil
+}
 
-	unusedHpas, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+var hpaDetector = newDetector("Hpa", []string{"horizontalpodautoscaler", "hpa", "horizontalpodautoscalers"}, NamespaceScoped, namespacedDetect(processNamespaceHpas),
+	func(clients Clients, namespace string) typedClient[*autoscalingv2.HorizontalPodAutoscaler, *autoscalingv2.HorizontalPodAutoscalerList] {
+		return clients.Clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace)
+	})
 
-	return unusedHpas, nil
+func init() {
+	MustRegisterDetector(hpaDetector)
+}
+
+func GetUnusedHpas(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{hpaDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -103,7 +103,7 @@ func createTestHpasWithOwnerReferences(t *testing.T) *fake.Clientset {
 func TestExtractUnusedHpas(t *testing.T) {
 	clientset := createTestHpas(t)
 
-	unusedHpas, err := processNamespaceHpas(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	unusedHpas, err := processNamespaceHpas(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -121,7 +121,7 @@ func TestProcessNamespaceHpasWithOwnerReferences(t *testing.T) {
 	clientset := createTestHpasWithOwnerReferences(t)
 
 	// Test with --ignore-owner-references=false (default behavior)
-	unusedHpas, err := processNamespaceHpas(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	unusedHpas, err := processNamespaceHpas(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -132,7 +132,7 @@ func TestProcessNamespaceHpasWithOwnerReferences(t *testing.T) {
 	}
 
 	// Test with --ignore-owner-references=true
-	unusedHpas, err = processNamespaceHpas(clientset, testNamespace, &filters.Options{IgnoreOwnerReferences: true}, common.Opts{})
+	unusedHpas, err = processNamespaceHpas(context.TODO(), clientset, testNamespace, &filters.Options{IgnoreOwnerReferences: true}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -159,7 +159,7 @@ func TestGetUnusedHpasStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedHpas(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedHpas(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedHpasStructured: %v", err)
 	}
@@ -196,7 +196,7 @@ func TestGetUnusedHpasStructuredWithOwnerReferences(t *testing.T) {
 	}
 
 	// Test with --ignore-owner-references=true
-	output, err := GetUnusedHpas(&filters.Options{IgnoreOwnerReferences: true}, clientset, "json", opts)
+	output, err := GetUnusedHpas(context.TODO(), &filters.Options{IgnoreOwnerReferences: true}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedHpasStructured: %v", err)
 	}
@@ -1,11 +1,8 @@
 package kor
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
 
 	v1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,11 +13,11 @@ import (
 	"github.com/yonahd/kor/pkg/filters"
 )
 
-func validateServiceBackend(clientset kubernetes.Interface, namespace string, backend *v1.IngressBackend) bool {
+func validateServiceBackend(ctx context.Context, clientset kubernetes.Interface, namespace string, backend *v1.IngressBackend) bool {
 	if backend.Service != nil {
 		serviceName := backend.Service.Name
 
-		_, err := clientset.CoreV1().Services(namespace).Get(context.TODO(), serviceName, metav1.GetOptions{})
+		_, err := clientset.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
 		if err != nil {
 			return false
 		}
@@ -28,8 +25,8 @@ func validateServiceBackend(clientset kubernetes.Interface, namespace string, ba
 	return true
 }
 
-func retrieveUsedIngress(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, error) {
-	ingresses, err := clientset.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func retrieveUsedIngress(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, error) {
+	ingresses, err := clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +41,7 @@ func retrieveUsedIngress(clientset kubernetes.Interface, namespace string, filte
 		used := true
 
 		if ingress.Spec.DefaultBackend != nil {
-			used = validateServiceBackend(clientset, namespace, ingress.Spec.DefaultBackend)
+			used = validateServiceBackend(ctx, clientset, namespace, ingress.Spec.DefaultBackend)
 		}
 		for _, rule := range ingress.Spec.Rules {
 			if rule.HTTP == nil {
@@ -52,7 +49,7 @@ func retrieveUsedIngress(clientset kubernetes.Interface, namespace string, filte
 				break
 			}
 			for _, path := range rule.HTTP.Paths {
-				used = validateServiceBackend(clientset, namespace, &path.Backend)
+				used = validateServiceBackend(ctx, clientset, namespace, &path.Backend)
 				if used {
 					break
 				}
@@ -68,8 +65,8 @@ func retrieveUsedIngress(clientset kubernetes.Interface, namespace string, filte
 	return usedIngresses, nil
 }
 
-func retrieveIngressNames(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
-	ingresses, err := clientset.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func retrieveIngressNames(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
+	ingresses, err := clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -96,12 +93,12 @@ func retrieveIngressNames(clientset kubernetes.Interface, namespace string, filt
 	return names, unusedIngressNames, nil
 }
 
-func processNamespaceIngresses(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	usedIngresses, err := retrieveUsedIngress(clientset, namespace, filterOpts)
+func processNamespaceIngresses(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	usedIngresses, err := retrieveUsedIngress(ctx, clientset, namespace, filterOpts)
 	if err != nil {
 		return nil, err
 	}
-	ingressNames, unusedIngressNames, err := retrieveIngressNames(clientset, namespace, filterOpts)
+	ingressNames, unusedIngressNames, err := retrieveIngressNames(ctx, clientset, namespace, filterOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -117,48 +114,44 @@ func processNamespaceIngresses(clientset kubernetes.Interface, namespace string,
 		reason := "Marked with unused label"
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
-	if opts.DeleteFlag {
-		if diff, err = DeleteResource(diff, clientset, namespace, "Ingress", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete Ingress %s in namespace %s: %v\n", diff, namespace, err)
-		}
-	}
 	return diff, nil
 
 }
 
-func GetUnusedIngresses(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceIngresses(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
+// retrieveIngressBackends references the Services the Ingresses of a namespace route to
+func retrieveIngressBackends(ctx context.Context, clientset kubernetes.Interface, namespace string) (references, error) {
+	ingresses, err := clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Ingresses: %v", err)
+	}
+	used := references{}
+	for _, ingress := range ingresses.Items {
+		if backend := ingress.Spec.DefaultBackend; backend != nil && backend.Service != nil {
+			used.add(backend.Service.Name, "Ingress", ingress.Namespace, ingress.Name, "spec.defaultBackend.service")
 		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["Ingress"] = diff
-		case "resource":
-			appendResources(resources, "Ingress", namespace, diff)
+		for i, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for j, path := range rule.HTTP.Paths {
+				if path.Backend.Service != nil {
+					used.add(path.Backend.Service.Name, "Ingress", ingress.Namespace, ingress.Name, fmt.Sprintf("spec.rules[%d].http.paths[%d].backend.service", i, j))
+				}
+			}
 		}
 	}
+	return used, nil
+}
 
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var ingressDetector = newDetector("Ingress", []string{"ingress", "ing", "ingresses"}, NamespaceScoped, namespacedDetect(processNamespaceIngresses),
+	func(clients Clients, namespace string) typedClient[*v1.Ingress, *v1.IngressList] {
+		return clients.Clientset.NetworkingV1().Ingresses(namespace)
+	})
 
-	unusedIngresses, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(ingressDetector)
+}
 
-	return unusedIngresses, nil
+func GetUnusedIngresses(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{ingressDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
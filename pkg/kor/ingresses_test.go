@@ -101,7 +101,7 @@ func createTestIngressesWithOwnerReferences(t *testing.T) *fake.Clientset {
 func TestRetrieveUsedIngress(t *testing.T) {
 	clientset := createTestIngresses(t)
 
-	usedIngresses, err := retrieveUsedIngress(clientset, testNamespace, &filters.Options{})
+	usedIngresses, err := retrieveUsedIngress(context.TODO(), clientset, testNamespace, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -119,7 +119,7 @@ func TestProcessNamespaceIngressesWithOwnerReferences(t *testing.T) {
 	clientset := createTestIngressesWithOwnerReferences(t)
 
 	// Test with --ignore-owner-references=false (default behavior)
-	unusedIngresses, err := processNamespaceIngresses(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	unusedIngresses, err := processNamespaceIngresses(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -130,7 +130,7 @@ func TestProcessNamespaceIngressesWithOwnerReferences(t *testing.T) {
 	}
 
 	// Test with --ignore-owner-references=true
-	unusedIngresses, err = processNamespaceIngresses(clientset, testNamespace, &filters.Options{IgnoreOwnerReferences: true}, common.Opts{})
+	unusedIngresses, err = processNamespaceIngresses(context.TODO(), clientset, testNamespace, &filters.Options{IgnoreOwnerReferences: true}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -157,7 +157,7 @@ func TestGetUnusedIngressesStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedIngresses(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedIngresses(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedIngressesStructured: %v", err)
 	}
@@ -194,7 +194,7 @@ func TestGetUnusedIngressesStructuredWithOwnerReferences(t *testing.T) {
 	}
 
 	// Test with --ignore-owner-references=true
-	output, err := GetUnusedIngresses(&filters.Options{IgnoreOwnerReferences: true}, clientset, "json", opts)
+	output, err := GetUnusedIngresses(context.TODO(), &filters.Options{IgnoreOwnerReferences: true}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedIngressesStructured: %v", err)
 	}
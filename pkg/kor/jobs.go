@@ -1,12 +1,8 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
-	"fmt"
-	"os"
 	"slices"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -20,8 +16,8 @@ import (
 //go:embed exceptions/jobs/jobs.json
 var jobsConfig []byte
 
-func processNamespaceJobs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	jobsList, err := clientset.BatchV1().Jobs(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespaceJobs(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	jobsList, err := clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -80,48 +76,18 @@ func processNamespaceJobs(clientset kubernetes.Interface, namespace string, filt
 		}
 	}
 
-	if opts.DeleteFlag {
-		if unusedJobNames, err = DeleteResource(unusedJobNames, clientset, namespace, "Job", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete Job %s in namespace %s: %v\n", unusedJobNames, namespace, err)
-		}
-	}
-
 	return unusedJobNames, nil
 }
 
-func GetUnusedJobs(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceJobs(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["Job"] = diff
-		case "resource":
-			appendResources(resources, "Job", namespace, diff)
-		}
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var jobDetector = newDetector("Job", []string{"job", "jobs"}, NamespaceScoped, namespacedDetect(processNamespaceJobs),
+	func(clients Clients, namespace string) typedClient[*batchv1.Job, *batchv1.JobList] {
+		return clients.Clientset.BatchV1().Jobs(namespace)
+	})
 
-	unusedJobs, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(jobDetector)
+}
 
-	return unusedJobs, nil
+func GetUnusedJobs(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{jobDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
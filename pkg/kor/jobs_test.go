@@ -150,7 +150,7 @@ func createTestJobs(t *testing.T) *fake.Clientset {
 func TestProcessNamespaceJobs(t *testing.T) {
 	clientset := createTestJobs(t)
 
-	unusedJobs, err := processNamespaceJobs(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	unusedJobs, err := processNamespaceJobs(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -180,7 +180,7 @@ func TestGetUnusedJobsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedJobs(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedJobs(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedJobsStructured: %v", err)
 	}
@@ -253,7 +253,7 @@ func TestFilterCronJobOwnedJobs(t *testing.T) {
 
 	// Test without filter - should return both (both are completed)
 	filterOptsNoSkip := &filters.Options{IgnoreOwnerReferences: false}
-	unusedWithoutFilter, err := processNamespaceJobs(clientset, testNamespace, filterOptsNoSkip, common.Opts{})
+	unusedWithoutFilter, err := processNamespaceJobs(context.TODO(), clientset, testNamespace, filterOptsNoSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused jobs: %v", err)
 	}
@@ -264,7 +264,7 @@ func TestFilterCronJobOwnedJobs(t *testing.T) {
 
 	// Test with filter - should return only standalone
 	filterOptsWithSkip := &filters.Options{IgnoreOwnerReferences: true}
-	unusedWithFilter, err := processNamespaceJobs(clientset, testNamespace, filterOptsWithSkip, common.Opts{})
+	unusedWithFilter, err := processNamespaceJobs(context.TODO(), clientset, testNamespace, filterOptsWithSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused jobs: %v", err)
 	}
@@ -1,14 +1,17 @@
 package kor
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strings"
 
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -18,6 +21,9 @@ import (
 
 var ResourceKindList map[string]ResourceKind
 
+// serviceAccountTokenPath is the token mounted in the pods kor runs in
+const serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
 type ExceptionResource struct {
 	Namespace    string
 	ResourceName string
@@ -44,6 +50,8 @@ type Config struct {
 	ExceptionServices            []ExceptionResource `json:"exceptionServices"`
 	ExceptionStorageClasses      []ExceptionResource `json:"exceptionStorageClasses"`
 	ExceptionJobs                []ExceptionResource `json:"exceptionJobs"`
+	ExceptionNamespaces          []ExceptionResource `json:"exceptionNamespaces"`
+	ExceptionCronJobs            []ExceptionResource `json:"exceptionCronJobs"`
 	ExceptionPdbs                []ExceptionResource `json:"exceptionPdbs"`
 	ExceptionRoleBindings        []ExceptionResource `json:"exceptionRoleBindings"`
 	ExceptionPriorityClasses     []ExceptionResource `json:"exceptionPriorityClasses"`
@@ -68,11 +76,63 @@ func GetKubeConfigPath() string {
 	return filepath.Join(home, ".kube", "config")
 }
 
+// ClientConfig is how kor connects to the cluster. QPS and Burst are the
+// client-side rate limits, zero values keeping the client-go defaults.
+type ClientConfig struct {
+	Kubeconfig string
+	QPS        float32
+	Burst      int
+}
+
+// RESTConfig loads the in-cluster config when kor runs in a pod, or else the
+// kubeconfig, with the rate limits applied
+func (c ClientConfig) RESTConfig() (*rest.Config, error) {
+	config, err := loadConfig(c.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if c.QPS > 0 {
+		config.QPS = c.QPS
+	}
+	if c.Burst > 0 {
+		config.Burst = c.Burst
+	}
+	return config, nil
+}
+
+// NewClients builds the clientset, API extensions and dynamic clients
+func (c ClientConfig) NewClients() (Clients, error) {
+	config, err := c.RESTConfig()
+	if err != nil {
+		return Clients{}, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return Clients{}, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	apiExtClient, err := apiextensionsclientset.NewForConfig(config)
+	if err != nil {
+		return Clients{}, fmt.Errorf("failed to create API extensions client: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return Clients{}, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	return Clients{Clientset: clientset, APIExtensions: apiExtClient, Dynamic: dynamicClient}, nil
+}
+
 func GetConfig(kubeconfig string) (*rest.Config, error) {
-	if _, err := os.Stat("/var/run/secrets/kubernetes.io/serviceaccount/token"); err == nil {
+	return ClientConfig{Kubeconfig: kubeconfig}.RESTConfig()
+}
+
+func loadConfig(kubeconfig string) (*rest.Config, error) {
+	if _, err := os.Stat(serviceAccountTokenPath); err == nil {
 		return rest.InClusterConfig()
 	}
+	return kubeClientConfig(kubeconfig).ClientConfig()
+}
 
+func kubeClientConfig(kubeconfig string) clientcmd.ClientConfig {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
 
 	if kubeconfig != "" {
@@ -80,54 +140,83 @@ func GetConfig(kubeconfig string) (*rest.Config, error) {
 	}
 
 	configOverrides := &clientcmd.ConfigOverrides{}
-	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+}
+
+// GetKubeUser returns who kor runs as, for the audit log: the service account
+// of the pod when running in a cluster, or else the user of the current
+// kubeconfig context. It is empty when neither is known.
+func GetKubeUser(kubeconfig string) string {
+	if token, err := os.ReadFile(serviceAccountTokenPath); err == nil {
+		return tokenSubject(string(token))
+	}
+	rawConfig, err := kubeClientConfig(kubeconfig).RawConfig()
+	if err != nil {
+		return ""
+	}
+	if context, ok := rawConfig.Contexts[rawConfig.CurrentContext]; ok {
+		return context.AuthInfo
+	}
+	return ""
+}
 
-	return kubeConfig.ClientConfig()
+// tokenSubject returns the subject of a service account token, such as
+// system:serviceaccount:kor:kor. The token is not verified.
+func tokenSubject(token string) string {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	return claims.Subject
 }
 
-func GetKubeClient(kubeconfig string) *kubernetes.Clientset {
+func GetKubeClient(kubeconfig string) (*kubernetes.Clientset, error) {
 	config, err := GetConfig(kubeconfig)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load kubeconfig: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create Kubernetes client: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
 	}
-	return clientset
+	return clientset, nil
 }
 
-func GetAPIExtensionsClient(kubeconfig string) *apiextensionsclientset.Clientset {
+func GetAPIExtensionsClient(kubeconfig string) (*apiextensionsclientset.Clientset, error) {
 	config, err := GetConfig(kubeconfig)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load kubeconfig: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
 	clientset, err := apiextensionsclientset.NewForConfig(config)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create Kubernetes client: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to create API extensions client: %w", err)
 	}
-	return clientset
+	return clientset, nil
 }
 
-func GetDynamicClient(kubeconfig string) *dynamic.DynamicClient {
+func GetDynamicClient(kubeconfig string) (*dynamic.DynamicClient, error) {
 	config, err := GetConfig(kubeconfig)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load kubeconfig: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
 	clientset, err := dynamic.NewForConfig(config)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create Kubernetes client: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
 	}
-	return clientset
+	return clientset, nil
 }
 
 // TODO create formatter by resource "#", "Resource Name", "Namespace"
@@ -215,10 +304,18 @@ func convertNamesToPresenseMap(names []string, _ []string, err error) (map[strin
 	return namesMap, nil
 }
 
+// GetResourceKinds returns the resource kinds served by the cluster, keyed by
+// their singular name. The groups that can't be discovered, such as the ones
+// of an unavailable APIService, are left out: the kinds of the others are
+// returned along with an error listing them.
 func GetResourceKinds(clientset kubernetes.Interface) (map[string]ResourceKind, error) {
 	resourceTypes, err := clientset.Discovery().ServerPreferredResources()
+	var discoveryErr error
 	if err != nil {
-		return nil, fmt.Errorf("error fetching server resources: %v", err)
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, fmt.Errorf("error fetching server resources: %v", err)
+		}
+		discoveryErr = fmt.Errorf("failed to fetch some server resources: %w", err)
 	}
 
 	kinds := make(map[string]ResourceKind)
@@ -243,5 +340,5 @@ func GetResourceKinds(clientset kubernetes.Interface) (map[string]ResourceKind,
 		}
 	}
 
-	return kinds, nil
+	return kinds, discoveryErr
 }
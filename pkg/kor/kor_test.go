@@ -1,9 +1,19 @@
 package kor
 
 import (
+	"encoding/base64"
+	"errors"
 	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func stringSlicesEqual(a, b []string) bool {
@@ -110,9 +120,9 @@ func TestGetKubeClientFromEnvVar(t *testing.T) {
 		return
 	}
 
-	kcs := GetKubeClient("")
-	if kcs == nil {
-		t.Errorf("Expected valid clientSet")
+	kcs, err := GetKubeClient("")
+	if err != nil || kcs == nil {
+		t.Errorf("Expected valid clientSet, got error %v", err)
 	}
 }
 
@@ -150,9 +160,41 @@ func TestGetKubeClientFromInput(t *testing.T) {
 		}
 	}()
 
-	kcs := GetKubeClient(configFile.Name())
-	if kcs == nil {
-		t.Errorf("Expected valid clientSet")
+	kcs, err := GetKubeClient(configFile.Name())
+	if err != nil || kcs == nil {
+		t.Errorf("Expected valid clientSet, got error %v", err)
+	}
+}
+
+func TestClientConfigRateLimits(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(configFile, []byte(getFakeConfigContent()), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := ClientConfig{Kubeconfig: configFile, QPS: 50, Burst: 100}.RESTConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.QPS != 50 || config.Burst != 100 {
+		t.Errorf("Expected QPS 50 and burst 100, got %v and %d", config.QPS, config.Burst)
+	}
+
+	// the rate limits of one config don't leak into the others
+	config, err = GetConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.QPS != 0 || config.Burst != 0 {
+		t.Errorf("Expected the client-go default rate limits, got QPS %v and burst %d", config.QPS, config.Burst)
+	}
+
+	clients, err := ClientConfig{Kubeconfig: configFile, QPS: 50, Burst: 100}.NewClients()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clients.Clientset == nil || clients.APIExtensions == nil || clients.Dynamic == nil {
+		t.Errorf("Expected every client to be built, got %+v", clients)
 	}
 }
 
@@ -223,3 +265,65 @@ func TestResourceExceptionWithRegexPrefixInNamespace(t *testing.T) {
 		t.Error("Expected to find exception")
 	}
 }
+
+func TestGetKubeUser(t *testing.T) {
+	if _, err := os.Stat(serviceAccountTokenPath); err == nil {
+		t.Skip("running in a cluster, the service account takes precedence")
+	}
+	kubeconfig := t.TempDir() + "/kubeconfig"
+	content := getFakeConfigContent() + `users:
+- name: alice
+  user:
+    token: secret
+`
+	content = strings.Replace(content, "    namespace: bar\n", "    namespace: bar\n    user: alice\n", 1)
+	if err := os.WriteFile(kubeconfig, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if user := GetKubeUser(kubeconfig); user != "alice" {
+		t.Errorf("Expected alice, got %q", user)
+	}
+}
+
+func TestTokenSubject(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"kubernetes/serviceaccount","sub":"system:serviceaccount:kor:kor"}`))
+	if subject := tokenSubject("header." + payload + ".signature\n"); subject != "system:serviceaccount:kor:kor" {
+		t.Errorf("Expected system:serviceaccount:kor:kor, got %q", subject)
+	}
+	if subject := tokenSubject("not-a-token"); subject != "" {
+		t.Errorf("Expected no subject, got %q", subject)
+	}
+}
+
+// partialDiscovery fails to discover the metrics.k8s.io group, as when its
+// APIService is unavailable
+type partialDiscovery struct {
+	*fakediscovery.FakeDiscovery
+}
+
+func (d *partialDiscovery) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	return []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "configmaps", SingularName: "configmap", ShortNames: []string{"cm"}}},
+	}}, &discovery.ErrGroupDiscoveryFailed{Groups: map[schema.GroupVersion]error{
+		{Group: "metrics.k8s.io", Version: "v1beta1"}: errors.New("the server is currently unable to handle the request"),
+	}}
+}
+
+type partialDiscoveryClientset struct {
+	*fake.Clientset
+}
+
+func (c *partialDiscoveryClientset) Discovery() discovery.DiscoveryInterface {
+	return &partialDiscovery{c.Clientset.Discovery().(*fakediscovery.FakeDiscovery)}
+}
+
+func TestGetResourceKindsPartialDiscovery(t *testing.T) {
+	kinds, err := GetResourceKinds(&partialDiscoveryClientset{fake.NewClientset()})
+	if !discovery.IsGroupDiscoveryFailedError(errors.Unwrap(err)) || !strings.Contains(err.Error(), "metrics.k8s.io/v1beta1") {
+		t.Errorf("Expected the failed group in the error, got %v", err)
+	}
+	if kind := kinds["configmap"]; kind.Plural != "configmaps" || !stringSlicesEqual(kind.ShortNames, []string{"cm"}) {
+		t.Errorf("Expected the configmap kind, got %+v", kind)
+	}
+}
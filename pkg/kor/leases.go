@@ -0,0 +1,105 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// DefaultLeaseStaleAge is how long a Lease may go without being renewed
+// before it is reported as unused. Leader election renews its Leases every
+// few seconds.
+const DefaultLeaseStaleAge = 24 * time.Hour
+
+// leaseRenewTime returns the last time a Lease was renewed or acquired, or
+// created when it never was
+func leaseRenewTime(lease coordinationv1.Lease) time.Time {
+	switch {
+	case lease.Spec.RenewTime != nil:
+		return lease.Spec.RenewTime.Time
+	case lease.Spec.AcquireTime != nil:
+		return lease.Spec.AcquireTime.Time
+	}
+	return lease.CreationTimestamp.Time
+}
+
+func processNamespaceLeases(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	leaseList, err := clientset.CoordinationV1().Leases(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	// the heartbeat Leases of kube-node-lease are named after their node
+	var nodeNames map[string]bool
+	if namespace == corev1.NamespaceNodeLease {
+		nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list nodes: %v", err)
+		}
+		nodeNames = make(map[string]bool, len(nodes.Items))
+		for _, node := range nodes.Items {
+			nodeNames[node.Name] = true
+		}
+	}
+
+	staleAge := opts.LeaseStaleAge
+	if staleAge == 0 {
+		staleAge = DefaultLeaseStaleAge
+	}
+
+	var unusedLeases []ResourceInfo
+
+	for _, lease := range leaseList.Items {
+		// Skip resources with ownerReferences if the general flag is set
+		if filterOpts.IgnoreOwnerReferences && len(lease.OwnerReferences) > 0 {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&lease).Run(filterOpts); pass {
+			continue
+		}
+
+		if lease.Labels["kor/used"] == "false" {
+			reason := "Marked with unused label"
+			unusedLeases = append(unusedLeases, ResourceInfo{Name: lease.Name, Reason: reason})
+			continue
+		}
+
+		if nodeNames != nil {
+			if !nodeNames[lease.Name] {
+				reason := fmt.Sprintf("Node %s does not exist", lease.Name)
+				unusedLeases = append(unusedLeases, ResourceInfo{Name: lease.Name, Reason: reason})
+			}
+			continue
+		}
+
+		if age := time.Since(leaseRenewTime(lease)); age > staleAge {
+			reason := fmt.Sprintf("Lease has not been renewed for %s", duration.HumanDuration(age))
+			unusedLeases = append(unusedLeases, ResourceInfo{Name: lease.Name, Reason: reason})
+		}
+	}
+
+	return unusedLeases, nil
+}
+
+var leaseDetector = newDetector("Lease", []string{"lease", "leases"}, NamespaceScoped, namespacedDetect(processNamespaceLeases),
+	func(clients Clients, namespace string) typedClient[*coordinationv1.Lease, *coordinationv1.LeaseList] {
+		return clients.Clientset.CoordinationV1().Leases(namespace)
+	})
+
+func init() {
+	MustRegisterDetector(leaseDetector)
+}
+
+func GetUnusedLeases(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{leaseDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
+}
@@ -0,0 +1,123 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestLeases(t *testing.T) *fake.Clientset {
+	clientset := fake.NewClientset()
+
+	for _, namespace := range []string{testNamespace, corev1.NamespaceNodeLease} {
+		_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(namespace, AppLabels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating namespace %s: %v", namespace, err)
+		}
+	}
+
+	_, err := clientset.CoreV1().Nodes().Create(context.TODO(), CreateTestNode("node-1"), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating node: %v", err)
+	}
+
+	leases := []struct {
+		namespace string
+		name      string
+		renewTime time.Time
+	}{
+		{testNamespace, "active-controller", time.Now().Add(-10 * time.Second)},
+		{testNamespace, "uninstalled-controller", time.Now().Add(-72 * time.Hour)},
+		{testNamespace, "recent-controller", time.Now().Add(-2 * time.Hour)},
+		// node heartbeats are checked against the nodes, not their renew time
+		{corev1.NamespaceNodeLease, "node-1", time.Now().Add(-72 * time.Hour)},
+		{corev1.NamespaceNodeLease, "node-2", time.Now().Add(-10 * time.Second)},
+	}
+	for _, lease := range leases {
+		_, err = clientset.CoordinationV1().Leases(lease.namespace).Create(context.TODO(), CreateTestLease(lease.namespace, lease.name, lease.renewTime), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake lease %s: %v", lease.name, err)
+		}
+	}
+
+	return clientset
+}
+
+func TestProcessNamespaceLeases(t *testing.T) {
+	clientset := createTestLeases(t)
+
+	unused, err := processNamespaceLeases(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Error retrieving unused leases: %v", err)
+	}
+	expected := []ResourceInfo{{Name: "uninstalled-controller", Reason: "Lease has not been renewed for 3d"}}
+	if !reflect.DeepEqual(unused, expected) {
+		t.Errorf("Expected unused leases %v, got %v", expected, unused)
+	}
+
+	unused, err = processNamespaceLeases(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{LeaseStaleAge: time.Hour})
+	if err != nil {
+		t.Fatalf("Error retrieving unused leases: %v", err)
+	}
+	if len(unused) != 2 {
+		t.Errorf("Expected 2 leases not renewed for an hour, got %v", unused)
+	}
+
+	unused, err = processNamespaceLeases(context.TODO(), clientset, corev1.NamespaceNodeLease, &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Error retrieving unused leases: %v", err)
+	}
+	expected = []ResourceInfo{{Name: "node-2", Reason: "Node node-2 does not exist"}}
+	if !reflect.DeepEqual(unused, expected) {
+		t.Errorf("Expected unused node leases %v, got %v", expected, unused)
+	}
+}
+
+func TestGetUnusedLeasesStructured(t *testing.T) {
+	clientset := createTestLeases(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedLeases(context.TODO(), &filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedLeasesStructured: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"Lease": {"uninstalled-controller"},
+		},
+		corev1.NamespaceNodeLease: {
+			"Lease": {"node-2"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+	for namespace := range actualOutput {
+		sort.Strings(actualOutput[namespace]["Lease"])
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output %+v, but got %+v", expectedOutput, actualOutput)
+	}
+}
@@ -0,0 +1,164 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// limitRangeItem returns the limits of a type set by a LimitRange
+func limitRangeItem(limitRange corev1.LimitRange, limitType corev1.LimitType) (corev1.LimitRangeItem, bool) {
+	for _, item := range limitRange.Spec.Limits {
+		if item.Type == limitType {
+			return item, true
+		}
+	}
+	return corev1.LimitRangeItem{}, false
+}
+
+// limitRangeField is a field of a LimitRange item setting a value per resource
+type limitRangeField struct {
+	name   string
+	values corev1.ResourceList
+}
+
+// limitRangeFields returns the fields of a LimitRange item setting a value
+// per resource
+func limitRangeFields(item corev1.LimitRangeItem) []limitRangeField {
+	return []limitRangeField{
+		{"default", item.Default},
+		{"defaultRequest", item.DefaultRequest},
+		{"min", item.Min},
+		{"max", item.Max},
+		{"maxLimitRequestRatio", item.MaxLimitRequestRatio},
+	}
+}
+
+// limitRangeConflict returns the first field and resource two LimitRange
+// items of the same type both set to different values. Resources set by only
+// one of them don't conflict.
+func limitRangeConflict(item, other corev1.LimitRangeItem) (string, corev1.ResourceName, bool) {
+	otherFields := limitRangeFields(other)
+	for i, field := range limitRangeFields(item) {
+		for _, resource := range slices.Sorted(maps.Keys(field.values)) {
+			otherValue, ok := otherFields[i].values[resource]
+			if ok && otherValue.Cmp(field.values[resource]) != 0 {
+				return field.name, resource, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// limitRangeOverlap returns why a LimitRange is redundant with one created
+// before it in the same namespace, or an empty string when none is. It
+// conflicts with one setting a value of the same type and resource
+// differently, as which one applies to a container is left undefined, and
+// duplicates one setting the same limits for every type it sets.
+func limitRangeOverlap(limitRange corev1.LimitRange, limitRanges []corev1.LimitRange) string {
+	for _, other := range limitRanges {
+		if other.Name == limitRange.Name {
+			// limitRanges is sorted, the next ones were created after it
+			return ""
+		}
+		duplicate := true
+		for _, item := range limitRange.Spec.Limits {
+			otherItem, ok := limitRangeItem(other, item.Type)
+			if !ok {
+				duplicate = false
+				continue
+			}
+			if field, resource, ok := limitRangeConflict(item, otherItem); ok {
+				return fmt.Sprintf("LimitRange conflicts with the %s %s %s of LimitRange %s", item.Type, field, resource, other.Name)
+			}
+			if !equality.Semantic.DeepEqual(item, otherItem) {
+				duplicate = false
+			}
+		}
+		if duplicate {
+			return fmt.Sprintf("LimitRange duplicates the limits of LimitRange %s", other.Name)
+		}
+	}
+	return ""
+}
+
+func processNamespaceLimitRanges(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	// the overlaps are checked against all the LimitRanges of the namespace,
+	// so they are listed without the --include-labels selector
+	limitRangeList, err := clientset.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	selector, err := labels.Parse(filterOpts.IncludeLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	// the oldest LimitRange of overlapping ones is kept
+	limitRanges := limitRangeList.Items
+	slices.SortFunc(limitRanges, func(a, b corev1.LimitRange) int {
+		if c := a.CreationTimestamp.Compare(b.CreationTimestamp.Time); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	var unusedLimitRanges []ResourceInfo
+
+	for _, limitRange := range limitRanges {
+		if !selector.Matches(labels.Set(limitRange.Labels)) {
+			continue
+		}
+
+		// Skip resources with ownerReferences if the general flag is set
+		if filterOpts.IgnoreOwnerReferences && len(limitRange.OwnerReferences) > 0 {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&limitRange).Run(filterOpts); pass {
+			continue
+		}
+
+		if limitRange.Labels["kor/used"] == "false" {
+			reason := "Marked with unused label"
+			unusedLimitRanges = append(unusedLimitRanges, ResourceInfo{Name: limitRange.Name, Reason: reason})
+			continue
+		}
+
+		if len(limitRange.Spec.Limits) == 0 {
+			reason := "LimitRange has no limits"
+			unusedLimitRanges = append(unusedLimitRanges, ResourceInfo{Name: limitRange.Name, Reason: reason})
+			continue
+		}
+
+		if reason := limitRangeOverlap(limitRange, limitRanges); reason != "" {
+			unusedLimitRanges = append(unusedLimitRanges, ResourceInfo{Name: limitRange.Name, Reason: reason})
+		}
+	}
+
+	return unusedLimitRanges, nil
+}
+
+var limitRangeDetector = newDetector("LimitRange", []string{"limitrange", "limits", "limitranges"}, NamespaceScoped, namespacedDetect(processNamespaceLimitRanges),
+	func(clients Clients, namespace string) typedClient[*corev1.LimitRange, *corev1.LimitRangeList] {
+		return clients.Clientset.CoreV1().LimitRanges(namespace)
+	})
+
+func init() {
+	MustRegisterDetector(limitRangeDetector)
+}
+
+func GetUnusedLimitRanges(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{limitRangeDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
+}
@@ -0,0 +1,119 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestLimitRanges(t *testing.T) *fake.Clientset {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	containerLimits := func(cpu string) corev1.LimitRangeItem {
+		return corev1.LimitRangeItem{
+			Type:    corev1.LimitTypeContainer,
+			Default: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+		}
+	}
+	pvcLimits := corev1.LimitRangeItem{
+		Type: corev1.LimitTypePersistentVolumeClaim,
+		Max:  corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+	}
+	created := time.Now().Add(-time.Hour)
+	at := func(minutes int) v1.Time {
+		return v1.NewTime(created.Add(time.Duration(minutes) * time.Minute))
+	}
+
+	limitRanges := []*corev1.LimitRange{
+		CreateTestLimitRange(testNamespace, "defaults", at(0), containerLimits("500m")),
+		CreateTestLimitRange(testNamespace, "storage", at(1), pvcLimits),
+		CreateTestLimitRange(testNamespace, "defaults-copy", at(2), containerLimits("0.5")),
+		CreateTestLimitRange(testNamespace, "defaults-other", at(3), containerLimits("1")),
+		CreateTestLimitRange(testNamespace, "empty", at(4)),
+		// only sets what the others don't
+		CreateTestLimitRange(testNamespace, "default-requests", at(5), corev1.LimitRangeItem{
+			Type:           corev1.LimitTypeContainer,
+			DefaultRequest: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+		}),
+		CreateTestLimitRange(testNamespace, "memory-defaults", at(6), corev1.LimitRangeItem{
+			Type:    corev1.LimitTypeContainer,
+			Default: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+		}),
+	}
+	for _, limitRange := range limitRanges {
+		_, err = clientset.CoreV1().LimitRanges(testNamespace).Create(context.TODO(), limitRange, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake LimitRange %s: %v", limitRange.Name, err)
+		}
+	}
+
+	return clientset
+}
+
+func TestProcessNamespaceLimitRanges(t *testing.T) {
+	clientset := createTestLimitRanges(t)
+
+	unused, err := processNamespaceLimitRanges(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Error retrieving unused LimitRanges: %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "defaults-copy", Reason: "LimitRange duplicates the limits of LimitRange defaults"},
+		{Name: "defaults-other", Reason: "LimitRange conflicts with the Container default cpu of LimitRange defaults"},
+		{Name: "empty", Reason: "LimitRange has no limits"},
+	}
+	if !reflect.DeepEqual(unused, expected) {
+		t.Errorf("Expected unused LimitRanges %v, got %v", expected, unused)
+	}
+}
+
+func TestGetUnusedLimitRangesStructured(t *testing.T) {
+	clientset := createTestLimitRanges(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedLimitRanges(context.TODO(), &filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedLimitRangesStructured: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"LimitRange": {"defaults-copy", "defaults-other", "empty"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+	sort.Strings(actualOutput[testNamespace]["LimitRange"])
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
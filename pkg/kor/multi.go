@@ -1,13 +1,17 @@
 package kor
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
@@ -36,170 +40,267 @@ func getCanonicalResourceType(resourceName string) string {
 	return resourceName
 }
 
-func retrieveNoNamespaceDiff(clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, resourceList []string, filterOpts *filters.Options, opts common.Opts) ([]ResourceDiff, []string) {
-	var noNamespaceDiff []ResourceDiff
-	markedForRemoval := make([]bool, len(resourceList))
-	updatedResourceList := resourceList
-
-	for counter, resource := range resourceList {
-		canonicalType := getCanonicalResourceType(resource)
-		switch canonicalType {
-		case "customresourcedefinition":
-			crdDiff := getUnusedCrds(apiExtClient, dynamicClient, filterOpts)
-			noNamespaceDiff = append(noNamespaceDiff, crdDiff)
-			markedForRemoval[counter] = true
-		case "persistentvolume":
-			pvDiff := getUnusedPvs(clientset, filterOpts)
-			noNamespaceDiff = append(noNamespaceDiff, pvDiff)
-			markedForRemoval[counter] = true
-		case "clusterrole":
-			clusterRoleDiff := getUnusedClusterRoles(clientset, filterOpts)
-			noNamespaceDiff = append(noNamespaceDiff, clusterRoleDiff)
-			markedForRemoval[counter] = true
-		case "clusterrolebinding":
-			clusterRoleBindingDiff := getUnusedClusterRoleBindings(clientset, filterOpts, opts)
-			noNamespaceDiff = append(noNamespaceDiff, clusterRoleBindingDiff)
-			markedForRemoval[counter] = true
-		case "storageclass":
-			storageClassDiff := getUnusedStorageClasses(clientset, filterOpts)
-			noNamespaceDiff = append(noNamespaceDiff, storageClassDiff)
-			markedForRemoval[counter] = true
-		case "volumeattachment":
-			vattsDiff := getUnusedVolumeAttachments(clientset, filterOpts)
-			noNamespaceDiff = append(noNamespaceDiff, vattsDiff)
-			markedForRemoval[counter] = true
-		case "priorityclass":
-			pcDiff := getUnusedPriorityClasses(clientset, filterOpts)
-			noNamespaceDiff = append(noNamespaceDiff, pcDiff)
-			markedForRemoval[counter] = true
+// resolveDetectors maps the requested resource names to their detectors,
+// keeping the requested order. The unsupported names are joined in the
+// returned error.
+func resolveDetectors(resourceList []string) ([]Detector, error) {
+	var resolved []Detector
+	var errs []error
+	for _, resource := range resourceList {
+		detector, ok := LookupDetector(resource)
+		if !ok {
+			errs = append(errs, fmt.Errorf("resource type %q is not supported", resource))
+			continue
 		}
+		resolved = append(resolved, detector)
 	}
+	return resolved, errors.Join(errs...)
+}
 
-	// Remove elements marked for removal
-	var clearedResourceList []string
-	for i, marked := range markedForRemoval {
-		if !marked {
-			clearedResourceList = append(clearedResourceList, updatedResourceList[i])
-		}
+// scanTask is one detector run against a namespace, or against the cluster
+// when namespace is empty
+type scanTask struct {
+	namespace string
+	detector  Detector
+	diff      []ResourceInfo
+	err       error
+}
+
+func newScanTasks(namespace string, detectors []Detector) []scanTask {
+	tasks := make([]scanTask, 0, len(detectors))
+	for _, detector := range detectors {
+		tasks = append(tasks, scanTask{namespace: namespace, detector: detector})
 	}
+	return tasks
+}
 
-	return noNamespaceDiff, clearedResourceList
+// runScanTasks runs the tasks on a pool of opts.Concurrency workers. Each
+// task keeps its own result, so the order of the tasks is the output order.
+func runScanTasks(ctx context.Context, clients Clients, tasks []scanTask, filterOpts *filters.Options, opts common.Opts) {
+	workers := min(max(opts.Concurrency, 1), len(tasks))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for i := range next {
+				if err := ctx.Err(); err != nil {
+					tasks[i].err = err
+					continue
+				}
+				tasks[i].diff, tasks[i].err = tasks[i].detector.Detect(ctx, clients, tasks[i].namespace, filterOpts, opts)
+			}
+		})
+	}
+	for i := range tasks {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
 }
 
-func retrieveNamespaceDiffs(clientset kubernetes.Interface, namespace string, resourceList []string, filterOpts *filters.Options, opts common.Opts) []ResourceDiff {
-	var allDiffs []ResourceDiff
-	for _, resource := range resourceList {
-		var diffResult ResourceDiff
-		canonicalType := getCanonicalResourceType(resource)
-		switch canonicalType {
-		case "configmap":
-			diffResult = getUnusedCMs(clientset, namespace, filterOpts, opts)
-		case "service":
-			diffResult = getUnusedSVCs(clientset, namespace, filterOpts, opts)
-		case "secret":
-			diffResult = getUnusedSecrets(clientset, namespace, filterOpts, opts)
-		case "serviceaccount":
-			diffResult = getUnusedServiceAccounts(clientset, namespace, filterOpts, opts)
-		case "deployment":
-			diffResult = getUnusedDeployments(clientset, namespace, filterOpts, opts)
-		case "statefulset":
-			diffResult = getUnusedStatefulSets(clientset, namespace, filterOpts, opts)
-		case "role":
-			diffResult = getUnusedRoles(clientset, namespace, filterOpts, opts)
-		case "horizontalpodautoscaler":
-			diffResult = getUnusedHpas(clientset, namespace, filterOpts, opts)
-		case "persistentvolumeclaim":
-			diffResult = getUnusedPvcs(clientset, namespace, filterOpts, opts)
-		case "ingress":
-			diffResult = getUnusedIngresses(clientset, namespace, filterOpts, opts)
-		case "poddisruptionbudget":
-			diffResult = getUnusedPdbs(clientset, namespace, filterOpts, opts)
-		case "pod":
-			diffResult = getUnusedPods(clientset, namespace, filterOpts, opts)
-		case "job":
-			diffResult = getUnusedJobs(clientset, namespace, filterOpts, opts)
-		case "replicaset":
-			diffResult = getUnusedReplicaSets(clientset, namespace, filterOpts, opts)
-		case "daemonset":
-			diffResult = getUnusedDaemonSets(clientset, namespace, filterOpts, opts)
-		case "networkpolicy":
-			diffResult = getUnusedNetworkPolicies(clientset, namespace, filterOpts, opts)
-		case "rolebinding":
-			diffResult = getUnusedRoleBindings(clientset, namespace, filterOpts, opts)
-		default:
-			fmt.Printf("resource type %q is not supported\n", resource)
-		}
-		allDiffs = append(allDiffs, diffResult)
+// retrieveNamespaceDiffs runs the detectors against a namespace, or against
+// the cluster when namespace is empty, returning one diff per detector
+func retrieveNamespaceDiffs(ctx context.Context, clients Clients, namespace string, detectors []Detector, filterOpts *filters.Options, opts common.Opts) []ResourceDiff {
+	tasks := newScanTasks(namespace, detectors)
+	runScanTasks(ctx, clients, tasks, filterOpts, opts)
+
+	allDiffs := make([]ResourceDiff, 0, len(tasks))
+	for _, task := range tasks {
+		allDiffs = append(allDiffs, ResourceDiff{task.detector.Name(), task.diff})
 	}
 	return allDiffs
 }
 
-func GetUnusedMulti(resourceNames string, filterOpts *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resourceList := strings.Split(resourceNames, ",")
-	namespaces := filterOpts.Namespaces(clientset)
-	resources := make(map[string]map[string][]ResourceInfo)
-	var err error
+// GetUnusedReport runs the detectors once for the cluster-scoped kinds and
+// once per selected namespace for the namespaced ones. The detectors read
+// the cluster through a Snapshot and a DynamicSnapshot, so each kind is
+// listed once per scan, and share the ones of clients when they already are
+// snapshots. They run on a pool of
+// opts.Concurrency workers. Namespaces are reported in name order. Deletion
+// happens once every detector is done, after a single review of every
+// finding unless opts.NoInteractive is set, and kind by kind in an order
+// that deletes owners and referring kinds first.
+//
+// The scan stops when ctx is done or opts.Timeout has elapsed, returning the
+// findings gathered so far in a report marked Incomplete. Nothing is deleted
+// from an incomplete report.
+//
+// The resources listed in opts.ExceptionsFile are left out of the report.
+//
+// Failing detectors and deletions are collected in the report's Errors, the
+// returned error being kept for failures that prevent any report at all.
+func GetUnusedReport(ctx context.Context, detectors []Detector, filterOpts *filters.Options, clients Clients, opts common.Opts) (*Report, error) {
+	thresholds, err := newThresholds(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateDryRun(opts.DryRun); err != nil {
+		return nil, err
+	}
+	if err := ValidateCascade(opts.Cascade); err != nil {
+		return nil, err
+	}
+	if err := validateQuarantine(opts); err != nil {
+		return nil, err
+	}
+	var exceptions *ExceptionsFile
+	if opts.ExceptionsFile != "" {
+		if exceptions, err = ReadExceptions(opts.ExceptionsFile); err != nil {
+			return nil, err
+		}
+	}
+	audit, err := newAuditLog(opts, clients.Clientset)
+	if err != nil {
+		return nil, err
+	}
 
-	if opts.GroupBy == "namespace" {
-		resources[""] = make(map[string][]ResourceInfo)
+	report := &Report{}
+	scanCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		scanCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	// without a clientset the detectors needing one fail with errClientsetNotConfigured,
+	// and a Snapshot of the caller is shared with it
+	if _, shared := clients.Clientset.(*Snapshot); clients.Clientset != nil && !shared {
+		clients.Clientset = NewSnapshot(clients.Clientset, len(filterOpts.IncludeNamespaces) == 0)
+	}
+	if _, shared := clients.Dynamic.(*DynamicSnapshot); clients.Dynamic != nil && !shared {
+		clients.Dynamic = NewDynamicSnapshot(clients.Dynamic, len(filterOpts.IncludeNamespaces) == 0)
 	}
 
-	noNamespaceDiff, resourceList := retrieveNoNamespaceDiff(clientset, apiExtClient, dynamicClient, resourceList, filterOpts, opts)
-	if len(noNamespaceDiff) != 0 {
-		for _, diff := range noNamespaceDiff {
-			if len(diff.diff) != 0 {
-				if opts.DeleteFlag {
-					if diff.diff, err = DeleteResource(diff.diff, clientset, "", diff.resourceType, opts.NoInteractive); err != nil {
-						fmt.Fprintf(os.Stderr, "Failed to delete %s %s: %v\n", diff.resourceType, diff.diff, err)
-					}
-				}
-				switch opts.GroupBy {
-				case "namespace":
-					resources[""][diff.resourceType] = diff.diff
-				case "resource":
-					appendResources(resources, diff.resourceType, "", diff.diff)
-				}
-			}
+	var namespaced, clusterScoped []Detector
+	for _, detector := range detectors {
+		if detector.Scope() == ClusterScoped {
+			clusterScoped = append(clusterScoped, detector)
+		} else {
+			namespaced = append(namespaced, detector)
 		}
 	}
 
-	for _, namespace := range namespaces {
-		allDiffs := retrieveNamespaceDiffs(clientset, namespace, resourceList, filterOpts, opts)
-		if opts.GroupBy == "namespace" {
-			resources[namespace] = make(map[string][]ResourceInfo)
+	var tasks []scanTask
+	if len(clusterScoped) != 0 {
+		tasks = append(tasks, newScanTasks("", clusterScoped)...)
+	}
+	if len(namespaced) != 0 && clients.Clientset == nil {
+		report.addError("", "", errClientsetNotConfigured)
+	} else if len(namespaced) != 0 {
+		namespaces, err := filterOpts.Namespaces(scanCtx, clients.Clientset)
+		if err != nil {
+			report.addError("", "", err)
+		}
+		for _, namespace := range slices.Sorted(slices.Values(namespaces)) {
+			tasks = append(tasks, newScanTasks(namespace, namespaced)...)
 		}
+	}
+	runScanTasks(scanCtx, clients, tasks, filterOpts, opts)
 
-		for _, diff := range allDiffs {
-			if opts.DeleteFlag {
-				if diff.diff, err = DeleteResource(diff.diff, clientset, namespace, diff.resourceType, opts.NoInteractive); err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to delete %s %s in namespace %s: %v\n", diff.resourceType, diff.diff, namespace, err)
-				}
+	if err := scanCtx.Err(); err != nil {
+		report.Incomplete = true
+		report.addError("", "", fmt.Errorf("scan stopped before completion, results are incomplete: %w", err))
+		if opts.DeleteFlag {
+			fmt.Fprintln(os.Stderr, "Skipping deletion of an incomplete scan")
+		}
+	}
+
+	backup := newBackupArchive(opts)
+	now := time.Now()
+	taskFindings := make([][]Finding, len(tasks))
+	for i, task := range tasks {
+		if task.err != nil {
+			if report.Incomplete {
+				// cut short by the deadline, already reported as an incomplete scan
+				continue
 			}
-			switch opts.GroupBy {
-			case "namespace":
-				resources[namespace][diff.resourceType] = diff.diff
-			case "resource":
-				appendResources(resources, diff.resourceType, namespace, diff.diff)
+			report.addError(task.detector.Name(), task.namespace, task.err)
+		}
+		report.addNamespace(task.namespace)
+		findings := exceptions.filter(newFindings(task.detector.Name(), task.namespace, task.diff))
+		if opts.Quarantine != "" && task.err == nil && !report.Incomplete {
+			var released []Finding
+			var err error
+			findings, released, err = quarantineFindings(ctx, findings, clients, task.detector, task.namespace, opts, now, audit,
+				func() ([]ResourceInfo, error) {
+					return task.detector.Detect(ctx, clients, task.namespace, filters.NewFilterOptions(), opts)
+				})
+			if err != nil {
+				report.addError(task.detector.Name(), task.namespace, err)
 			}
+			report.Released = append(report.Released, released...)
 		}
+		taskFindings[i] = findings
 	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
+	if opts.DeleteFlag && !report.Incomplete {
+		detectorOf := func(finding Finding) Detector {
+			detector, _ := LookupDetector(finding.Kind)
+			return detector
+		}
+		all := slices.Concat(taskFindings...)
+		preview, err := deletionCascade(ctx, clients, all, opts)
+		if err != nil {
+			report.addError("", "", err)
 		}
+		review, err := reviewDeletion(ctx, all, preview, opts, AuditDelete,
+			func(finding Finding) error {
+				return detectorOf(finding).Flag(ctx, clients, finding.Namespace, finding.Name)
+			},
+			func(finding Finding) (runtime.Object, error) {
+				return getObject(ctx, detectorOf(finding), clients, finding.Namespace, finding.Name)
+			}, audit)
+		if err != nil {
+			report.addError("", "", err)
+		}
+		report.Cascade = deleteTasksInOrder(ctx, report, tasks, taskFindings, clients, opts, review, preview, backup, audit)
+	}
+	for i := range tasks {
+		report.Findings = append(report.Findings, taskFindings[i]...)
+	}
+	if err := backup.Close(); err != nil {
+		report.addError("", "", fmt.Errorf("failed to close backup archive: %w", err))
 	}
+	report.BackupArchive = backup.Path()
+	if err := audit.Close(); err != nil {
+		report.addError("", "", fmt.Errorf("failed to close audit log: %w", err))
+	}
+	report.Violations = thresholds.check(report.Findings)
+
+	return report, nil
+}
 
-	unusedMulti, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
+// GetUnusedWithDetectors returns the formatted unused resources found by the given detectors
+func GetUnusedWithDetectors(ctx context.Context, detectors []Detector, filterOpts *filters.Options, clients Clients, outputFormat string, opts common.Opts) (string, error) {
+	report, err := GetUnusedReport(ctx, detectors, filterOpts, clients, opts)
 	if err != nil {
-		fmt.Printf("err: %v\n", err)
+		return "", err
 	}
+	return formatReportWithThresholds(report, outputFormat, opts)
+}
 
-	return unusedMulti, nil
+// GetUnusedMultiReport scans the comma-separated resource kinds in resourceNames
+// Unsupported kinds are reported as errors, failing the scan when none of
+// the requested kinds is supported.
+func GetUnusedMultiReport(ctx context.Context, resourceNames string, filterOpts *filters.Options, clients Clients, opts common.Opts) (*Report, error) {
+	detectors, resolveErr := resolveDetectors(strings.Split(resourceNames, ","))
+	if len(detectors) == 0 {
+		return nil, resolveErr
+	}
+
+	report, err := GetUnusedReport(ctx, detectors, filterOpts, clients, opts)
+	if err != nil {
+		return nil, err
+	}
+	if resolveErr != nil {
+		report.addError("", "", resolveErr)
+	}
+	return report, nil
+}
+
+func GetUnusedMulti(ctx context.Context, resourceNames string, filterOpts *filters.Options, clientset kubernetes.Interface, apiExtClient apiextensionsclientset.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	clients := Clients{Clientset: clientset, APIExtensions: apiExtClient, Dynamic: dynamicClient}
+	report, err := GetUnusedMultiReport(ctx, resourceNames, filterOpts, clients, opts)
+	if err != nil {
+		return "", err
+	}
+	return formatReportWithThresholds(report, outputFormat, opts)
 }
@@ -61,7 +61,11 @@ func TestRetrieveNamespaceDiff(t *testing.T) {
 	resourceList := []string{"cm", "pdb", "deployment"}
 	filterOpts := &filters.Options{}
 
-	namespaceDiff := retrieveNamespaceDiffs(clientset, testNamespace, resourceList, filterOpts, common.Opts{})
+	detectors, err := resolveDetectors(resourceList)
+	if err != nil {
+		t.Fatalf("Expected all resource types to be supported, got %v", err)
+	}
+	namespaceDiff := retrieveNamespaceDiffs(context.TODO(), Clients{Clientset: clientset}, testNamespace, detectors, filterOpts, common.Opts{})
 
 	if len(namespaceDiff) != 3 {
 		t.Fatalf("Expected 3 diffs, got %d", len(namespaceDiff))
@@ -94,7 +98,7 @@ func TestGetUnusedMulti(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedMulti(resourceList, &filters.Options{}, clientset, nil, nil, "json", opts)
+	output, err := GetUnusedMulti(context.TODO(), resourceList, &filters.Options{}, clientset, nil, nil, "json", opts)
 
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedMulti: %v", err)
@@ -178,7 +182,7 @@ func TestGetUnusedMultiWithMultipleResources(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedMulti(resourceList, &filters.Options{}, clientset, nil, nil, "json", opts)
+	output, err := GetUnusedMulti(context.TODO(), resourceList, &filters.Options{}, clientset, nil, nil, "json", opts)
 
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedMulti: %v", err)
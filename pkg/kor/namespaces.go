@@ -0,0 +1,271 @@
+package kor
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+//go:embed exceptions/namespaces/namespaces.json
+var namespacesConfig []byte
+
+// defaultNamespaceConfigMaps are created by Kubernetes (or the distribution) in every namespace
+var defaultNamespaceConfigMaps = []string{
+	"kube-root-ca.crt",
+	"openshift-service-ca.crt",
+}
+
+// derivedNamespaceResources are kept up to date by Kubernetes from other
+// objects of the namespace, such as the Endpoints of Services, and are left
+// out when deciding whether a namespace is used
+var derivedNamespaceResources = []schema.GroupResource{
+	{Resource: "events"},
+	{Group: "events.k8s.io", Resource: "events"},
+	{Resource: "endpoints"},
+	{Group: "discovery.k8s.io", Resource: "endpointslices"},
+	{Group: "apps", Resource: "controllerrevisions"},
+	{Group: "metrics.k8s.io", Resource: "pods"},
+}
+
+// defaultNamespaceObjects tells, by detector name, the objects Kubernetes
+// (or the distribution) creates in every namespace. They are left out when
+// deciding whether a namespace is used.
+var defaultNamespaceObjects = map[string]func(obj metav1.Object) bool{
+	"ConfigMap": func(obj metav1.Object) bool {
+		return slices.Contains(defaultNamespaceConfigMaps, obj.GetName())
+	},
+	"ServiceAccount": func(obj metav1.Object) bool {
+		return obj.GetName() == "default"
+	},
+	"Secret": func(obj metav1.Object) bool {
+		secret, ok := obj.(*corev1.Secret)
+		return ok && secret.Type == corev1.SecretTypeServiceAccountToken && secret.Annotations[corev1.ServiceAccountNameKey] == "default"
+	},
+}
+
+// namespaceListers returns the namespaced detectors able to list their
+// objects, which decide whether the objects of their kind are used. The
+// objects of the other kinds are always in use.
+func namespaceListers() []Detector {
+	var listers []Detector
+	for _, detector := range DetectorsByScope(NamespaceScoped) {
+		if _, ok := detector.(Lister); ok {
+			listers = append(listers, detector)
+		}
+	}
+	return listers
+}
+
+// namespaceObjectNames returns the names of the objects of a detector in
+// namespace, leaving out the ones created by default. There are none when
+// the resource is not served, such as the Gateway API kinds without their CRDs.
+func namespaceObjectNames(ctx context.Context, detector Detector, clients Clients, namespace string) ([]string, error) {
+	objects, err := listObjects(ctx, detector, clients, namespace, "")
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	isDefault := defaultNamespaceObjects[detector.Name()]
+	var names []string
+	for _, obj := range objects {
+		if isDefault == nil || !isDefault(obj) {
+			names = append(names, obj.GetName())
+		}
+	}
+	return names, nil
+}
+
+// otherNamespacedResources discovers the namespaced resources that can be
+// listed and have no kor detector listing them. Any object of
+// these makes a namespace used. Discovery must succeed for every API group,
+// otherwise a namespace could hold objects of a group that was not checked.
+func otherNamespacedResources(clientset kubernetes.Interface) ([]schema.GroupVersionResource, error) {
+	resourceLists, err := clientset.Discovery().ServerPreferredNamespacedResources()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the namespaced resources: %v", err)
+	}
+
+	checked := make(map[schema.GroupResource]bool)
+	for _, detector := range namespaceListers() {
+		checked[detector.(Lister).Resource()] = true
+	}
+	for _, resource := range derivedNamespaceResources {
+		checked[resource] = true
+	}
+
+	var resources []schema.GroupVersionResource
+	for _, list := range resourceLists {
+		if list == nil {
+			continue
+		}
+		groupVersion, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range list.APIResources {
+			// subresources such as pods/log are not objects of their own
+			if strings.Contains(resource.Name, "/") || !slices.Contains(resource.Verbs, "list") {
+				continue
+			}
+			gvr := groupVersion.WithResource(resource.Name)
+			if !checked[gvr.GroupResource()] {
+				resources = append(resources, gvr)
+			}
+		}
+	}
+	return resources, nil
+}
+
+// isNamespaceUnused reports whether every object in the namespace is either a
+// system default or reported as unused by the detector for its kind. Objects
+// of otherResources, the kinds without a detector, are always in use. The
+// returned bool is true when the namespace holds nothing but system defaults.
+func isNamespaceUnused(ctx context.Context, clients Clients, namespace string, otherResources []schema.GroupVersionResource, opts common.Opts) (bool, bool, error) {
+	// Objects are judged on their own merits; label and age filters only apply to the namespace itself
+	detectorFilterOpts := &filters.Options{}
+
+	empty := true
+	for _, detector := range namespaceListers() {
+		names, err := namespaceObjectNames(ctx, detector, clients, namespace)
+		if err != nil {
+			return false, false, fmt.Errorf("failed to list %s: %w", detector.Name(), err)
+		}
+		if len(names) == 0 {
+			continue
+		}
+		empty = false
+
+		unused, err := detector.Detect(ctx, clients, namespace, detectorFilterOpts, opts)
+		if err != nil {
+			return false, false, fmt.Errorf("failed to process %s: %w", detector.Name(), err)
+		}
+		for _, name := range names {
+			if !resourceInfoContains(unused, name) {
+				return false, false, nil
+			}
+		}
+	}
+
+	if len(otherResources) > 0 && clients.Dynamic == nil {
+		return false, false, errDynamicClientNotConfigured
+	}
+	for _, gvr := range otherResources {
+		list, err := clients.Dynamic.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{Limit: 1})
+		if apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+			continue
+		}
+		if err != nil {
+			return false, false, fmt.Errorf("failed to list %s: %v", gvr.GroupResource(), err)
+		}
+		if len(list.Items) > 0 {
+			return false, false, nil
+		}
+	}
+	return true, empty, nil
+}
+
+func processNamespaces(ctx context.Context, clients Clients, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	clientset := clients.Clientset
+	namespaceList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := unmarshalConfig(namespacesConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	includedNamespaces, err := filterOpts.Namespaces(ctx, clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	otherResources, err := otherNamespacedResources(clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedNamespaces []ResourceInfo
+
+	for _, namespace := range namespaceList.Items {
+		if !slices.Contains(includedNamespaces, namespace.Name) {
+			continue
+		}
+
+		// Skip resources with ownerReferences if the general flag is set
+		if filterOpts.IgnoreOwnerReferences && len(namespace.OwnerReferences) > 0 {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&namespace).Run(filterOpts); pass {
+			continue
+		}
+
+		if namespace.Labels["kor/used"] == "false" {
+			reason := "Marked with unused label"
+			unusedNamespaces = append(unusedNamespaces, ResourceInfo{Name: namespace.Name, Reason: reason})
+			continue
+		}
+
+		exceptionFound, err := isResourceException(namespace.Name, "", config.ExceptionNamespaces)
+		if err != nil {
+			return nil, err
+		}
+
+		if exceptionFound {
+			continue
+		}
+
+		if namespace.Status.Phase == corev1.NamespaceTerminating {
+			continue
+		}
+
+		unused, empty, err := isNamespaceUnused(ctx, clients, namespace.Name, otherResources, opts)
+		if err != nil {
+			return nil, fmt.Errorf("namespace %s: %w", namespace.Name, err)
+		}
+		if !unused {
+			continue
+		}
+
+		reason := "Namespace only contains default or unused resources"
+		if empty {
+			reason = "Namespace only contains default resources"
+		}
+		unusedNamespaces = append(unusedNamespaces, ResourceInfo{Name: namespace.Name, Reason: reason})
+	}
+
+	return unusedNamespaces, nil
+}
+
+func detectNamespaces(ctx context.Context, clients Clients, _ string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	return processNamespaces(ctx, clients, filterOpts, opts)
+}
+
+var namespaceDetector = newDetector("Namespace", []string{"namespace", "ns", "namespaces"}, ClusterScoped, detectNamespaces,
+	func(clients Clients, _ string) typedClient[*corev1.Namespace, *corev1.NamespaceList] {
+		return clients.Clientset.CoreV1().Namespaces()
+	})
+
+func init() {
+	MustRegisterDetector(namespaceDetector)
+}
+
+func GetUnusedNamespaces(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, dynamicClient dynamic.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{namespaceDetector}, filterOpts, Clients{Clientset: clientset, Dynamic: dynamicClient}, outputFormat, opts)
+}
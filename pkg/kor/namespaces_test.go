@@ -0,0 +1,283 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"reflect"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestNamespaces(t *testing.T) *fake.Clientset {
+	clientset := fake.NewClientset()
+
+	namespaces := []*corev1.Namespace{
+		CreateTestNamespace("empty-ns", AppLabels),
+		CreateTestNamespace("leftover-ns", AppLabels),
+		CreateTestNamespace("used-ns", AppLabels),
+		CreateTestNamespace("kube-system", AppLabels),
+		CreateTestNamespace("marked-unused-ns", UnusedLabels),
+		CreateTestNamespace("marked-used-ns", UsedLabels),
+	}
+	for _, namespace := range namespaces {
+		_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), namespace, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating namespace %s: %v", namespace.Name, err)
+		}
+
+		// Every namespace gets the objects Kubernetes creates by default
+		_, err = clientset.CoreV1().ConfigMaps(namespace.Name).Create(context.TODO(), CreateTestConfigmap(namespace.Name, "kube-root-ca.crt", AppLabels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+		_, err = clientset.CoreV1().ServiceAccounts(namespace.Name).Create(context.TODO(), CreateTestServiceAccount(namespace.Name, "default", AppLabels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake serviceaccount: %v", err)
+		}
+	}
+
+	_, err := clientset.CoreV1().ConfigMaps("leftover-ns").Create(context.TODO(), CreateTestConfigmap("leftover-ns", "leftover-config", AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake configmap: %v", err)
+	}
+
+	_, err = clientset.AppsV1().Deployments("leftover-ns").Create(context.TODO(), CreateTestDeployment("leftover-ns", "scaled-down", 0, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake deployment: %v", err)
+	}
+
+	_, err = clientset.AppsV1().Deployments("used-ns").Create(context.TODO(), CreateTestDeployment("used-ns", "running", 1, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake deployment: %v", err)
+	}
+
+	return clientset
+}
+
+func TestProcessNamespaces(t *testing.T) {
+	clientset := createTestNamespaces(t)
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gatewayAPIListKinds)
+
+	unusedNamespaces, err := processNamespaces(context.TODO(), Clients{Clientset: clientset, Dynamic: dynamicClient}, &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"empty-ns":         "Namespace only contains default resources",
+		"leftover-ns":      "Namespace only contains default or unused resources",
+		"marked-unused-ns": "Marked with unused label",
+	}
+
+	if len(unusedNamespaces) != len(expected) {
+		t.Fatalf("Expected %d unused namespaces, got %d: %v", len(expected), len(unusedNamespaces), unusedNamespaces)
+	}
+
+	for _, namespace := range unusedNamespaces {
+		reason, ok := expected[namespace.Name]
+		if !ok {
+			t.Errorf("Unexpected unused namespace %s", namespace.Name)
+			continue
+		}
+		if namespace.Reason != reason {
+			t.Errorf("Expected reason %q for namespace %s, got %q", reason, namespace.Name, namespace.Reason)
+		}
+	}
+}
+
+func TestProcessNamespacesIncludeNamespaces(t *testing.T) {
+	clientset := createTestNamespaces(t)
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gatewayAPIListKinds)
+
+	unusedNamespaces, err := processNamespaces(context.TODO(), Clients{Clientset: clientset, Dynamic: dynamicClient}, &filters.Options{IncludeNamespaces: []string{"empty-ns"}}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(unusedNamespaces) != 1 || unusedNamespaces[0].Name != "empty-ns" {
+		t.Errorf("Expected only empty-ns to be unused, got %v", unusedNamespaces)
+	}
+}
+
+// namespacedDiscovery serves the namespaced resources of the fake discovery,
+// which only implements ServerGroupsAndResources
+type namespacedDiscovery struct {
+	*fakediscovery.FakeDiscovery
+}
+
+func (d *namespacedDiscovery) ServerPreferredNamespacedResources() ([]*v1.APIResourceList, error) {
+	return discovery.ServerPreferredNamespacedResources(d.FakeDiscovery)
+}
+
+type namespacedDiscoveryClientset struct {
+	*fake.Clientset
+}
+
+func (c *namespacedDiscoveryClientset) Discovery() discovery.DiscoveryInterface {
+	return &namespacedDiscovery{c.Clientset.Discovery().(*fakediscovery.FakeDiscovery)}
+}
+
+func TestProcessNamespacesOtherResources(t *testing.T) {
+	clientset := createTestNamespaces(t)
+	clientset.Resources = []*v1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []v1.APIResource{
+				{Name: "configmaps", Namespaced: true, Kind: "ConfigMap", Verbs: v1.Verbs{"get", "list"}},
+				{Name: "events", Namespaced: true, Kind: "Event", Verbs: v1.Verbs{"get", "list"}},
+				{Name: "pods/log", Namespaced: true, Kind: "Pod", Verbs: v1.Verbs{"get"}},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []v1.APIResource{
+				{Name: "widgets", Namespaced: true, Kind: "Widget", Verbs: v1.Verbs{"get", "list"}},
+			},
+		},
+	}
+	widgetGVR := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	listKinds := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "events"}: "EventList",
+		widgetGVR:                           "WidgetList",
+	}
+	maps.Copy(listKinds, gatewayAPIListKinds)
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	if _, err := dynamicClient.Resource(widgetGVR).Namespace("leftover-ns").Create(context.TODO(), CreateTestUnstructered("Widget", "example.com/v1", "leftover-ns", "widget"), v1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating fake widget: %v", err)
+	}
+
+	clients := Clients{Clientset: &namespacedDiscoveryClientset{clientset}, Dynamic: dynamicClient}
+	unusedNamespaces, err := processNamespaces(context.TODO(), clients, &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var names []string
+	for _, namespace := range unusedNamespaces {
+		names = append(names, namespace.Name)
+	}
+	expected := []string{"empty-ns", "marked-unused-ns"}
+	if !reflect.DeepEqual(RemoveDuplicatesAndSort(names), expected) {
+		t.Errorf("Expected %v, leftover-ns holding a custom resource being used, got %v", expected, names)
+	}
+
+	if _, err := processNamespaces(context.TODO(), Clients{Clientset: clients.Clientset}, &filters.Options{}, common.Opts{}); !errors.Is(err, errDynamicClientNotConfigured) {
+		t.Errorf("Expected %v without a dynamic client, got %v", errDynamicClientNotConfigured, err)
+	}
+}
+
+func TestProcessNamespacesGatewayAPI(t *testing.T) {
+	clientset := createTestNamespaces(t)
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gatewayAPIListKinds)
+	objects := []struct {
+		gvr    schema.GroupVersionResource
+		object *unstructured.Unstructured
+	}{
+		// a Gateway without routes is unused
+		{gatewayGVR, createTestGateway("empty-ns", "idle", "used-class")},
+		{gatewayGVR, createTestGateway("leftover-ns", "web", "used-class")},
+		{httpRouteGVR, createTestRoute("HTTPRoute", "gateway.networking.k8s.io/v1", "leftover-ns", "web-route", []map[string]interface{}{{"name": "web"}})},
+	}
+	for _, object := range objects {
+		_, err := dynamicClient.Resource(object.gvr).Namespace(object.object.GetNamespace()).Create(context.TODO(), object.object, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake %s %s: %v", object.object.GetKind(), object.object.GetName(), err)
+		}
+	}
+
+	unusedNamespaces, err := processNamespaces(context.TODO(), Clients{Clientset: clientset, Dynamic: dynamicClient}, &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "empty-ns", Reason: "Namespace only contains default or unused resources"},
+		{Name: "marked-unused-ns", Reason: "Marked with unused label"},
+	}
+	sort.Slice(unusedNamespaces, func(i, j int) bool { return unusedNamespaces[i].Name < unusedNamespaces[j].Name })
+	if !reflect.DeepEqual(unusedNamespaces, expected) {
+		t.Errorf("Expected %v, leftover-ns holding a Gateway with a route being used, got %v", expected, unusedNamespaces)
+	}
+
+	if _, err := processNamespaces(context.TODO(), Clients{Clientset: clientset}, &filters.Options{}, common.Opts{}); !errors.Is(err, errDynamicClientNotConfigured) {
+		t.Errorf("Expected %v without a dynamic client, got %v", errDynamicClientNotConfigured, err)
+	}
+}
+
+func TestNamespaceListersResources(t *testing.T) {
+	resources := make(map[string]schema.GroupResource)
+	for _, detector := range namespaceListers() {
+		resource := detector.(Lister).Resource()
+		if resource.Resource == "" {
+			t.Errorf("Expected the resource of %s to be known", detector.Name())
+		}
+		resources[detector.Name()] = resource
+	}
+
+	expected := map[string]schema.GroupResource{
+		"ConfigMap": {Resource: "configmaps"},
+		"Hpa":       {Group: "autoscaling", Resource: "horizontalpodautoscalers"},
+		"Ingress":   {Group: "networking.k8s.io", Resource: "ingresses"},
+		"Pdb":       {Group: "policy", Resource: "poddisruptionbudgets"},
+		"Lease":     {Group: "coordination.k8s.io", Resource: "leases"},
+		"Gateway":   {Group: gatewayAPIGroup, Resource: "gateways"},
+		"TLSRoute":  {Group: gatewayAPIGroup, Resource: "tlsroutes"},
+	}
+	for name, resource := range expected {
+		if resources[name] != resource {
+			t.Errorf("Expected %s to list %v, got %v", name, resource, resources[name])
+		}
+	}
+}
+
+func TestGetUnusedNamespacesStructured(t *testing.T) {
+	clientset := createTestNamespaces(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gatewayAPIListKinds)
+	output, err := GetUnusedNamespaces(context.TODO(), &filters.Options{}, clientset, dynamicClient, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedNamespacesStructured: %v", err)
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	actualNamespaces := actualOutput[""]["Namespace"]
+	expectedNamespaces := []string{"empty-ns", "leftover-ns", "marked-unused-ns"}
+	if !reflect.DeepEqual(RemoveDuplicatesAndSort(actualNamespaces), expectedNamespaces) {
+		t.Errorf("Expected %v, got %v", expectedNamespaces, actualNamespaces)
+	}
+}
+
+func init() {
+	scheme.Scheme = runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme.Scheme)
+}
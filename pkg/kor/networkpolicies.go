@@ -1,11 +1,7 @@
 package kor
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"os"
 	"slices"
 
 	v1 "k8s.io/api/core/v1"
@@ -23,12 +19,12 @@ const (
 	noPodAppliedByRulesReason = "NetworkPolicy Ingress and Egress rules apply to 0 pods"
 )
 
-func retrievePodsForSelector(clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) ([]v1.Pod, error) {
+func retrievePodsForSelector(ctx context.Context, clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) ([]v1.Pod, error) {
 	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
 	if err != nil {
 		return nil, err
 	}
-	podList, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+	podList, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: labelSelector.String(),
 	})
 	if err != nil {
@@ -38,7 +34,7 @@ func retrievePodsForSelector(clientset kubernetes.Interface, namespace string, s
 	return podList.Items, nil
 }
 
-func isAnyPodMatchedInSources(clientset kubernetes.Interface, sources []networkingv1.NetworkPolicyPeer) (bool, error) {
+func isAnyPodMatchedInSources(ctx context.Context, clientset kubernetes.Interface, sources []networkingv1.NetworkPolicyPeer) (bool, error) {
 	// If this field is empty or missing, this rule matches all pods
 	if len(sources) == 0 {
 		return true, nil
@@ -55,7 +51,7 @@ func isAnyPodMatchedInSources(clientset kubernetes.Interface, sources []networki
 			return false, err
 		}
 
-		nsList, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{
+		nsList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{
 			LabelSelector: labelSelector.String(),
 		})
 		if err != nil {
@@ -63,7 +59,7 @@ func isAnyPodMatchedInSources(clientset kubernetes.Interface, sources []networki
 		}
 
 		for _, ns := range nsList.Items {
-			podList, err := retrievePodsForSelector(clientset, ns.Name, netpolPeer.PodSelector)
+			podList, err := retrievePodsForSelector(ctx, clientset, ns.Name, netpolPeer.PodSelector)
 			if err != nil {
 				return false, err
 			}
@@ -77,13 +73,13 @@ func isAnyPodMatchedInSources(clientset kubernetes.Interface, sources []networki
 	return false, nil
 }
 
-func isAnyIngressRuleUsed(clientset kubernetes.Interface, netpol networkingv1.NetworkPolicy) (bool, error) {
+func isAnyIngressRuleUsed(ctx context.Context, clientset kubernetes.Interface, netpol networkingv1.NetworkPolicy) (bool, error) {
 	// Deny all ingress traffic
 	if len(netpol.Spec.Ingress) == 0 && slices.Contains(netpol.Spec.PolicyTypes, networkingv1.PolicyTypeIngress) {
 		return true, nil
 	}
 	for _, ingressRule := range netpol.Spec.Ingress {
-		podsMatched, err := isAnyPodMatchedInSources(clientset, ingressRule.From)
+		podsMatched, err := isAnyPodMatchedInSources(ctx, clientset, ingressRule.From)
 		if err != nil {
 			return false, err
 		}
@@ -96,14 +92,14 @@ func isAnyIngressRuleUsed(clientset kubernetes.Interface, netpol networkingv1.Ne
 	return false, nil
 }
 
-func isAnyEgressRuleUsed(clientset kubernetes.Interface, netpol networkingv1.NetworkPolicy) (bool, error) {
+func isAnyEgressRuleUsed(ctx context.Context, clientset kubernetes.Interface, netpol networkingv1.NetworkPolicy) (bool, error) {
 	// Deny all egress traffic
 	if len(netpol.Spec.Egress) == 0 && slices.Contains(netpol.Spec.PolicyTypes, networkingv1.PolicyTypeEgress) {
 		return true, nil
 	}
 
 	for _, egressRule := range netpol.Spec.Egress {
-		podsMatched, err := isAnyPodMatchedInSources(clientset, egressRule.To)
+		podsMatched, err := isAnyPodMatchedInSources(ctx, clientset, egressRule.To)
 		if err != nil {
 			return false, err
 		}
@@ -116,8 +112,8 @@ func isAnyEgressRuleUsed(clientset kubernetes.Interface, netpol networkingv1.Net
 	return false, nil
 }
 
-func processNamespaceNetworkPolicies(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	netpolList, err := clientset.NetworkingV1().NetworkPolicies(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespaceNetworkPolicies(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	netpolList, err := clientset.NetworkingV1().NetworkPolicies(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +135,7 @@ func processNamespaceNetworkPolicies(clientset kubernetes.Interface, namespace s
 			continue
 		}
 
-		pods, err := retrievePodsForSelector(clientset, namespace, &netpol.Spec.PodSelector)
+		pods, err := retrievePodsForSelector(ctx, clientset, namespace, &netpol.Spec.PodSelector)
 		if err != nil {
 			return nil, err
 		}
@@ -149,13 +145,13 @@ func processNamespaceNetworkPolicies(clientset kubernetes.Interface, namespace s
 			continue
 		}
 
-		if used, err := isAnyIngressRuleUsed(clientset, netpol); err != nil {
+		if used, err := isAnyIngressRuleUsed(ctx, clientset, netpol); err != nil {
 			return nil, err
 		} else if used {
 			continue
 		}
 
-		if used, err := isAnyEgressRuleUsed(clientset, netpol); err != nil {
+		if used, err := isAnyEgressRuleUsed(ctx, clientset, netpol); err != nil {
 			return nil, err
 		} else if used {
 			continue
@@ -163,49 +159,18 @@ func processNamespaceNetworkPolicies(clientset kubernetes.Interface, namespace s
 
 		unusedNetpols = append(unusedNetpols, ResourceInfo{Name: netpol.Name, Reason: noPodAppliedByRulesReason})
 	}
-	if opts.DeleteFlag {
-		if unusedNetpols, err := DeleteResource(unusedNetpols, clientset, namespace, "NetworkPolicy", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete NetworkPolicy %s in namespace %s: %v\n", unusedNetpols, namespace, err)
-		}
-	}
 	return unusedNetpols, nil
 }
 
-func GetUnusedNetworkPolicies(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceNetworkPolicies(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["NetworkPolicy"] = diff
-		case "resource":
-			appendResources(resources, "NetworkPolicy", namespace, diff)
-		}
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var networkPolicyDetector = newDetector("NetworkPolicy", []string{"networkpolicy", "netpol", "networkpolicies"}, NamespaceScoped, namespacedDetect(processNamespaceNetworkPolicies),
+	func(clients Clients, namespace string) typedClient[*networkingv1.NetworkPolicy, *networkingv1.NetworkPolicyList] {
+		return clients.Clientset.NetworkingV1().NetworkPolicies(namespace)
+	})
 
-	unusedNetworkPolicies, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(networkPolicyDetector)
+}
 
-	return unusedNetworkPolicies, nil
+func GetUnusedNetworkPolicies(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{networkPolicyDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -165,7 +165,7 @@ func TestRetrievePodsForSelector(t *testing.T) {
 			"app.kubernetes.io/version": "v1",
 		},
 	}
-	pods, err := retrievePodsForSelector(clientset, testNamespace, selector)
+	pods, err := retrievePodsForSelector(context.TODO(), clientset, testNamespace, selector)
 	if err != nil {
 		t.Errorf("Error retrieving pods for selector %v: %v", selector, err)
 	}
@@ -198,7 +198,7 @@ func TestIsAnyPodMatchedInSources(t *testing.T) {
 		},
 	}
 
-	matched, err := isAnyPodMatchedInSources(clientset, sources)
+	matched, err := isAnyPodMatchedInSources(context.TODO(), clientset, sources)
 	if err != nil {
 		t.Errorf("Error checking if sources match any pods: %v", err)
 	}
@@ -213,7 +213,7 @@ func TestIsAnyIngressRuleUsed(t *testing.T) {
 
 	netpol := CreateTestNetworkPolicy("netpol-0", testNamespace, AppLabels, v1.LabelSelector{}, nil, nil)
 
-	used, err := isAnyIngressRuleUsed(clientset, *netpol)
+	used, err := isAnyIngressRuleUsed(context.TODO(), clientset, *netpol)
 	if err != nil {
 		t.Errorf("Error checking if any ingress rule is used: %v", err)
 	}
@@ -228,7 +228,7 @@ func TestIsAnyEgressRuleUsed(t *testing.T) {
 
 	netpol := CreateTestNetworkPolicy("netpol-0", testNamespace, AppLabels, v1.LabelSelector{}, nil, nil)
 
-	used, err := isAnyEgressRuleUsed(clientset, *netpol)
+	used, err := isAnyEgressRuleUsed(context.TODO(), clientset, *netpol)
 	if err != nil {
 		t.Errorf("Error checking if any egress rule is used: %v", err)
 	}
@@ -241,7 +241,7 @@ func TestIsAnyEgressRuleUsed(t *testing.T) {
 func TestProcessNamespaceNetworkPolicies(t *testing.T) {
 	clientset := createTestNetworkPolicies(t)
 
-	unusedNetpols, err := processNamespaceNetworkPolicies(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	unusedNetpols, err := processNamespaceNetworkPolicies(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -268,7 +268,7 @@ func TestProcessNamespaceNetworkPoliciesWithOwnerReferences(t *testing.T) {
 	clientset := createTestNetworkPoliciesWithOwnerReferences(t)
 
 	// --ignore-owner-references=false (varsayılan)
-	unusedNetpols, err := processNamespaceNetworkPolicies(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	unusedNetpols, err := processNamespaceNetworkPolicies(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -277,7 +277,7 @@ func TestProcessNamespaceNetworkPoliciesWithOwnerReferences(t *testing.T) {
 	}
 
 	// --ignore-owner-references=true
-	unusedNetpols, err = processNamespaceNetworkPolicies(clientset, testNamespace, &filters.Options{IgnoreOwnerReferences: true}, common.Opts{})
+	unusedNetpols, err = processNamespaceNetworkPolicies(context.TODO(), clientset, testNamespace, &filters.Options{IgnoreOwnerReferences: true}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -301,7 +301,7 @@ func TestGetUnusedNetworkPolicies(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedNetworkPolicies(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedNetworkPolicies(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedNetworkPolicies: %v", err)
 	}
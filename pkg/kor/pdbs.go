@@ -1,13 +1,10 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
-	"fmt"
-	"os"
 
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
@@ -20,9 +17,9 @@ import (
 //go:embed exceptions/pdbs/pdbs.json
 var pdbsConfig []byte
 
-func processNamespacePdbs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+func processNamespacePdbs(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
 	var unusedPdbs []ResourceInfo
-	pdbs, err := clientset.PolicyV1().PodDisruptionBudgets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	pdbs, err := clientset.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +59,7 @@ func processNamespacePdbs(clientset kubernetes.Interface, namespace string, filt
 
 		// Validate empty selector
 		if selector == nil || len(selector.MatchLabels) == 0 {
-			hasRunningPods, err := validateRunningPods(clientset, namespace)
+			hasRunningPods, err := validateRunningPods(ctx, clientset, namespace)
 			if err != nil {
 				return nil, err
 			}
@@ -74,12 +71,12 @@ func processNamespacePdbs(clientset kubernetes.Interface, namespace string, filt
 
 			continue
 		} else {
-			hasMatchingTemplates, err = validateMatchingTemplates(clientset, namespace, selector)
+			hasMatchingTemplates, err = validateMatchingTemplates(ctx, clientset, namespace, selector)
 			if err != nil {
 				return nil, err
 			}
 
-			hasMatchingWorkloads, err = validateMatchingWorkloads(clientset, namespace, selector)
+			hasMatchingWorkloads, err = validateMatchingWorkloads(ctx, clientset, namespace, selector)
 			if err != nil {
 				return nil, err
 			}
@@ -90,17 +87,12 @@ func processNamespacePdbs(clientset kubernetes.Interface, namespace string, filt
 			unusedPdbs = append(unusedPdbs, ResourceInfo{Name: pdb.Name, Reason: reason})
 		}
 	}
-	if opts.DeleteFlag {
-		if unusedPdbs, err = DeleteResource(unusedPdbs, clientset, namespace, "PDB", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete PDB %s in namespace %s: %v\n", unusedPdbs, namespace, err)
-		}
-	}
 
 	return unusedPdbs, nil
 }
 
-func validateRunningPods(clientset kubernetes.Interface, namespace string) (bool, error) {
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+func validateRunningPods(ctx context.Context, clientset kubernetes.Interface, namespace string) (bool, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
 		FieldSelector: "status.phase=Running",
 	})
 	if err != nil {
@@ -118,13 +110,13 @@ func validateRunningPods(clientset kubernetes.Interface, namespace string) (bool
 	return false, nil
 }
 
-func validateMatchingTemplates(clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) (bool, error) {
+func validateMatchingTemplates(ctx context.Context, clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) (bool, error) {
 	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
 	if err != nil {
 		return false, err
 	}
 
-	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return false, err
 	}
@@ -136,7 +128,7 @@ func validateMatchingTemplates(clientset kubernetes.Interface, namespace string,
 		}
 	}
 
-	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return false, err
 	}
@@ -151,8 +143,8 @@ func validateMatchingTemplates(clientset kubernetes.Interface, namespace string,
 	return false, nil
 }
 
-func validateMatchingWorkloads(clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) (bool, error) {
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+func validateMatchingWorkloads(ctx context.Context, clientset kubernetes.Interface, namespace string, selector *metav1.LabelSelector) (bool, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: metav1.FormatLabelSelector(selector),
 	})
 	if err != nil {
@@ -165,39 +157,15 @@ func validateMatchingWorkloads(clientset kubernetes.Interface, namespace string,
 	return false, nil
 }
 
-func GetUnusedPdbs(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespacePdbs(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["Pdb"] = diff
-		case "resource":
-			appendResources(resources, "Pdb", namespace, diff)
-		}
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var pdbDetector = newDetector("Pdb", []string{"poddisruptionbudget", "pdb", "poddisruptionbudgets"}, NamespaceScoped, namespacedDetect(processNamespacePdbs),
+	func(clients Clients, namespace string) typedClient[*policyv1.PodDisruptionBudget, *policyv1.PodDisruptionBudgetList] {
+		return clients.Clientset.PolicyV1().PodDisruptionBudgets(namespace)
+	})
 
-	unusedPdbs, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(pdbDetector)
+}
 
-	return unusedPdbs, nil
+func GetUnusedPdbs(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{pdbDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
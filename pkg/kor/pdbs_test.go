@@ -115,7 +115,7 @@ func TestProcessNamespacePdbs(t *testing.T) {
 	totalUnusedPdbs := []ResourceInfo{}
 
 	for _, ns := range namespaces {
-		unusedPdbs, err := processNamespacePdbs(clientset, ns, &filters.Options{}, common.Opts{})
+		unusedPdbs, err := processNamespacePdbs(context.TODO(), clientset, ns, &filters.Options{}, common.Opts{})
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
@@ -146,7 +146,7 @@ func TestGetUnusedPdbsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedPdbs(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedPdbs(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedPdbsStructured: %v", err)
 	}
@@ -212,7 +212,7 @@ func TestFilterOwnerReferencedPdbs(t *testing.T) {
 
 	// Test without filter - should return both
 	filterOptsNoSkip := &filters.Options{IgnoreOwnerReferences: false}
-	unusedWithoutFilter, err := processNamespacePdbs(clientset, testNamespace, filterOptsNoSkip, common.Opts{})
+	unusedWithoutFilter, err := processNamespacePdbs(context.TODO(), clientset, testNamespace, filterOptsNoSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused PDBs: %v", err)
 	}
@@ -223,7 +223,7 @@ func TestFilterOwnerReferencedPdbs(t *testing.T) {
 
 	// Test with filter - should return only standalone
 	filterOptsWithSkip := &filters.Options{IgnoreOwnerReferences: true}
-	unusedWithFilter, err := processNamespacePdbs(clientset, testNamespace, filterOptsWithSkip, common.Opts{})
+	unusedWithFilter, err := processNamespacePdbs(context.TODO(), clientset, testNamespace, filterOptsWithSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused PDBs: %v", err)
 	}
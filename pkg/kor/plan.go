@@ -0,0 +1,344 @@
+package kor
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+// planAPIVersion is the format of the plan files written by WritePlan
+const planAPIVersion = "kor/v1"
+
+// Plan lists the unused resources a deletion would delete, so it can be
+// reviewed before ApplyPlan deletes them. Each item pins the object it was
+// made from, anything that changed since is left alone.
+type Plan struct {
+	APIVersion string     `json:"apiVersion"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	Items      []PlanItem `json:"items"`
+}
+
+// PlanItem is a resource to delete when applying a Plan
+type PlanItem struct {
+	Kind            string    `json:"kind"`
+	Namespace       string    `json:"namespace,omitempty"`
+	Name            string    `json:"name"`
+	UID             types.UID `json:"uid"`
+	ResourceVersion string    `json:"resourceVersion"`
+	Reason          string    `json:"reason,omitempty"`
+	// Hash is the SHA-256 of the object when it was planned, without its
+	// server-managed fields
+	Hash string `json:"hash"`
+	// Dependents are the objects the garbage collector removes along with
+	// it, unless the plan is applied with --cascade orphan
+	Dependents []CascadeNode `json:"dependents,omitempty"`
+}
+
+// NewPlan returns the plan deleting the findings of a report, along with the
+// dependents of each of them so they are reviewed too. Findings that can't be
+// fetched are left out of the plan and recorded in report.Errors, the ones
+// already gone are left out silently.
+func NewPlan(ctx context.Context, report *Report, clients Clients) *Plan {
+	plan := &Plan{APIVersion: planAPIVersion, CreatedAt: time.Now().UTC().Truncate(time.Second), Items: []PlanItem{}}
+	var planned []Finding
+	for _, finding := range report.Findings {
+		if finding.Deleted {
+			continue
+		}
+		detector, ok := LookupDetector(finding.Kind)
+		if !ok {
+			report.addError(finding.Kind, finding.Namespace, fmt.Errorf("resource type %q can't be planned", finding.Kind))
+			continue
+		}
+		obj, err := getObject(ctx, detector, clients, finding.Namespace, finding.Name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err == nil {
+			var item PlanItem
+			item, err = newPlanItem(finding, obj)
+			if err == nil {
+				plan.Items = append(plan.Items, item)
+				planned = append(planned, finding)
+				continue
+			}
+		}
+		report.addError(finding.Kind, finding.Namespace, fmt.Errorf("failed to plan %s %s: %w", finding.Kind, finding.Name, err))
+	}
+
+	preview, err := cascadePreview(ctx, clients, planned)
+	if err != nil {
+		report.addError("", "", fmt.Errorf("failed to preview the dependents removed with the planned resources: %w", err))
+	}
+	dependents := make(map[objectKey][]CascadeNode, len(preview))
+	for _, node := range preview {
+		dependents[node.key()] = node.Dependents
+	}
+	for i, item := range plan.Items {
+		plan.Items[i].Dependents = dependents[objectKey{item.Kind, item.Namespace, item.Name}]
+	}
+	return plan
+}
+
+// Cascade returns the planned resources that have dependents, with the tree
+// of their dependents
+func (p *Plan) Cascade() []CascadeNode {
+	var cascade []CascadeNode
+	for _, item := range p.Items {
+		if len(item.Dependents) != 0 {
+			cascade = append(cascade, CascadeNode{Kind: item.Kind, Namespace: item.Namespace, Name: item.Name, Dependents: item.Dependents})
+		}
+	}
+	return cascade
+}
+
+func newPlanItem(finding Finding, obj runtime.Object) (PlanItem, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return PlanItem{}, err
+	}
+	hash, err := objectHash(obj)
+	if err != nil {
+		return PlanItem{}, err
+	}
+	return PlanItem{
+		Kind:            finding.Kind,
+		Namespace:       finding.Namespace,
+		Name:            finding.Name,
+		UID:             accessor.GetUID(),
+		ResourceVersion: accessor.GetResourceVersion(),
+		Reason:          finding.Reason,
+		Hash:            hash,
+	}, nil
+}
+
+// objectHash hashes obj as it would be backed up, its JSON encoding having
+// sorted keys
+func objectHash(obj runtime.Object) (string, error) {
+	u, err := toBackupObject(obj)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(u.Object)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// WritePlan saves a plan to path as indented JSON
+func WritePlan(path string, plan *Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// ReadPlan loads a plan saved by WritePlan
+func ReadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{}
+	if err := json.Unmarshal(data, plan); err != nil {
+		return nil, fmt.Errorf("invalid plan %s: %w", path, err)
+	}
+	if plan.APIVersion != planAPIVersion {
+		return nil, fmt.Errorf("invalid plan %s: unsupported apiVersion %q, expected %q", path, plan.APIVersion, planAPIVersion)
+	}
+	return plan, nil
+}
+
+// planDrift tells why an object no longer matches its plan item, or returns
+// an empty string when it still does
+func planDrift(item PlanItem, obj runtime.Object) (string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+	if accessor.GetUID() != item.UID {
+		return "recreated since the plan was made", nil
+	}
+	if accessor.GetResourceVersion() != item.ResourceVersion {
+		return "changed since the plan was made", nil
+	}
+	hash, err := objectHash(obj)
+	if err != nil {
+		return "", err
+	}
+	if hash != item.Hash {
+		return "does not match the hash of the plan", nil
+	}
+	return "", nil
+}
+
+// ApplyPlan deletes the items of a plan whose object still has the UID,
+// resourceVersion and hash it was planned with, following opts.DryRun,
+// opts.Cascade, opts.BackupDir and the audit options. Items are deleted kind by kind in the same
+// order as a scan deletes them. Deletes are sent with these as preconditions, so an object
+// changed in the meantime is not deleted either. Reviewing the plan stands
+// for confirmation, nothing is asked, and the cascade reported is the one of
+// the plan. Items that changed are returned in Report.Skipped with the reason
+// why.
+func ApplyPlan(ctx context.Context, plan *Plan, clients Clients, opts common.Opts) (*Report, error) {
+	if err := ValidateDryRun(opts.DryRun); err != nil {
+		return nil, err
+	}
+	if err := ValidateCascade(opts.Cascade); err != nil {
+		return nil, err
+	}
+
+	audit, err := newAuditLog(opts, clients.Clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	backup := newBackupArchive(opts)
+	// the entries have the planned UID when the object is gone
+	record := func(result string, finding Finding, uid types.UID, obj runtime.Object, err error) {
+		entry := newAuditEntry(AuditDelete, result, finding, err)
+		entry.UID = uid
+		if err := audit.record(ctx, entry, obj); err != nil {
+			report.addError(finding.Kind, finding.Namespace, err)
+		}
+	}
+	skip := func(finding Finding, uid types.UID, obj runtime.Object, reason string) {
+		finding.Reason = reason
+		report.Skipped = append(report.Skipped, finding)
+		record(AuditSkipped, finding, uid, obj, nil)
+	}
+
+	items := slices.Clone(plan.Items)
+	slices.SortStableFunc(items, func(a, b PlanItem) int {
+		return cmp.Compare(deletionRank(a.Kind), deletionRank(b.Kind))
+	})
+	owners := make(map[objectKey]CascadeNode)
+	if opts.Cascade != CascadeOrphan {
+		for _, node := range plan.Cascade() {
+			owners[node.key()] = node
+		}
+	}
+	cascaded := make(map[objectKey]bool)
+	deleted := func(finding Finding, uid types.UID, obj runtime.Object) {
+		record(AuditSucceeded, finding, uid, obj, nil)
+		finding.Deleted = true
+		finding.DryRun = opts.DryRun != ""
+		report.Findings = append(report.Findings, finding)
+		if node, ok := owners[objectKey{finding.Kind, finding.Namespace, finding.Name}]; ok {
+			report.Cascade = append(report.Cascade, node)
+			node.walk(func(dependent CascadeNode) {
+				cascaded[dependent.key()] = true
+			})
+		}
+	}
+
+	for _, item := range items {
+		if ctx.Err() != nil {
+			report.Incomplete = true
+			report.addError("", "", fmt.Errorf("plan stopped before completion: %w", ctx.Err()))
+			break
+		}
+		report.addNamespace(item.Namespace)
+		finding := Finding{Kind: item.Kind, Namespace: item.Namespace, Name: item.Name, Reason: item.Reason}
+		if cascaded[objectKey{item.Kind, item.Namespace, item.Name}] {
+			// removed by the garbage collector along with an owner deleted before
+			finding.Deleted = true
+			finding.DryRun = opts.DryRun != ""
+			report.Findings = append(report.Findings, finding)
+			record(AuditCascaded, finding, item.UID, nil, nil)
+			continue
+		}
+		detector, ok := LookupDetector(item.Kind)
+		if !ok {
+			report.addError(item.Kind, item.Namespace, fmt.Errorf("resource type %q is not supported", item.Kind))
+			continue
+		}
+		resourceType := detector.Name()
+
+		obj, err := getObject(ctx, detector, clients, item.Namespace, item.Name)
+		if apierrors.IsNotFound(err) {
+			skip(finding, item.UID, nil, "deleted since the plan was made")
+			continue
+		}
+		var drift string
+		if err == nil {
+			drift, err = planDrift(item, obj)
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to get %s %s: %w", resourceType, item.Name, err)
+			report.addError(resourceType, item.Namespace, err)
+			report.Findings = append(report.Findings, finding)
+			record(AuditFailed, finding, item.UID, nil, err)
+			continue
+		}
+		if drift != "" {
+			skip(finding, item.UID, obj, drift)
+			continue
+		}
+
+		if opts.DryRun == DryRunClient {
+			deleted(finding, item.UID, obj)
+			continue
+		}
+		if backup != nil {
+			if err := backup.save(obj); err != nil {
+				err = fmt.Errorf("failed to back up %s %s, not deleting it: %w", resourceType, item.Name, err)
+				report.addError(resourceType, item.Namespace, err)
+				report.Findings = append(report.Findings, finding)
+				record(AuditFailed, finding, item.UID, obj, err)
+				continue
+			}
+		}
+
+		if opts.DryRun == "" {
+			fmt.Printf("Deleting %s %s in namespace %s\n", resourceType, item.Name, item.Namespace)
+		}
+		deleteOptions := metav1.DeleteOptions{
+			DryRun:            dryRunOption(opts.DryRun),
+			PropagationPolicy: propagationPolicy(opts.Cascade),
+			Preconditions:     &metav1.Preconditions{UID: &item.UID, ResourceVersion: &item.ResourceVersion},
+		}
+		err = detector.Delete(ctx, clients, item.Namespace, item.Name, deleteOptions)
+		switch {
+		case apierrors.IsConflict(err):
+			skip(finding, item.UID, obj, "changed since the plan was made")
+			continue
+		case apierrors.IsNotFound(err):
+			skip(finding, item.UID, obj, "deleted since the plan was made")
+			continue
+		case err != nil:
+			report.addError(resourceType, item.Namespace, deleteError(opts.DryRun, resourceType, finding, err))
+			report.Findings = append(report.Findings, finding)
+			record(AuditFailed, finding, item.UID, obj, err)
+			continue
+		}
+		deleted(finding, item.UID, obj)
+	}
+
+	if err := backup.Close(); err != nil {
+		report.addError("", "", fmt.Errorf("failed to close backup archive: %w", err))
+	}
+	report.BackupArchive = backup.Path()
+	if err := audit.Close(); err != nil {
+		report.addError("", "", fmt.Errorf("failed to close audit log: %w", err))
+	}
+	return report, nil
+}
@@ -0,0 +1,171 @@
+package kor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestPlanAndApply(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	for _, name := range []string{"configmap-changed", "configmap-gone", "configmap-kept", "configmap-unchanged"} {
+		configmap := CreateTestConfigmap(testNamespace, name, AppLabels)
+		configmap.UID = types.UID("uid-" + name)
+		configmap.ResourceVersion = "1"
+		_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), configmap, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+
+	clients := Clients{Clientset: clientset}
+	report, err := GetUnusedReport(context.TODO(), []Detector{configMapDetector}, &filters.Options{}, clients, common.Opts{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	plan := NewPlan(context.TODO(), report, clients)
+	if len(report.Errors) != 0 || len(plan.Items) != 4 {
+		t.Fatalf("Expected 4 planned configmaps, got %+v and errors %v", plan.Items, report.Errors)
+	}
+	for _, item := range plan.Items {
+		if item.UID == "" || item.ResourceVersion != "1" || item.Hash == "" {
+			t.Errorf("Expected the UID, resourceVersion and hash of %s in the plan, got %+v", item.Name, item)
+		}
+	}
+
+	path := t.TempDir() + "/plan.json"
+	if err := WritePlan(path, plan); err != nil {
+		t.Fatalf("Error writing plan: %v", err)
+	}
+	plan, err = ReadPlan(path)
+	if err != nil {
+		t.Fatalf("Error reading plan: %v", err)
+	}
+
+	changed, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "configmap-changed", v1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting fake configmap: %v", err)
+	}
+	changed.Data = map[string]string{"key": "value"}
+	changed.ResourceVersion = "2"
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Update(context.TODO(), changed, v1.UpdateOptions{}); err != nil {
+		t.Fatalf("Error updating fake configmap: %v", err)
+	}
+	if err := clientset.CoreV1().ConfigMaps(testNamespace).Delete(context.TODO(), "configmap-gone", v1.DeleteOptions{}); err != nil {
+		t.Fatalf("Error deleting fake configmap: %v", err)
+	}
+	// the fake clientset ignores preconditions, this one changes between the check and the delete
+	clientset.PrependReactor("delete", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deleteAction := action.(k8stesting.DeleteAction)
+		preconditions := deleteAction.GetDeleteOptions().Preconditions
+		if preconditions == nil || preconditions.UID == nil || preconditions.ResourceVersion == nil || *preconditions.ResourceVersion != "1" {
+			t.Errorf("Expected the delete of %s to have the UID and resourceVersion preconditions, got %+v", deleteAction.GetName(), preconditions)
+		}
+		if deleteAction.GetName() == "configmap-kept" {
+			return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "configmap-kept", nil)
+		}
+		return false, nil, nil
+	})
+
+	report, err = ApplyPlan(context.TODO(), plan, clients, common.Opts{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Errorf("Expected no errors, got %v", report.Errors)
+	}
+	expectedFindings := []Finding{{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-unchanged", Reason: plan.Items[3].Reason, Deleted: true}}
+	if !reflect.DeepEqual(report.Findings, expectedFindings) {
+		t.Errorf("Expected %+v, got %+v", expectedFindings, report.Findings)
+	}
+	expectedSkipped := []Finding{
+		{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-changed", Reason: "changed since the plan was made"},
+		{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-gone", Reason: "deleted since the plan was made"},
+		{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-kept", Reason: "changed since the plan was made"},
+	}
+	if !reflect.DeepEqual(report.Skipped, expectedSkipped) {
+		t.Errorf("Expected %+v, got %+v", expectedSkipped, report.Skipped)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "configmap-changed", v1.GetOptions{}); err != nil {
+		t.Errorf("Expected configmap-changed to be kept, got %v", err)
+	}
+}
+
+func TestReadPlanVersion(t *testing.T) {
+	path := t.TempDir() + "/plan.json"
+	if err := WritePlan(path, &Plan{APIVersion: "kor/v0"}); err != nil {
+		t.Fatalf("Error writing plan: %v", err)
+	}
+	if _, err := ReadPlan(path); err == nil {
+		t.Errorf("Expected an error for an unsupported plan version")
+	}
+}
+
+func TestPlanCascade(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	addCascadeTestObjects(t, clientset)
+
+	clients := Clients{Clientset: clientset}
+	report, err := GetUnusedReport(context.TODO(), []Detector{deploymentDetector}, &filters.Options{}, clients, common.Opts{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	plan := NewPlan(context.TODO(), report, clients)
+	if len(report.Errors) != 0 || len(plan.Items) != 1 {
+		t.Fatalf("Expected deployment-1 to be planned, got %+v and errors %v", plan.Items, report.Errors)
+	}
+	expectedCascade := []CascadeNode{{
+		Kind:      "Deployment",
+		Namespace: testNamespace,
+		Name:      "deployment-1",
+		Dependents: []CascadeNode{{
+			Kind:       "ReplicaSet",
+			Namespace:  testNamespace,
+			Name:       "deployment-1-abc",
+			Dependents: []CascadeNode{{Kind: "Pod", Namespace: testNamespace, Name: "deployment-1-abc-1"}},
+		}},
+	}}
+	if !reflect.DeepEqual(plan.Cascade(), expectedCascade) {
+		t.Errorf("Expected the planned cascade %+v, got %+v", expectedCascade, plan.Cascade())
+	}
+
+	// applying reports the cascade that was reviewed in the plan
+	if err := clientset.CoreV1().Pods(testNamespace).Delete(context.TODO(), "deployment-1-abc-1", v1.DeleteOptions{}); err != nil {
+		t.Fatalf("Error deleting fake pod: %v", err)
+	}
+	report, err = ApplyPlan(context.TODO(), plan, clients, common.Opts{DryRun: DryRunClient})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(report.Cascade, expectedCascade) {
+		t.Errorf("Expected the cascade %+v, got %+v", expectedCascade, report.Cascade)
+	}
+	report, err = ApplyPlan(context.TODO(), plan, clients, common.Opts{DryRun: DryRunClient, Cascade: CascadeOrphan})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Cascade) != 0 {
+		t.Errorf("Expected no cascade when orphaning the dependents, got %+v", report.Cascade)
+	}
+}
@@ -1,11 +1,7 @@
 package kor
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"os"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -15,8 +11,8 @@ import (
 	"github.com/yonahd/kor/pkg/filters"
 )
 
-func processNamespacePods(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	podsList, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespacePods(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	podsList, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -50,48 +46,19 @@ func processNamespacePods(clientset kubernetes.Interface, namespace string, filt
 		}
 
 	}
-	if opts.DeleteFlag {
-		if evictedPods, err = DeleteResource(evictedPods, clientset, namespace, "Pod", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete Pod %s in namespace %s: %v\n", evictedPods, namespace, err)
-		}
-	}
 
 	return evictedPods, nil
 }
 
-func GetUnusedPods(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespacePods(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["Pod"] = diff
-		case "resource":
-			appendResources(resources, "Pod", namespace, diff)
-		}
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var podDetector = newDetector("Pod", []string{"pod", "po", "pods"}, NamespaceScoped, namespacedDetect(processNamespacePods),
+	func(clients Clients, namespace string) typedClient[*corev1.Pod, *corev1.PodList] {
+		return clients.Clientset.CoreV1().Pods(namespace)
+	})
 
-	unusedPods, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(podDetector)
+}
 
-	return unusedPods, nil
+func GetUnusedPods(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{podDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -92,7 +92,7 @@ func createTestPods(t *testing.T) *fake.Clientset {
 
 func TestProcessNamespacePods(t *testing.T) {
 	clientset := createTestPods(t)
-	evictedPods, err := processNamespacePods(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	evictedPods, err := processNamespacePods(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -126,7 +126,7 @@ func TestGetUnusedPodsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedPods(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedPods(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedPodsStructured: %v", err)
 	}
@@ -200,7 +200,7 @@ func TestFilterOwnerReferencedPods(t *testing.T) {
 
 	// Test without filter - should return both
 	filterOptsNoSkip := &filters.Options{IgnoreOwnerReferences: false}
-	unusedWithoutFilter, err := processNamespacePods(clientset, testNamespace, filterOptsNoSkip, common.Opts{})
+	unusedWithoutFilter, err := processNamespacePods(context.TODO(), clientset, testNamespace, filterOptsNoSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused pods: %v", err)
 	}
@@ -211,7 +211,7 @@ func TestFilterOwnerReferencedPods(t *testing.T) {
 
 	// Test with filter - should return only standalone
 	filterOptsWithSkip := &filters.Options{IgnoreOwnerReferences: true}
-	unusedWithFilter, err := processNamespacePods(clientset, testNamespace, filterOptsWithSkip, common.Opts{})
+	unusedWithFilter, err := processNamespacePods(context.TODO(), clientset, testNamespace, filterOptsWithSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused pods: %v", err)
 	}
@@ -1,13 +1,11 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
 	"fmt"
-	"os"
 
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -19,26 +17,26 @@ import (
 //go:embed exceptions/priorityclasses/priorityclasses.json
 var priorityClassesConfig []byte
 
-func retrieveUsedPriorityClasses(clientset kubernetes.Interface) ([]string, error) {
-	pods, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+func retrieveUsedPriorityClasses(ctx context.Context, clientset kubernetes.Interface) (references, error) {
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list Pods: %v", err)
 	}
 
-	var usedPriorityClasses []string
+	usedPriorityClasses := references{}
 
 	// Iterate through each Pod and check for PriorityClass usage
 	for _, pod := range pods.Items {
 		if pod.Spec.PriorityClassName != "" {
-			usedPriorityClasses = append(usedPriorityClasses, pod.Spec.PriorityClassName)
+			usedPriorityClasses.add(pod.Spec.PriorityClassName, "Pod", pod.Namespace, pod.Name, "spec.priorityClassName")
 		}
 	}
 
 	return usedPriorityClasses, nil
 }
 
-func processPriorityClasses(clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	pcs, err := clientset.SchedulingV1().PriorityClasses().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processPriorityClasses(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	pcs, err := clientset.SchedulingV1().PriorityClasses().List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -83,53 +81,27 @@ func processPriorityClasses(clientset kubernetes.Interface, filterOpts *filters.
 		priorityClassNames = append(priorityClassNames, pc.Name)
 	}
 
-	usedPriorityClasses, err := retrieveUsedPriorityClasses(clientset)
+	usedPriorityClasses, err := retrieveUsedPriorityClasses(ctx, clientset)
 	if err != nil {
 		return nil, err
 	}
 
-	diff := CalculateResourceDifference(usedPriorityClasses, priorityClassNames)
+	diff := CalculateResourceDifference(usedPriorityClasses.names(), priorityClassNames)
 	for _, name := range diff {
 		unusedPriorityClasses = append(unusedPriorityClasses, ResourceInfo{Name: name, Reason: "Not in Use"})
 	}
 	return unusedPriorityClasses, nil
 }
 
-func GetUnusedPriorityClasses(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	diff, err := processPriorityClasses(clientset, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to process priorityClasses: %v\n", err)
-	}
-	if opts.DeleteFlag {
-		if diff, err = DeleteResource(diff, clientset, "", "PriorityClass", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete PriorityClass %s: %v\n", diff, err)
-		}
-	}
-	switch opts.GroupBy {
-	case "namespace":
-		resources[""] = make(map[string][]ResourceInfo)
-		resources[""]["PriorityClass"] = diff
-	case "resource":
-		appendResources(resources, "PriorityClass", "", diff)
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var priorityClassDetector = newDetector("PriorityClass", []string{"priorityclass", "pc", "priorityclasses"}, ClusterScoped, clusterDetect(processPriorityClasses),
+	func(clients Clients, _ string) typedClient[*schedulingv1.PriorityClass, *schedulingv1.PriorityClassList] {
+		return clients.Clientset.SchedulingV1().PriorityClasses()
+	})
 
-	unusedPriorityClasses, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(priorityClassDetector)
+}
 
-	return unusedPriorityClasses, nil
+func GetUnusedPriorityClasses(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{priorityClassDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -41,20 +41,20 @@ func TestRetrieveUsedPriorityClasses(t *testing.T) {
 		t.Fatalf("Error creating fake Pod: %v", err)
 	}
 
-	usedPriorityClasses, err := retrieveUsedPriorityClasses(clientset)
+	usedPriorityClasses, err := retrieveUsedPriorityClasses(context.TODO(), clientset)
 
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
-	if !contains(usedPriorityClasses, "test-pc1") {
+	if !contains(usedPriorityClasses.names(), "test-pc1") {
 		t.Errorf("Expected 'test-pc1', got %v", usedPriorityClasses)
 	}
 }
 
 func TestProcessPriorityClasses(t *testing.T) {
 	clientset := createTestPriorityClass(t)
-	unusedPriorityClasses, err := processPriorityClasses(clientset, &filters.Options{})
+	unusedPriorityClasses, err := processPriorityClasses(context.TODO(), clientset, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -80,7 +80,7 @@ func TestGetUnusedPriorityClassesStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedPriorityClasses(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedPriorityClasses(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedPriorityClasses: %v", err)
 	}
@@ -130,7 +130,7 @@ func TestFilterOwnerReferencedPriorityClasses(t *testing.T) {
 
 	// Test without filter - should return both
 	filterOptsNoSkip := &filters.Options{IgnoreOwnerReferences: false}
-	unusedWithoutFilter, err := processPriorityClasses(clientset, filterOptsNoSkip)
+	unusedWithoutFilter, err := processPriorityClasses(context.TODO(), clientset, filterOptsNoSkip)
 	if err != nil {
 		t.Fatalf("Error retrieving unused PriorityClasses: %v", err)
 	}
@@ -141,7 +141,7 @@ func TestFilterOwnerReferencedPriorityClasses(t *testing.T) {
 
 	// Test with filter - should return only standalone
 	filterOptsWithSkip := &filters.Options{IgnoreOwnerReferences: true}
-	unusedWithFilter, err := processPriorityClasses(clientset, filterOptsWithSkip)
+	unusedWithFilter, err := processPriorityClasses(context.TODO(), clientset, filterOptsWithSkip)
 	if err != nil {
 		t.Fatalf("Error retrieving unused PriorityClasses: %v", err)
 	}
@@ -176,7 +176,7 @@ func TestSkipGlobalDefaultPriorityClasses(t *testing.T) {
 	}
 
 	// Process PriorityClasses - global default should be skipped
-	unusedPriorityClasses, err := processPriorityClasses(clientset, &filters.Options{})
+	unusedPriorityClasses, err := processPriorityClasses(context.TODO(), clientset, &filters.Options{})
 	if err != nil {
 		t.Fatalf("Error processing PriorityClasses: %v", err)
 	}
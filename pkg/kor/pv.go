@@ -1,12 +1,9 @@
 package kor
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"os"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -15,8 +12,8 @@ import (
 	"github.com/yonahd/kor/pkg/filters"
 )
 
-func processPvs(clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	pvs, err := clientset.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processPvs(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	pvs, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -50,41 +47,15 @@ func processPvs(clientset kubernetes.Interface, filterOpts *filters.Options) ([]
 
 }
 
-func GetUnusedPvs(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	diff, err := processPvs(clientset, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to process pvs: %v\n", err)
-	}
-	if opts.DeleteFlag {
-		if diff, err = DeleteResource(diff, clientset, "", "PV", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete PV %s: %v\n", diff, err)
-		}
-	}
-	switch opts.GroupBy {
-	case "namespace":
-		resources[""] = make(map[string][]ResourceInfo)
-		resources[""]["Pv"] = diff
-	case "resource":
-		appendResources(resources, "Pv", "", diff)
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var pvDetector = newDetector("Pv", []string{"persistentvolume", "pv", "persistentvolumes"}, ClusterScoped, clusterDetect(processPvs),
+	func(clients Clients, _ string) typedClient[*corev1.PersistentVolume, *corev1.PersistentVolumeList] {
+		return clients.Clientset.CoreV1().PersistentVolumes()
+	})
 
-	unusedPvs, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(pvDetector)
+}
 
-	return unusedPvs, nil
+func GetUnusedPvs(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{pvDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
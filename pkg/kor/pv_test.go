@@ -45,7 +45,7 @@ func createTestPvs(t *testing.T) *fake.Clientset {
 
 func TestProcessPvs(t *testing.T) {
 	clientset := createTestPvs(t)
-	usedPvs, err := processPvs(clientset, &filters.Options{})
+	usedPvs, err := processPvs(context.TODO(), clientset, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -71,7 +71,7 @@ func TestGetUnusedPvs(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedPvs(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedPvs(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedPvs: %v", err)
 	}
@@ -1,12 +1,10 @@
 package kor
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -15,31 +13,38 @@ import (
 	"github.com/yonahd/kor/pkg/filters"
 )
 
-func retrieveUsedPvcs(clientset kubernetes.Interface, namespace string) ([]string, error) {
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+// addPodSpecPvcs adds the PVCs mounted by the volumes of a pod spec,
+// source.Field being the path of the spec
+func addPodSpecPvcs(used references, spec *corev1.PodSpec, source Reference) {
+	for i, volume := range spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			used.addReference(volume.PersistentVolumeClaim.ClaimName, source.at(fmt.Sprintf(".volumes[%d].persistentVolumeClaim", i), nil))
+		}
+	}
+}
+
+func retrieveUsedPvcs(ctx context.Context, clientset kubernetes.Interface, namespace string) (references, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		fmt.Printf("Failed to list Pods: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to list pods: %v", err)
 	}
-	var usedPvcs []string
+	usedPvcs := references{}
 	// Iterate through each Pod and check for PVC usage
 	for _, pod := range pods.Items {
-		for _, volume := range pod.Spec.Volumes {
-			if volume.PersistentVolumeClaim != nil {
-				usedPvcs = append(usedPvcs, volume.PersistentVolumeClaim.ClaimName)
-			}
+		addPodSpecPvcs(usedPvcs, &pod.Spec, Reference{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name, Field: "spec"})
+		for i, volume := range pod.Spec.Volumes {
 			// Include ephemeral PVC
 			if volume.Ephemeral != nil && volume.Ephemeral.VolumeClaimTemplate != nil {
 				// https://kubernetes.io/docs/concepts/storage/ephemeral-volumes/#persistentvolumeclaim-naming
-				usedPvcs = append(usedPvcs, pod.GetObjectMeta().GetName()+"-"+volume.Name)
+				usedPvcs.add(pod.GetObjectMeta().GetName()+"-"+volume.Name, "Pod", pod.Namespace, pod.Name, fmt.Sprintf("spec.volumes[%d].ephemeral", i))
 			}
 		}
 	}
 	return usedPvcs, err
 }
 
-func processNamespacePvcs(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespacePvcs(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -64,13 +69,13 @@ func processNamespacePvcs(clientset kubernetes.Interface, namespace string, filt
 		pvcNames = append(pvcNames, pvc.Name)
 	}
 
-	usedPvcs, err := retrieveUsedPvcs(clientset, namespace)
+	usedPvcs, err := retrieveUsedPvcs(ctx, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
 
 	var diff []ResourceInfo
-	for _, name := range CalculateResourceDifference(usedPvcs, pvcNames) {
+	for _, name := range CalculateResourceDifference(usedPvcs.names(), pvcNames) {
 		reason := "PVC is not in use"
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
@@ -80,47 +85,33 @@ func processNamespacePvcs(clientset kubernetes.Interface, namespace string, filt
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
 
-	if opts.DeleteFlag {
-		if diff, err = DeleteResource(diff, clientset, namespace, "PVC", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete PVC %s in namespace %s: %v\n", diff, namespace, err)
-		}
-	}
 	return diff, nil
 }
 
-func GetUnusedPvcs(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespacePvcs(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["Pvc"] = diff
-		case "resource":
-			appendResources(resources, "Pvc", namespace, diff)
-		}
+// retrievePvcVolumes references the PersistentVolumes bound to the PVCs of a namespace
+func retrievePvcVolumes(ctx context.Context, clientset kubernetes.Interface, namespace string) (references, error) {
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PVCs: %v", err)
 	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
+	used := references{}
+	for _, pvc := range pvcs.Items {
+		if pvc.Spec.VolumeName != "" {
+			used.add(pvc.Spec.VolumeName, "PersistentVolumeClaim", pvc.Namespace, pvc.Name, "spec.volumeName")
 		}
 	}
+	return used, nil
+}
 
-	unusedPvcs, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+var pvcDetector = newDetector("Pvc", []string{"persistentvolumeclaim", "pvc", "persistentvolumeclaims"}, NamespaceScoped, namespacedDetect(processNamespacePvcs),
+	func(clients Clients, namespace string) typedClient[*corev1.PersistentVolumeClaim, *corev1.PersistentVolumeClaimList] {
+		return clients.Clientset.CoreV1().PersistentVolumeClaims(namespace)
+	})
+
+func init() {
+	MustRegisterDetector(pvcDetector)
+}
 
-	return unusedPvcs, nil
+func GetUnusedPvcs(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{pvcDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -74,7 +74,8 @@ func createTestPvcs(t *testing.T) *fake.Clientset {
 
 func TestRetrieveUsedPvcs(t *testing.T) {
 	clientset := createTestPvcs(t)
-	usedPvcs, err := retrieveUsedPvcs(clientset, testNamespace)
+	usedPvcReferences, err := retrieveUsedPvcs(context.TODO(), clientset, testNamespace)
+	usedPvcs := usedPvcReferences.names()
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -83,18 +84,18 @@ func TestRetrieveUsedPvcs(t *testing.T) {
 		t.Errorf("Expected 2 used pvc, got %d", len(usedPvcs))
 	}
 
-	if usedPvcs[0] != "test-pvc1" {
-		t.Errorf("Expected 'test-pvc1', got %s", usedPvcs[0])
+	if !contains(usedPvcs, "test-pvc1") {
+		t.Errorf("Expected 'test-pvc1', got %v", usedPvcs)
 	}
 
-	if usedPvcs[1] != "test-pod-ephemeral-storage-test-ephemeral-volume" {
-		t.Errorf("Expected 'test-pod-ephemeral-storage-test-ephemeral-volume', got %s", usedPvcs[1])
+	if !contains(usedPvcs, "test-pod-ephemeral-storage-test-ephemeral-volume") {
+		t.Errorf("Expected 'test-pod-ephemeral-storage-test-ephemeral-volume', got %v", usedPvcs)
 	}
 }
 
 func TestProcessNamespacePvcs(t *testing.T) {
 	clientset := createTestPvcs(t)
-	usedPvcs, err := processNamespacePvcs(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	usedPvcs, err := processNamespacePvcs(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -120,7 +121,7 @@ func TestGetUnusedPvcsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedPvcs(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedPvcs(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedPvcsStructured: %v", err)
 	}
@@ -181,7 +182,7 @@ func TestFilterOwnerReferencedPvcs(t *testing.T) {
 
 	// Test without filter - should return both
 	filterOptsNoSkip := &filters.Options{IgnoreOwnerReferences: false}
-	unusedWithoutFilter, err := processNamespacePvcs(clientset, testNamespace, filterOptsNoSkip, common.Opts{})
+	unusedWithoutFilter, err := processNamespacePvcs(context.TODO(), clientset, testNamespace, filterOptsNoSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused PVCs: %v", err)
 	}
@@ -192,7 +193,7 @@ func TestFilterOwnerReferencedPvcs(t *testing.T) {
 
 	// Test with filter - should return only standalone
 	filterOptsWithSkip := &filters.Options{IgnoreOwnerReferences: true}
-	unusedWithFilter, err := processNamespacePvcs(clientset, testNamespace, filterOptsWithSkip, common.Opts{})
+	unusedWithFilter, err := processNamespacePvcs(context.TODO(), clientset, testNamespace, filterOptsWithSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused PVCs: %v", err)
 	}
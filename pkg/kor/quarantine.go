@@ -0,0 +1,154 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+// QuarantineLabel marks the unused resources put in quarantine by kor
+// quarantine, its value being the time they were marked
+const QuarantineLabel = "kor/quarantined-at"
+
+// quarantineTimeFormat is the layout of the quarantine time, label values
+// can't hold the colons of RFC 3339
+const quarantineTimeFormat = "20060102T150405Z"
+
+// Quarantine modes of a scan. Marking labels the unused resources, reaping
+// deletes the ones quarantined for longer than the grace period. Both
+// release the quarantined resources that are used again.
+const (
+	QuarantineMark = "mark"
+	QuarantineReap = "reap"
+)
+
+func validateQuarantine(opts common.Opts) error {
+	switch opts.Quarantine {
+	case "", QuarantineReap:
+		return nil
+	case QuarantineMark:
+		if opts.DeleteFlag {
+			return errors.New("resources can't be quarantined and deleted in the same run")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid quarantine mode %q, must be %q or %q", opts.Quarantine, QuarantineMark, QuarantineReap)
+	}
+}
+
+func quarantinePatch(value *string) []byte {
+	if value == nil {
+		return fmt.Appendf(nil, `{"metadata":{"labels":{%q:null}}}`, QuarantineLabel)
+	}
+	return fmt.Appendf(nil, `{"metadata":{"labels":{%q:%q}}}`, QuarantineLabel, *value)
+}
+
+// quarantineFindings reconciles the quarantine of the objects a detector
+// scanned in a namespace with its findings. The findings already in quarantine
+// get the time they were marked, and the other ones are marked when opts is in
+// mark mode. Quarantined objects that are no longer unused are released by
+// removing their marker and returned. Nothing is changed in a dry run. The
+// markers added and removed are recorded to audit.
+//
+// The findings leave out the objects hidden by the filters and exceptions,
+// so a quarantined object missing from them is only released when
+// detectUnfiltered, running the detector without those, doesn't find it
+// unused either.
+func quarantineFindings(ctx context.Context, findings []Finding, clients Clients, detector Detector, namespace string, opts common.Opts, now time.Time, audit *auditLog, detectUnfiltered func() ([]ResourceInfo, error)) ([]Finding, []Finding, error) {
+	resourceType := detector.Name()
+	quarantined, err := listObjects(ctx, detector, clients, namespace, QuarantineLabel)
+	if err != nil {
+		return findings, nil, fmt.Errorf("failed to list quarantined %s: %w", resourceType, err)
+	}
+
+	var errs []error
+	record := func(action, result string, finding Finding, obj runtime.Object, err error) {
+		if err := audit.record(ctx, newAuditEntry(action, result, finding, err), obj); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	quarantinedAt := make(map[string]time.Time, len(quarantined))
+	for _, object := range quarantined {
+		markedAt, err := time.Parse(quarantineTimeFormat, object.GetLabels()[QuarantineLabel])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s label on %s %s in namespace %s: %w", QuarantineLabel, resourceType, object.GetName(), object.GetNamespace(), err))
+		}
+		quarantinedAt[object.GetName()] = markedAt
+	}
+
+	unused := make(map[string]bool, len(findings))
+	for i, finding := range findings {
+		unused[finding.Name] = true
+		if markedAt, ok := quarantinedAt[finding.Name]; ok {
+			findings[i].QuarantinedAt = markedAt
+			continue
+		}
+		if opts.Quarantine != QuarantineMark {
+			continue
+		}
+		markedAt := now.UTC().Truncate(time.Second)
+		if opts.DryRun == "" {
+			value := markedAt.Format(quarantineTimeFormat)
+			if err := patchObject(ctx, detector, clients, finding.Namespace, finding.Name, quarantinePatch(&value)); err != nil {
+				errs = append(errs, fmt.Errorf("failed to quarantine %s %s in namespace %s: %w", resourceType, finding.Name, finding.Namespace, err))
+				record(AuditQuarantine, AuditFailed, finding, nil, err)
+				continue
+			}
+			record(AuditQuarantine, AuditSucceeded, finding, audit.object(func() (runtime.Object, error) {
+				return getObject(ctx, detector, clients, finding.Namespace, finding.Name)
+			}), nil)
+		}
+		findings[i].QuarantinedAt = markedAt
+	}
+
+	if slices.ContainsFunc(quarantined, func(object metav1.Object) bool { return !unused[object.GetName()] }) {
+		diff, err := detectUnfiltered()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to check whether the quarantined %s are in use: %w", resourceType, err))
+			return findings, nil, errors.Join(errs...)
+		}
+		for _, info := range diff {
+			unused[info.Name] = true
+		}
+	}
+
+	slices.SortFunc(quarantined, func(a, b metav1.Object) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+	var released []Finding
+	for _, object := range quarantined {
+		if unused[object.GetName()] {
+			continue
+		}
+		finding := Finding{Kind: resourceType, Namespace: object.GetNamespace(), Name: object.GetName(), Reason: "No longer unused, released from quarantine"}
+		if opts.DryRun == "" {
+			obj, _ := object.(runtime.Object)
+			if err := patchObject(ctx, detector, clients, object.GetNamespace(), object.GetName(), quarantinePatch(nil)); err != nil {
+				errs = append(errs, fmt.Errorf("failed to release %s %s in namespace %s from quarantine: %w", resourceType, object.GetName(), object.GetNamespace(), err))
+				record(AuditRelease, AuditFailed, finding, obj, err)
+				continue
+			}
+			record(AuditRelease, AuditSucceeded, finding, obj, nil)
+		}
+		released = append(released, finding)
+	}
+
+	return findings, released, errors.Join(errs...)
+}
+
+// deletable tells whether a finding can be deleted. When reaping, only the
+// findings quarantined for longer than the grace period can.
+func deletable(finding Finding, opts common.Opts) bool {
+	if opts.Quarantine != QuarantineReap {
+		return true
+	}
+	return !finding.QuarantinedAt.IsZero() && time.Since(finding.QuarantinedAt) >= opts.QuarantineGracePeriod
+}
@@ -0,0 +1,152 @@
+package kor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestQuarantineAndReap(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	monthAgo := time.Now().UTC().Add(-30 * 24 * time.Hour).Truncate(time.Second)
+	for _, configmap := range []struct {
+		name   string
+		labels map[string]string
+	}{
+		{name: "configmap-new", labels: map[string]string{}},
+		{name: "configmap-quarantined", labels: map[string]string{QuarantineLabel: monthAgo.Format(quarantineTimeFormat)}},
+		{name: "configmap-used-again", labels: map[string]string{QuarantineLabel: monthAgo.Format(quarantineTimeFormat), "kor/used": "true"}},
+	} {
+		_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, configmap.name, configmap.labels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+
+	opts := common.Opts{Quarantine: QuarantineMark}
+	report, err := GetUnusedReport(context.TODO(), []Detector{configMapDetector}, &filters.Options{}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("Expected no scan errors, got %v", report.Errors)
+	}
+
+	quarantinedAt := map[string]time.Time{}
+	for _, finding := range report.Findings {
+		quarantinedAt[finding.Name] = finding.QuarantinedAt
+	}
+	if quarantinedAt["configmap-new"].IsZero() || time.Since(quarantinedAt["configmap-new"]) > time.Minute {
+		t.Errorf("Expected configmap-new to be quarantined now, got %v", quarantinedAt["configmap-new"])
+	}
+	if !quarantinedAt["configmap-quarantined"].Equal(monthAgo) {
+		t.Errorf("Expected configmap-quarantined to keep its quarantine time %v, got %v", monthAgo, quarantinedAt["configmap-quarantined"])
+	}
+	expectedReleased := []Finding{{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-used-again", Reason: "No longer unused, released from quarantine"}}
+	if !reflect.DeepEqual(report.Released, expectedReleased) {
+		t.Errorf("Expected %v, got %v", expectedReleased, report.Released)
+	}
+
+	configmap, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "configmap-new", v1.GetOptions{})
+	if err != nil || configmap.Labels[QuarantineLabel] == "" {
+		t.Errorf("Expected configmap-new to be labelled, got %v, %v", configmap, err)
+	}
+	configmap, err = clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "configmap-used-again", v1.GetOptions{})
+	if _, ok := configmap.Labels[QuarantineLabel]; err != nil || ok {
+		t.Errorf("Expected the quarantine label of configmap-used-again to be removed, got %v, %v", configmap, err)
+	}
+
+	opts = common.Opts{Quarantine: QuarantineReap, QuarantineGracePeriod: 14 * 24 * time.Hour, DeleteFlag: true, NoInteractive: true}
+	report, err = GetUnusedReport(context.TODO(), []Detector{configMapDetector}, &filters.Options{}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, finding := range report.Findings {
+		if finding.Deleted != (finding.Name == "configmap-quarantined") {
+			t.Errorf("Expected only configmap-quarantined to be reaped, got %+v", finding)
+		}
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "configmap-quarantined", v1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected configmap-quarantined to be deleted, got %v", err)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "configmap-new", v1.GetOptions{}); err != nil {
+		t.Errorf("Expected configmap-new to be kept until its grace period is over, got %v", err)
+	}
+}
+
+func TestValidateQuarantine(t *testing.T) {
+	if err := validateQuarantine(common.Opts{Quarantine: QuarantineMark, DeleteFlag: true}); err == nil {
+		t.Errorf("Expected an error when quarantining and deleting in the same run")
+	}
+	if err := validateQuarantine(common.Opts{Quarantine: "unknown"}); err == nil {
+		t.Errorf("Expected an error for an unknown quarantine mode")
+	}
+}
+
+func TestQuarantineKeepsFilteredObjects(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	markedAt := time.Now().UTC().Add(-24 * time.Hour).Format(quarantineTimeFormat)
+	for _, configmap := range []struct {
+		name   string
+		labels map[string]string
+	}{
+		{name: "configmap-excluded", labels: map[string]string{QuarantineLabel: markedAt, "team": "infra"}},
+		{name: "configmap-exception", labels: map[string]string{QuarantineLabel: markedAt}},
+		{name: "configmap-used-again", labels: map[string]string{QuarantineLabel: markedAt, "kor/used": "true"}},
+	} {
+		_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, configmap.name, configmap.labels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+
+	path := t.TempDir() + "/exceptions.yaml"
+	if err := AddException(path, Exception{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-exception"}); err != nil {
+		t.Fatalf("Error adding exception: %v", err)
+	}
+
+	opts := common.Opts{Quarantine: QuarantineMark, ExceptionsFile: path}
+	filterOpts := &filters.Options{ExcludeLabels: []string{"team=infra"}}
+	report, err := GetUnusedReport(context.TODO(), []Detector{configMapDetector}, filterOpts, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("Expected no scan errors, got %v", report.Errors)
+	}
+	if len(report.Findings) != 0 {
+		t.Errorf("Expected the filtered configmaps not to be reported, got %+v", report.Findings)
+	}
+
+	// only the configmap found in use is released, the filtered ones are still unused
+	expectedReleased := []Finding{{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-used-again", Reason: "No longer unused, released from quarantine"}}
+	if !reflect.DeepEqual(report.Released, expectedReleased) {
+		t.Errorf("Expected %v, got %v", expectedReleased, report.Released)
+	}
+	for _, name := range []string{"configmap-excluded", "configmap-exception"} {
+		configmap, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), name, v1.GetOptions{})
+		if err != nil || configmap.Labels[QuarantineLabel] != markedAt {
+			t.Errorf("Expected %s to stay quarantined, got %v, %v", name, configmap, err)
+		}
+	}
+}
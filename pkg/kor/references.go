@@ -0,0 +1,93 @@
+package kor
+
+import (
+	"context"
+	"maps"
+	"slices"
+)
+
+// Reference is an object using a resource, and the field of the object it is
+// used from, e.g. the pod mounting a ConfigMap at spec.volumes[0].configMap
+type Reference struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Field     string `json:"field"`
+	// Optional is set when the object tolerates the resource missing, e.g. an
+	// optional configMapKeyRef
+	Optional bool `json:"optional,omitempty"`
+}
+
+// at returns the reference from a field below r.Field
+func (r Reference) at(field string, optional *bool) Reference {
+	r.Field += field
+	r.Optional = optional != nil && *optional
+	return r
+}
+
+// references maps the name of each resource used to the references to it
+type references map[string][]Reference
+
+func (r references) add(name, kind, namespace, objectName, field string) {
+	r.addReference(name, Reference{Kind: kind, Namespace: namespace, Name: objectName, Field: field})
+}
+
+func (r references) addReference(name string, reference Reference) {
+	if !slices.Contains(r[name], reference) {
+		r[name] = append(r[name], reference)
+	}
+}
+
+func (r references) merge(other references) {
+	for name, refs := range other {
+		r[name] = append(r[name], refs...)
+	}
+}
+
+// names returns the names of the resources used, sorted
+func (r references) names() []string {
+	return slices.Sorted(maps.Keys(r))
+}
+
+// objectIndex tells whether the objects referenced exist, kor counting the
+// names referenced whether they exist or not. Each kind is listed once per
+// namespace.
+type objectIndex struct {
+	ctx     context.Context
+	clients Clients
+	names   map[string]map[string]bool
+}
+
+func newObjectIndex(ctx context.Context, clients Clients) *objectIndex {
+	return &objectIndex{ctx: ctx, clients: clients, names: map[string]map[string]bool{}}
+}
+
+// exists tells whether an object exists, kinds kor doesn't know or can't
+// list being assumed to
+func (i *objectIndex) exists(kind, namespace, name string) (bool, error) {
+	detector, ok := LookupDetector(kind)
+	if !ok {
+		return true, nil
+	}
+	lister, ok := detector.(Lister)
+	if !ok {
+		return true, nil
+	}
+	if detector.Scope() == ClusterScoped {
+		namespace = ""
+	}
+	key := detector.Name() + "/" + namespace
+	names, ok := i.names[key]
+	if !ok {
+		objects, err := lister.List(i.ctx, i.clients, namespace, "")
+		if err != nil {
+			return false, err
+		}
+		names = make(map[string]bool, len(objects))
+		for _, obj := range objects {
+			names[obj.GetName()] = true
+		}
+		i.names[key] = names
+	}
+	return names[name], nil
+}
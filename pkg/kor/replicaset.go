@@ -1,12 +1,9 @@
 package kor
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"os"
 
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
@@ -14,8 +11,8 @@ import (
 	"github.com/yonahd/kor/pkg/filters"
 )
 
-func processNamespaceReplicaSets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	replicaSetList, err := clientset.AppsV1().ReplicaSets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespaceReplicaSets(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	replicaSetList, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -44,47 +41,18 @@ func processNamespaceReplicaSets(clientset kubernetes.Interface, namespace strin
 			unusedReplicaSetNames = append(unusedReplicaSetNames, ResourceInfo{Name: replicaSet.Name, Reason: reason})
 		}
 	}
-	if opts.DeleteFlag {
-		if unusedReplicaSetNames, err = DeleteResource(unusedReplicaSetNames, clientset, namespace, "ReplicaSet", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete ReplicaSet %s in namespace %s: %v\n", unusedReplicaSetNames, namespace, err)
-		}
-	}
 	return unusedReplicaSetNames, nil
 }
 
-func GetUnusedReplicaSets(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceReplicaSets(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["ReplicaSet"] = diff
-		case "resource":
-			appendResources(resources, "ReplicaSet", namespace, diff)
-		}
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var replicaSetDetector = newDetector("ReplicaSet", []string{"replicaset", "rs", "replicasets"}, NamespaceScoped, namespacedDetect(processNamespaceReplicaSets),
+	func(clients Clients, namespace string) typedClient[*appsv1.ReplicaSet, *appsv1.ReplicaSetList] {
+		return clients.Clientset.AppsV1().ReplicaSets(namespace)
+	})
 
-	unusedReplicaSets, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(replicaSetDetector)
+}
 
-	return unusedReplicaSets, nil
+func GetUnusedReplicaSets(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{replicaSetDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -67,7 +67,7 @@ func TestProcessNamespaceReplicaSets(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedReplicaSets(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedReplicaSets(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedReplicaSetsStructured: %v", err)
 	}
@@ -144,7 +144,7 @@ func TestFilterDeploymentOwnedReplicaSets(t *testing.T) {
 
 	// Test without filter - should return both
 	filterOptsNoSkip := &filters.Options{IgnoreOwnerReferences: false}
-	unusedWithoutFilter, err := processNamespaceReplicaSets(clientset, testNamespace, filterOptsNoSkip, common.Opts{})
+	unusedWithoutFilter, err := processNamespaceReplicaSets(context.TODO(), clientset, testNamespace, filterOptsNoSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused replica sets: %v", err)
 	}
@@ -155,7 +155,7 @@ func TestFilterDeploymentOwnedReplicaSets(t *testing.T) {
 
 	// Test with filter - should return only standalone
 	filterOptsWithSkip := &filters.Options{IgnoreOwnerReferences: true}
-	unusedWithFilter, err := processNamespaceReplicaSets(clientset, testNamespace, filterOptsWithSkip, common.Opts{})
+	unusedWithFilter, err := processNamespaceReplicaSets(context.TODO(), clientset, testNamespace, filterOptsWithSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused replica sets: %v", err)
 	}
@@ -0,0 +1,144 @@
+package kor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+// Finding is a single unused resource found by a Detector
+type Finding struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason,omitempty"`
+	Deleted   bool   `json:"deleted,omitempty"`
+	// DryRun is set when Deleted only reports what a --dry-run would delete
+	DryRun bool `json:"dryRun,omitempty"`
+	// QuarantinedAt is the time kor quarantine marked the resource
+	QuarantinedAt time.Time `json:"quarantinedAt,omitzero"`
+}
+
+// displayName is the name shown in the output, suffixed with "-DELETED" once
+// the resource is deleted
+func (f Finding) displayName() string {
+	switch {
+	case f.Deleted && f.DryRun:
+		return f.Name + "-DELETED (dry run)"
+	case f.Deleted:
+		return f.Name + "-DELETED"
+	default:
+		return f.Name
+	}
+}
+
+// removed reports whether the resource is gone from the cluster, a dry run
+// leaving it in place
+func (f Finding) removed() bool {
+	return f.Deleted && !f.DryRun
+}
+
+// Report is the typed result of a scan. It is rendered with FormatReport,
+// or consumed directly by library users and the exporter.
+type Report struct {
+	Findings []Finding `json:"findings"`
+	// Namespaces lists the scanned namespaces in scan order, "" standing for the cluster-scoped kinds
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Incomplete is set when the scan was cancelled or timed out before every
+	// detector ran. Findings then only cover the namespaces and kinds scanned in time.
+	Incomplete bool `json:"incomplete,omitempty"`
+	// Errors lists what could not be scanned or deleted. Findings are still
+	// reported for everything else.
+	Errors []ScanError `json:"errors,omitempty"`
+	// BackupArchive is the file the deleted resources were saved to with --backup-dir
+	BackupArchive string `json:"backupArchive,omitempty"`
+	// Released lists the quarantined resources that are used again, whose
+	// quarantine marker was removed
+	Released []Finding `json:"released,omitempty"`
+	// Cascade lists the deleted resources that had dependents, with the
+	// dependents the garbage collector removes along with them
+	Cascade []CascadeNode `json:"cascade,omitempty"`
+	// Skipped lists the plan items ApplyPlan left alone, with the reason why
+	Skipped []Finding `json:"skipped,omitempty"`
+	// Violations lists the thresholds exceeded when opts.FailOnFindings is set
+	Violations []ThresholdViolation `json:"violations,omitempty"`
+}
+
+// ScanError is a failure of a detector in a namespace, or of the scan as a
+// whole when Kind is empty
+type ScanError struct {
+	Kind      string `json:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Message   string `json:"error"`
+}
+
+func (e ScanError) String() string {
+	switch {
+	case e.Kind == "":
+		return e.Message
+	case e.Namespace == "":
+		return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+	default:
+		return fmt.Sprintf("%s in namespace %s: %s", e.Kind, e.Namespace, e.Message)
+	}
+}
+
+func (r *Report) addNamespace(namespace string) {
+	if !contains(r.Namespaces, namespace) {
+		r.Namespaces = append(r.Namespaces, namespace)
+	}
+}
+
+// addError records err, one ScanError per error when err joins several
+func (r *Report) addError(kind, namespace string, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			r.addError(kind, namespace, err)
+		}
+		return
+	}
+	r.Errors = append(r.Errors, ScanError{Kind: kind, Namespace: namespace, Message: err.Error()})
+}
+
+func newFindings(kind, namespace string, diff []ResourceInfo) []Finding {
+	findings := make([]Finding, 0, len(diff))
+	for _, info := range diff {
+		findings = append(findings, Finding{Kind: kind, Namespace: namespace, Name: info.Name, Reason: info.Reason})
+	}
+	return findings
+}
+
+// Grouped returns the findings keyed by namespace then kind, or by kind then
+// namespace when groupBy is "resource". Deleted resources get a "-DELETED"
+// suffix, followed by "(dry run)" for a --dry-run, as in the rendered output.
+func (r *Report) Grouped(groupBy string) map[string]map[string][]ResourceInfo {
+	resources := make(map[string]map[string][]ResourceInfo)
+	if groupBy == "namespace" {
+		for _, namespace := range r.Namespaces {
+			resources[namespace] = make(map[string][]ResourceInfo)
+		}
+	}
+
+	for _, finding := range r.Findings {
+		info := ResourceInfo{Name: finding.displayName(), Reason: finding.Reason}
+		if !finding.QuarantinedAt.IsZero() {
+			info.Reason += fmt.Sprintf(" (quarantined since %s)", finding.QuarantinedAt.Format(time.RFC3339))
+		}
+		switch groupBy {
+		case "namespace":
+			if _, ok := resources[finding.Namespace]; !ok {
+				resources[finding.Namespace] = make(map[string][]ResourceInfo)
+			}
+			resources[finding.Namespace][finding.Kind] = append(resources[finding.Namespace][finding.Kind], info)
+		case "resource":
+			appendResources(resources, finding.Kind, finding.Namespace, []ResourceInfo{info})
+		}
+	}
+	return resources
+}
+
+// FormatReport renders a report in the requested output format, sending it to Slack when configured
+func FormatReport(report *Report, outputFormat string, opts common.Opts) (string, error) {
+	return formatUnusedResources(report.Grouped(opts.GroupBy), report.Errors, report.Incomplete, outputFormat, opts)
+}
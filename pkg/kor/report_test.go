@@ -0,0 +1,326 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestReportGrouped(t *testing.T) {
+	report := &Report{
+		Namespaces: []string{"", testNamespace, "empty-namespace"},
+		Findings: []Finding{
+			{Kind: "Pv", Name: "test-pv", Reason: "PV is not in use"},
+			{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-1", Reason: "Marked with unused label"},
+			{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-2", Deleted: true},
+		},
+	}
+
+	byNamespace := report.Grouped("namespace")
+	expectedByNamespace := map[string]map[string][]ResourceInfo{
+		"": {
+			"Pv": {{Name: "test-pv", Reason: "PV is not in use"}},
+		},
+		testNamespace: {
+			"ConfigMap": {
+				{Name: "configmap-1", Reason: "Marked with unused label"},
+				{Name: "configmap-2-DELETED"},
+			},
+		},
+		"empty-namespace": {},
+	}
+	if !reflect.DeepEqual(byNamespace, expectedByNamespace) {
+		t.Errorf("Expected %v, got %v", expectedByNamespace, byNamespace)
+	}
+
+	byResource := report.Grouped("resource")
+	expectedByResource := map[string]map[string][]ResourceInfo{
+		"Pv": {
+			"": {{Name: "test-pv", Reason: "PV is not in use"}},
+		},
+		"ConfigMap": {
+			testNamespace: {
+				{Name: "configmap-1", Reason: "Marked with unused label"},
+				{Name: "configmap-2-DELETED"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(byResource, expectedByResource) {
+		t.Errorf("Expected %v, got %v", expectedByResource, byResource)
+	}
+}
+
+func TestGetUnusedReport(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	configmap := CreateTestConfigmap(testNamespace, "configmap-1", AppLabels)
+	_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), configmap, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake configmap: %v", err)
+	}
+
+	report, err := GetUnusedReport(context.TODO(), []Detector{configMapDetector}, &filters.Options{}, Clients{Clientset: clientset}, common.Opts{GroupBy: "namespace"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedFindings := []Finding{
+		{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-1", Reason: "ConfigMap is not used in any pod or container"},
+	}
+	if !reflect.DeepEqual(report.Findings, expectedFindings) {
+		t.Errorf("Expected %v, got %v", expectedFindings, report.Findings)
+	}
+	if !reflect.DeepEqual(report.Namespaces, []string{testNamespace}) {
+		t.Errorf("Expected only %s to be scanned, got %v", testNamespace, report.Namespaces)
+	}
+}
+
+func TestGetUnusedReportConcurrentOrder(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	namespaces := []string{"ns-a", "ns-b", "ns-c", "ns-d"}
+	for _, namespace := range namespaces {
+		_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(namespace, AppLabels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating namespace %s: %v", namespace, err)
+		}
+		for _, name := range []string{"configmap-1", "configmap-2"} {
+			_, err = clientset.CoreV1().ConfigMaps(namespace).Create(context.TODO(), CreateTestConfigmap(namespace, name, AppLabels), v1.CreateOptions{})
+			if err != nil {
+				t.Fatalf("Error creating fake configmap: %v", err)
+			}
+		}
+	}
+
+	detectors, err := resolveDetectors([]string{"cm", "secret", "sa"})
+	if err != nil {
+		t.Fatalf("Expected all resource types to be supported, got %v", err)
+	}
+	sequential, err := GetUnusedReport(context.TODO(), detectors, &filters.Options{}, Clients{Clientset: clientset}, common.Opts{Concurrency: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	concurrent, err := GetUnusedReport(context.TODO(), detectors, &filters.Options{}, Clients{Clientset: clientset}, common.Opts{Concurrency: 8})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(concurrent.Findings) != len(namespaces)*2 {
+		t.Errorf("Expected %d findings, got %v", len(namespaces)*2, concurrent.Findings)
+	}
+	if !reflect.DeepEqual(sequential, concurrent) {
+		t.Errorf("Expected concurrent scan to match sequential scan, got %v and %v", concurrent, sequential)
+	}
+	if !reflect.DeepEqual(concurrent.Namespaces, namespaces) {
+		t.Errorf("Expected namespaces in scan order %v, got %v", namespaces, concurrent.Namespaces)
+	}
+}
+
+func TestGetUnusedReportTimeout(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, "configmap-1", AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake configmap: %v", err)
+	}
+
+	hangingDetector := &resourceDetector{
+		name:  "Hanging",
+		scope: NamespaceScoped,
+		detect: func(ctx context.Context, _ Clients, _ string, _ *filters.Options, _ common.Opts) ([]ResourceInfo, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	opts := common.Opts{Timeout: 50 * time.Millisecond, DeleteFlag: true, NoInteractive: true}
+	report, err := GetUnusedReport(context.TODO(), []Detector{configMapDetector, hangingDetector}, &filters.Options{}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !report.Incomplete {
+		t.Errorf("Expected the report to be marked incomplete")
+	}
+	expectedFindings := []Finding{
+		{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-1", Reason: "ConfigMap is not used in any pod or container"},
+	}
+	if !reflect.DeepEqual(report.Findings, expectedFindings) {
+		t.Errorf("Expected the findings scanned in time %v, got %v", expectedFindings, report.Findings)
+	}
+
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "configmap-1", v1.GetOptions{}); err != nil {
+		t.Errorf("Expected nothing to be deleted from an incomplete scan, got %v", err)
+	}
+}
+
+func TestGetUnusedReportCancelled(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := GetUnusedReport(ctx, []Detector{priorityClassDetector}, &filters.Options{}, Clients{Clientset: clientset}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !report.Incomplete || len(report.Findings) != 0 {
+		t.Errorf("Expected an empty incomplete report, got %+v", report)
+	}
+}
+
+func TestGetUnusedReportErrors(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	for _, namespace := range []string{"ns-a", "ns-b"} {
+		_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(namespace, AppLabels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating namespace %s: %v", namespace, err)
+		}
+	}
+
+	failingDetector := &resourceDetector{
+		name:  "Failing",
+		scope: NamespaceScoped,
+		detect: func(_ context.Context, _ Clients, namespace string, _ *filters.Options, _ common.Opts) ([]ResourceInfo, error) {
+			if namespace == "ns-b" {
+				return nil, errors.New("forbidden")
+			}
+			return []ResourceInfo{{Name: "resource-1"}}, nil
+		},
+	}
+
+	report, err := GetUnusedReport(context.TODO(), []Detector{failingDetector}, &filters.Options{}, Clients{Clientset: clientset}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedErrors := []ScanError{{Kind: "Failing", Namespace: "ns-b", Message: "forbidden"}}
+	if !reflect.DeepEqual(report.Errors, expectedErrors) {
+		t.Errorf("Expected errors %v, got %v", expectedErrors, report.Errors)
+	}
+	expectedFindings := []Finding{{Kind: "Failing", Namespace: "ns-a", Name: "resource-1"}}
+	if !reflect.DeepEqual(report.Findings, expectedFindings) {
+		t.Errorf("Expected the findings of the other namespaces %v, got %v", expectedFindings, report.Findings)
+	}
+	if report.Incomplete {
+		t.Errorf("Expected a report with errors not to be marked incomplete")
+	}
+}
+
+func TestFormatReportErrors(t *testing.T) {
+	report := &Report{
+		Namespaces: []string{testNamespace},
+		Findings:   []Finding{{Kind: "ConfigMap", Namespace: testNamespace, Name: "configmap-1"}},
+		Errors:     []ScanError{{Kind: "Secret", Namespace: testNamespace, Message: "forbidden"}},
+	}
+
+	output, err := FormatReport(report, "json", common.Opts{GroupBy: "namespace"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var parsed map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	var scanErrors []ScanError
+	if err := json.Unmarshal(parsed[scanErrorsKey], &scanErrors); err != nil {
+		t.Fatalf("Expected scan errors under %q, got %v", scanErrorsKey, err)
+	}
+	if !reflect.DeepEqual(scanErrors, report.Errors) {
+		t.Errorf("Expected %v, got %v", report.Errors, scanErrors)
+	}
+	if _, ok := parsed[testNamespace]; !ok {
+		t.Errorf("Expected the findings of %s next to the errors, got %s", testNamespace, output)
+	}
+	if _, ok := parsed[scanIncompleteKey]; ok {
+		t.Errorf("Expected no %q key for a complete scan, got %s", scanIncompleteKey, output)
+	}
+
+	report.Incomplete = true
+	output, err = FormatReport(report, "yaml", common.Opts{GroupBy: "namespace"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var parsedYAML map[string]any
+	if err := yaml.Unmarshal([]byte(output), &parsedYAML); err != nil {
+		t.Fatalf("Expected valid YAML, got %v", err)
+	}
+	if parsedYAML[scanIncompleteKey] != true {
+		t.Errorf("Expected %q to be true for an incomplete scan, got %s", scanIncompleteKey, output)
+	}
+}
+
+func TestFormatOutputSorted(t *testing.T) {
+	resources := map[string]map[string][]ResourceInfo{
+		"ns-b": {"Secret": {{Name: "secret-1"}}, "ConfigMap": {{Name: "configmap-1"}}},
+		"ns-c": {"Pod": {{Name: "pod-1"}}},
+		"ns-a": {"Service": {{Name: "service-1"}}},
+	}
+
+	// map iteration order is random, so render a few times to catch an unsorted table
+	for range 10 {
+		output := FormatOutput(resources, common.Opts{GroupBy: "namespace"})
+		assertInOrder(t, output.String(), `"ns-a"`, `"ns-b"`, `"ns-c"`)
+		assertInOrder(t, output.String(), "configmap-1", "secret-1")
+	}
+
+	byResource := map[string]map[string][]ResourceInfo{
+		"Secret":    {"ns-b": {{Name: "secret-b"}}, "ns-a": {{Name: "secret-a"}}},
+		"ConfigMap": {"ns-a": {{Name: "configmap-a"}}},
+	}
+	for range 10 {
+		output := FormatOutput(byResource, common.Opts{GroupBy: "resource"})
+		assertInOrder(t, output.String(), "configmap-a", "secret-a", "secret-b")
+	}
+}
+
+// assertInOrder checks that each of substrings appears in output after the previous one
+func assertInOrder(t *testing.T, output string, substrings ...string) {
+	t.Helper()
+	last := -1
+	for _, substring := range substrings {
+		index := strings.Index(output, substring)
+		if index <= last {
+			t.Fatalf("Expected %q in order in:\n%s", substrings, output)
+		}
+		last = index
+	}
+}
+
+func TestGetUnusedMultiReportUnsupported(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	report, err := GetUnusedMultiReport(context.TODO(), "cm,unknown", &filters.Options{}, Clients{Clientset: clientset}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Message != `resource type "unknown" is not supported` {
+		t.Errorf("Expected the unsupported type to be reported, got %v", report.Errors)
+	}
+
+	if _, err := GetUnusedMultiReport(context.TODO(), "unknown", &filters.Options{}, Clients{Clientset: clientset}, common.Opts{}); err == nil {
+		t.Errorf("Expected an error when no resource type is supported")
+	}
+}
@@ -0,0 +1,186 @@
+package kor
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// DefaultResourceQuotaIdleAge is how long the used values of a ResourceQuota
+// may stay zero before it is reported as unused
+const DefaultResourceQuotaIdleAge = 30 * 24 * time.Hour
+
+// quotaUsageTime returns the last time the used values of a ResourceQuota
+// changed, or when it was created when that is not known. The quota
+// controller only updates its status when they change.
+func quotaUsageTime(quota corev1.ResourceQuota) time.Time {
+	last := quota.CreationTimestamp.Time
+	for _, entry := range quota.ManagedFields {
+		if entry.Subresource == "status" && entry.Time != nil && entry.Time.After(last) {
+			last = entry.Time.Time
+		}
+	}
+	return last
+}
+
+// forbidsResource tells whether a quota has a hard limit of zero, which
+// forbids creating what it constrains
+func forbidsResource(quota corev1.ResourceQuota) bool {
+	for _, quantity := range quota.Spec.Hard {
+		if quantity.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// podComputeResource returns the container resource a quota resource
+// constrains, and whether it is the limit rather than the request. ok is
+// false for the resources other than the compute resources of pods, such as
+// object counts and storage requests.
+func podComputeResource(name corev1.ResourceName) (resource corev1.ResourceName, limit bool, ok bool) {
+	switch {
+	case name == corev1.ResourceCPU || name == corev1.ResourceMemory || name == corev1.ResourceEphemeralStorage:
+		return name, false, true
+	case name == corev1.ResourceRequestsStorage:
+		return "", false, false
+	case strings.HasPrefix(string(name), corev1.DefaultResourceRequestsPrefix):
+		return corev1.ResourceName(strings.TrimPrefix(string(name), corev1.DefaultResourceRequestsPrefix)), false, true
+	case strings.HasPrefix(string(name), "limits."):
+		return corev1.ResourceName(strings.TrimPrefix(string(name), "limits.")), true, true
+	case strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix):
+		return name, false, true
+	}
+	return "", false, false
+}
+
+// podsRequest tells whether a container of the pods requests, or limits, a
+// compute resource
+func podsRequest(pods []corev1.Pod, resource corev1.ResourceName, limit bool) bool {
+	for _, pod := range pods {
+		for _, container := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+			quantities := container.Resources.Requests
+			if limit {
+				quantities = container.Resources.Limits
+			}
+			if _, ok := quantities[resource]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// constrainsNoPod tells whether a quota only constrains compute resources
+// that no pod requests. Quotas with scopes only apply to some of the pods and
+// are left out.
+func constrainsNoPod(quota corev1.ResourceQuota, pods []corev1.Pod) bool {
+	if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+		return false
+	}
+	for name := range quota.Spec.Hard {
+		resource, limit, ok := podComputeResource(name)
+		if !ok || podsRequest(pods, resource, limit) {
+			return false
+		}
+	}
+	return true
+}
+
+func processNamespaceResourceQuotas(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	quotaList, err := clientset.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+	if err != nil {
+		return nil, err
+	}
+
+	podsList, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// terminated pods don't count against quotas
+	var pods []corev1.Pod
+	for _, pod := range podsList.Items {
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			pods = append(pods, pod)
+		}
+	}
+
+	idleAge := opts.ResourceQuotaIdleAge
+	if idleAge == 0 {
+		idleAge = DefaultResourceQuotaIdleAge
+	}
+
+	var unusedQuotas []ResourceInfo
+
+	for _, quota := range quotaList.Items {
+		// Skip resources with ownerReferences if the general flag is set
+		if filterOpts.IgnoreOwnerReferences && len(quota.OwnerReferences) > 0 {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&quota).Run(filterOpts); pass {
+			continue
+		}
+
+		if quota.Labels["kor/used"] == "false" {
+			reason := "Marked with unused label"
+			unusedQuotas = append(unusedQuotas, ResourceInfo{Name: quota.Name, Reason: reason})
+			continue
+		}
+
+		if len(quota.Spec.Hard) == 0 {
+			reason := "ResourceQuota has no hard limits"
+			unusedQuotas = append(unusedQuotas, ResourceInfo{Name: quota.Name, Reason: reason})
+			continue
+		}
+
+		// a zero hard limit keeps anything from being created, so nothing ever uses it
+		if forbidsResource(quota) {
+			continue
+		}
+
+		// the used values are left empty until the quota controller computes them
+		if len(quota.Status.Used) > 0 && time.Since(quotaUsageTime(quota)) >= idleAge {
+			used := false
+			for _, quantity := range quota.Status.Used {
+				if !quantity.IsZero() {
+					used = true
+					break
+				}
+			}
+			if !used {
+				reason := "ResourceQuota has no usage"
+				unusedQuotas = append(unusedQuotas, ResourceInfo{Name: quota.Name, Reason: reason})
+				continue
+			}
+		}
+
+		if constrainsNoPod(quota, pods) {
+			reason := "ResourceQuota constrains resources no pod requests"
+			unusedQuotas = append(unusedQuotas, ResourceInfo{Name: quota.Name, Reason: reason})
+		}
+	}
+
+	return unusedQuotas, nil
+}
+
+var resourceQuotaDetector = newDetector("ResourceQuota", []string{"resourcequota", "quota", "resourcequotas"}, NamespaceScoped, namespacedDetect(processNamespaceResourceQuotas),
+	func(clients Clients, namespace string) typedClient[*corev1.ResourceQuota, *corev1.ResourceQuotaList] {
+		return clients.Clientset.CoreV1().ResourceQuotas(namespace)
+	})
+
+func init() {
+	MustRegisterDetector(resourceQuotaDetector)
+}
+
+func GetUnusedResourceQuotas(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{resourceQuotaDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
+}
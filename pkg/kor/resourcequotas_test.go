@@ -0,0 +1,168 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestResourceQuotas(t *testing.T) *fake.Clientset {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	pod := CreateTestPod(testNamespace, "test-pod", "", nil, AppLabels)
+	pod.Spec.Containers = []corev1.Container{{
+		Name: "app",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+		},
+	}}
+	_, err = clientset.CoreV1().Pods(testNamespace).Create(context.TODO(), pod, v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake pod: %v", err)
+	}
+
+	unusedLabelQuota := CreateTestResourceQuota(testNamespace, "unused-label-quota", corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}, nil)
+	unusedLabelQuota.Labels = UnusedLabels
+	idleCounts := func(name string) *corev1.ResourceQuota {
+		return CreateTestResourceQuota(testNamespace, name,
+			corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10"), corev1.ResourceServices: resource.MustParse("5")},
+			corev1.ResourceList{corev1.ResourcePods: resource.MustParse("0"), corev1.ResourceServices: resource.MustParse("0")})
+	}
+	idleQuota := idleCounts("idle-quota")
+	idleQuota.CreationTimestamp = v1.NewTime(time.Now().Add(-60 * 24 * time.Hour))
+	newQuota := idleCounts("new-quota")
+	newQuota.CreationTimestamp = v1.NewTime(time.Now().Add(-time.Hour))
+	// forbids creating LoadBalancer Services
+	zeroHardQuota := CreateTestResourceQuota(testNamespace, "zero-hard-quota",
+		corev1.ResourceList{corev1.ResourceServicesLoadBalancers: resource.MustParse("0")},
+		corev1.ResourceList{corev1.ResourceServicesLoadBalancers: resource.MustParse("0")})
+	zeroHardQuota.CreationTimestamp = idleQuota.CreationTimestamp
+	quotas := []*corev1.ResourceQuota{
+		CreateTestResourceQuota(testNamespace, "used-quota",
+			corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("1")},
+			corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("100m")}),
+		CreateTestResourceQuota(testNamespace, "empty-quota", corev1.ResourceList{}, nil),
+		idleQuota,
+		newQuota,
+		zeroHardQuota,
+		// not computed yet by the quota controller
+		CreateTestResourceQuota(testNamespace, "gpu-quota", corev1.ResourceList{"requests.nvidia.com/gpu": resource.MustParse("4")}, nil),
+		CreateTestResourceQuota(testNamespace, "cpu-quota", corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse("2")}, nil),
+		CreateTestResourceQuota(testNamespace, "count-quota", corev1.ResourceList{corev1.ResourceConfigMaps: resource.MustParse("10")}, nil),
+		unusedLabelQuota,
+	}
+	for _, quota := range quotas {
+		_, err = clientset.CoreV1().ResourceQuotas(testNamespace).Create(context.TODO(), quota, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake ResourceQuota %s: %v", quota.Name, err)
+		}
+	}
+
+	return clientset
+}
+
+func TestProcessNamespaceResourceQuotas(t *testing.T) {
+	clientset := createTestResourceQuotas(t)
+
+	unused, err := processNamespaceResourceQuotas(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Error retrieving unused ResourceQuotas: %v", err)
+	}
+
+	expected := []ResourceInfo{
+		{Name: "cpu-quota", Reason: "ResourceQuota constrains resources no pod requests"},
+		{Name: "empty-quota", Reason: "ResourceQuota has no hard limits"},
+		{Name: "gpu-quota", Reason: "ResourceQuota constrains resources no pod requests"},
+		{Name: "idle-quota", Reason: "ResourceQuota has no usage"},
+		{Name: "unused-label-quota", Reason: "Marked with unused label"},
+	}
+	sort.Slice(unused, func(i, j int) bool { return unused[i].Name < unused[j].Name })
+	if !reflect.DeepEqual(unused, expected) {
+		t.Errorf("Expected unused ResourceQuotas %v, got %v", expected, unused)
+	}
+
+	// with a shorter idle age the quotas without usage for the last hour are unused too
+	unused, err = processNamespaceResourceQuotas(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{ResourceQuotaIdleAge: 30 * time.Minute})
+	if err != nil {
+		t.Fatalf("Error retrieving unused ResourceQuotas: %v", err)
+	}
+	var idle []string
+	for _, info := range unused {
+		if info.Reason == "ResourceQuota has no usage" {
+			idle = append(idle, info.Name)
+		}
+	}
+	sort.Strings(idle)
+	if expectedIdle := []string{"idle-quota", "new-quota"}; !reflect.DeepEqual(idle, expectedIdle) {
+		t.Errorf("Expected the ResourceQuotas without usage %v, got %v", expectedIdle, idle)
+	}
+}
+
+func TestQuotaUsageTime(t *testing.T) {
+	created := time.Now().Add(-60 * 24 * time.Hour).Truncate(time.Second)
+	updated := v1.NewTime(created.Add(30 * 24 * time.Hour))
+	quota := CreateTestResourceQuota(testNamespace, "test-quota", corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}, nil)
+	quota.CreationTimestamp = v1.NewTime(created)
+	if usage := quotaUsageTime(*quota); !usage.Equal(created) {
+		t.Errorf("Expected the creation time %v without status updates, got %v", created, usage)
+	}
+
+	// the quota controller updates the used values through the status subresource
+	quota.ManagedFields = []v1.ManagedFieldsEntry{
+		{Manager: "kubectl-create", Operation: v1.ManagedFieldsOperationUpdate, Time: &v1.Time{Time: time.Now()}},
+		{Manager: "kube-controller-manager", Operation: v1.ManagedFieldsOperationUpdate, Subresource: "status", Time: &updated},
+	}
+	if usage := quotaUsageTime(*quota); !usage.Equal(updated.Time) {
+		t.Errorf("Expected the status update time %v, got %v", updated.Time, usage)
+	}
+}
+
+func TestGetUnusedResourceQuotasStructured(t *testing.T) {
+	clientset := createTestResourceQuotas(t)
+
+	opts := common.Opts{
+		WebhookURL:    "",
+		Channel:       "",
+		Token:         "",
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedResourceQuotas(context.TODO(), &filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedResourceQuotasStructured: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		testNamespace: {
+			"ResourceQuota": {"cpu-quota", "empty-quota", "gpu-quota", "idle-quota", "unused-label-quota"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+	sort.Strings(actualOutput[testNamespace]["ResourceQuota"])
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output does not match actual output")
+	}
+}
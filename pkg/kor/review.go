@@ -0,0 +1,284 @@
+package kor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/mattn/go-isatty"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+// reviewAction is what the batch review decided for a finding
+type reviewAction int
+
+const (
+	reviewSkip reviewAction = iota
+	reviewDelete
+	reviewFlag
+)
+
+// reviewResult holds the action decided for each reviewed finding. A nil
+// result, when there was no review, deletes every finding.
+type reviewResult map[objectKey]reviewAction
+
+func (r reviewResult) action(finding Finding) reviewAction {
+	if r == nil {
+		return reviewDelete
+	}
+	return r[objectKey{finding.Kind, finding.Namespace, finding.Name}]
+}
+
+// The batch review reads the selections from reviewInput and writes the
+// list and prompts to reviewOutput
+var (
+	reviewInput  io.Reader = os.Stdin
+	reviewOutput io.Writer = os.Stdout
+)
+
+// reviewDeletion runs the batch review of the findings about to be deleted,
+// unless opts.NoInteractive is set or it is a dry run, in which case it
+// returns a nil result deleting them all. The dependents in cascade are
+// listed under the findings that own them. The findings selected as in use
+// are flagged with flag, and the skipped ones recorded to audit as declined.
+// When stdin is not a terminal nothing is deleted, there being nobody to
+// review the list. The failures are joined in the returned error.
+func reviewDeletion(ctx context.Context, findings []Finding, cascade []CascadeNode, opts common.Opts, action string, flag func(Finding) error, get func(Finding) (runtime.Object, error), audit *auditLog) (reviewResult, error) {
+	if opts.NoInteractive || opts.DryRun != "" {
+		return nil, nil
+	}
+
+	var reviewed []Finding
+	for _, finding := range findings {
+		if deletable(finding, opts) {
+			reviewed = append(reviewed, finding)
+		}
+	}
+	result := make(reviewResult, len(reviewed))
+	if len(reviewed) == 0 {
+		return result, nil
+	}
+
+	var errs []error
+	var actions []reviewAction
+	if file, ok := reviewInput.(*os.File); ok && !isatty.IsTerminal(file.Fd()) && !isatty.IsCygwinTerminal(file.Fd()) {
+		errs = append(errs, errors.New("stdin is not a terminal, nothing was deleted: use --no-interactive to delete without a review, or kor plan and kor apply"))
+		actions = make([]reviewAction, len(reviewed))
+	} else {
+		var err error
+		actions, err = reviewFindings(reviewInput, reviewOutput, reviewed, cascade)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read the review, nothing was deleted: %w", err))
+		}
+	}
+
+	record := func(action, result string, finding Finding, obj runtime.Object, err error) {
+		if err := audit.record(ctx, newAuditEntry(action, result, finding, err), obj); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i, finding := range reviewed {
+		key := objectKey{finding.Kind, finding.Namespace, finding.Name}
+		result[key] = actions[i]
+		switch actions[i] {
+		case reviewSkip:
+			declined := finding
+			declined.Reason = "not deleted - user declined"
+			record(action, AuditSkipped, declined, audit.object(func() (runtime.Object, error) { return get(finding) }), nil)
+		case reviewFlag:
+			if err := flag(finding); err != nil {
+				result[key] = reviewSkip
+				errs = append(errs, fmt.Errorf("failed to flag %s %s in namespace %s as in use: %w", finding.Kind, finding.Name, finding.Namespace, err))
+				record(AuditFlag, AuditFailed, finding, nil, err)
+				continue
+			}
+			record(AuditFlag, AuditSucceeded, finding, audit.object(func() (runtime.Object, error) { return get(finding) }), nil)
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// reviewFindings lists the findings with numbers, each followed by its
+// dependents in cascade, and reads which of them to delete and which to flag
+// as in use, skipping the rest, then asks to confirm once. Invalid selections
+// are asked again. When in ends before the confirmation, or it is declined,
+// every finding is skipped.
+func reviewFindings(in io.Reader, out io.Writer, findings []Finding, cascade []CascadeNode) ([]reviewAction, error) {
+	actions := make([]reviewAction, len(findings))
+	owners := make(map[objectKey]CascadeNode, len(cascade))
+	for _, node := range cascade {
+		owners[node.key()] = node
+	}
+
+	fmt.Fprintf(out, "%d unused resources to review:\n", len(findings))
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	var printDependents func(nodes []CascadeNode, indent string)
+	printDependents = func(nodes []CascadeNode, indent string) {
+		for _, node := range nodes {
+			fmt.Fprintf(table, "\t%s%s\t%s\t%s\t%s\n", indent, node.Kind, node.Namespace, node.Name, "removed along with its owner")
+			printDependents(node.Dependents, indent+"  ")
+		}
+	}
+	for i, finding := range findings {
+		namespace := finding.Namespace
+		if namespace == "" {
+			namespace = "-"
+		}
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\n", i+1, finding.Kind, namespace, finding.Name, finding.Reason)
+		printDependents(owners[objectKey{finding.Kind, finding.Namespace, finding.Name}].Dependents, "  ")
+	}
+	if err := table.Flush(); err != nil {
+		return actions, err
+	}
+	fmt.Fprintln(out, "Select resources by number (3), range (1-5), kind (kind=ConfigMap), namespace (ns=default) or all, separated by commas.")
+
+	scanner := bufio.NewScanner(in)
+	cancelled := func() ([]reviewAction, error) {
+		if err := scanner.Err(); err != nil {
+			return make([]reviewAction, len(findings)), err
+		}
+		fmt.Fprintln(out, "\nNo more input, nothing was deleted")
+		return make([]reviewAction, len(findings)), nil
+	}
+	prompt := func(question string) ([]bool, bool) {
+		for {
+			fmt.Fprint(out, question)
+			if !scanner.Scan() {
+				return nil, false
+			}
+			selected, err := parseSelection(scanner.Text(), findings)
+			if err == nil {
+				return selected, true
+			}
+			fmt.Fprintln(out, err)
+		}
+	}
+
+	toDelete, ok := prompt("Resources to delete [none]: ")
+	if !ok {
+		return cancelled()
+	}
+	toFlag, ok := prompt("Resources to flag as in use, among the others [none]: ")
+	if !ok {
+		return cancelled()
+	}
+
+	var deletes, flags, dependents int
+	for i, finding := range findings {
+		switch {
+		case toDelete[i]:
+			actions[i] = reviewDelete
+			deletes++
+			owners[objectKey{finding.Kind, finding.Namespace, finding.Name}].walk(func(CascadeNode) {
+				dependents++
+			})
+		case toFlag[i]:
+			actions[i] = reviewFlag
+			flags++
+		}
+	}
+	if deletes+flags == 0 {
+		fmt.Fprintln(out, "Nothing selected, nothing was deleted")
+		return actions, nil
+	}
+
+	removed := ""
+	if dependents != 0 {
+		removed = fmt.Sprintf(" along with %d dependents", dependents)
+	}
+	fmt.Fprintf(out, "Delete %d%s, flag %d as in use and skip %d resources? (Y/N): ", deletes, removed, flags, len(findings)-deletes-flags)
+	if !scanner.Scan() {
+		return cancelled()
+	}
+	if confirmation := strings.ToLower(strings.TrimSpace(scanner.Text())); confirmation != "y" && confirmation != "yes" {
+		fmt.Fprintln(out, "Nothing was deleted")
+		return make([]reviewAction, len(findings)), nil
+	}
+	return actions, nil
+}
+
+// parseSelection returns which findings a selection of the batch review
+// matches. A selection is made of numbers, ranges, kind=<kind>,
+// ns=<namespace>, all or none, separated by commas or spaces. Empty means
+// none.
+func parseSelection(input string, findings []Finding) ([]bool, error) {
+	selected := make([]bool, len(findings))
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	for _, field := range fields {
+		matched := false
+		match := func(i int) {
+			selected[i] = true
+			matched = true
+		}
+
+		key, value, isFilter := strings.Cut(field, "=")
+		switch {
+		case strings.EqualFold(field, "none"):
+			matched = true
+		case strings.EqualFold(field, "all"):
+			for i := range findings {
+				match(i)
+			}
+		case isFilter && (key == "kind" || key == "k"):
+			if detector, ok := LookupDetector(value); ok {
+				value = detector.Name()
+			}
+			for i, finding := range findings {
+				if strings.EqualFold(finding.Kind, value) {
+					match(i)
+				}
+			}
+		case isFilter && (key == "namespace" || key == "ns" || key == "n"):
+			for i, finding := range findings {
+				if finding.Namespace == value {
+					match(i)
+				}
+			}
+		case isFilter:
+			return nil, fmt.Errorf("invalid selection %q, expected kind=<kind> or ns=<namespace>", field)
+		default:
+			first, last, err := parseRange(field, len(findings))
+			if err != nil {
+				return nil, err
+			}
+			for i := first; i <= last; i++ {
+				match(i - 1)
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no resource matches %q", field)
+		}
+	}
+	return selected, nil
+}
+
+// parseRange parses a number or a range of numbers of the batch review, from
+// 1 to n
+func parseRange(field string, n int) (int, int, error) {
+	from, to, isRange := strings.Cut(field, "-")
+	first, err := strconv.Atoi(from)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid selection %q, expected a number, a range, kind=<kind>, ns=<namespace> or all", field)
+	}
+	last := first
+	if isRange {
+		if last, err = strconv.Atoi(to); err != nil {
+			return 0, 0, fmt.Errorf("invalid range %q", field)
+		}
+	}
+	if first < 1 || last > n || first > last {
+		return 0, 0, fmt.Errorf("invalid selection %q, resources are numbered from 1 to %d", field, n)
+	}
+	return first, last, nil
+}
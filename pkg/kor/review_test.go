@@ -0,0 +1,192 @@
+package kor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+var reviewTestFindings = []Finding{
+	{Kind: "ConfigMap", Namespace: "ns-1", Name: "configmap-1"},
+	{Kind: "ConfigMap", Namespace: "ns-2", Name: "configmap-2"},
+	{Kind: "Secret", Namespace: "ns-1", Name: "secret-1"},
+	{Kind: "Secret", Namespace: "ns-2", Name: "secret-2"},
+	{Kind: "Pv", Name: "pv-1"},
+}
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []bool
+	}{
+		{"", []bool{false, false, false, false, false}},
+		{"none", []bool{false, false, false, false, false}},
+		{"all", []bool{true, true, true, true, true}},
+		{"1,3", []bool{true, false, true, false, false}},
+		{"2-4", []bool{false, true, true, true, false}},
+		{"1 5", []bool{true, false, false, false, true}},
+		{"kind=secret", []bool{false, false, true, true, false}},
+		{"kind=cm", []bool{true, true, false, false, false}},
+		{"ns=ns-2, 5", []bool{false, true, false, true, true}},
+	}
+	for _, test := range tests {
+		selected, err := parseSelection(test.input, reviewTestFindings)
+		if err != nil {
+			t.Errorf("Expected no error for %q, got %v", test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(selected, test.expected) {
+			t.Errorf("Expected %q to select %v, got %v", test.input, test.expected, selected)
+		}
+	}
+
+	for _, input := range []string{"0", "6", "3-2", "1-x", "abc", "kind=Deployment", "ns=other", "label=app"} {
+		if _, err := parseSelection(input, reviewTestFindings); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
+
+func TestReviewFindings(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []reviewAction
+	}{
+		{
+			name:     "confirmed",
+			input:    "1-2\nkind=Secret\ny\n",
+			expected: []reviewAction{reviewDelete, reviewDelete, reviewFlag, reviewFlag, reviewSkip},
+		},
+		{
+			name:     "invalid selection asked again",
+			input:    "9\nns=ns-1\n\nyes\n",
+			expected: []reviewAction{reviewDelete, reviewSkip, reviewDelete, reviewSkip, reviewSkip},
+		},
+		{
+			name:     "flag ignores the resources to delete",
+			input:    "1\nall\ny\n",
+			expected: []reviewAction{reviewDelete, reviewFlag, reviewFlag, reviewFlag, reviewFlag},
+		},
+		{
+			name:     "declined",
+			input:    "all\n\nn\n",
+			expected: []reviewAction{reviewSkip, reviewSkip, reviewSkip, reviewSkip, reviewSkip},
+		},
+		{
+			name:     "end of input",
+			input:    "all\n\n",
+			expected: []reviewAction{reviewSkip, reviewSkip, reviewSkip, reviewSkip, reviewSkip},
+		},
+		{
+			name:     "no input",
+			input:    "",
+			expected: []reviewAction{reviewSkip, reviewSkip, reviewSkip, reviewSkip, reviewSkip},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			actions, err := reviewFindings(strings.NewReader(test.input), &out, reviewTestFindings, nil)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(actions, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, actions)
+			}
+			if !strings.Contains(out.String(), "5  Pv         -     pv-1") {
+				t.Errorf("Expected the numbered list of findings, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestGetUnusedReportReview(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	for _, name := range []string{"configmap-1", "configmap-2", "configmap-3"} {
+		_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, name, AppLabels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+
+	input, output := reviewInput, reviewOutput
+	defer func() { reviewInput, reviewOutput = input, output }()
+	reviewInput = strings.NewReader("1\n2\ny\n")
+	reviewOutput = &bytes.Buffer{}
+
+	opts := common.Opts{DeleteFlag: true}
+	report, err := GetUnusedReport(context.TODO(), []Detector{configMapDetector}, &filters.Options{}, Clients{Clientset: clientset}, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("Expected no errors, got %v", report.Errors)
+	}
+
+	deleted := map[string]bool{}
+	reasons := map[string]string{}
+	for _, finding := range report.Findings {
+		deleted[finding.Name] = finding.Deleted
+		reasons[finding.Name] = finding.Reason
+	}
+	if !reflect.DeepEqual(deleted, map[string]bool{"configmap-1": true, "configmap-2": false, "configmap-3": false}) {
+		t.Errorf("Expected only configmap-1 to be deleted, got %v", deleted)
+	}
+	if reasons["configmap-2"] != "flagged as in use" {
+		t.Errorf("Expected the reason of the flagged configmap-2 to be %q, got %q", "flagged as in use", reasons["configmap-2"])
+	}
+	if reasons["configmap-3"] != "not deleted - user declined" {
+		t.Errorf("Expected the reason of the declined configmap-3 to be %q, got %q", "not deleted - user declined", reasons["configmap-3"])
+	}
+	configmap, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "configmap-2", v1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting configmap-2: %v", err)
+	}
+	if configmap.Labels["kor/used"] != "true" {
+		t.Errorf("Expected configmap-2 to be flagged as in use, got labels %v", configmap.Labels)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Get(context.TODO(), "configmap-3", v1.GetOptions{}); err != nil {
+		t.Errorf("Expected configmap-3 to be skipped, got %v", err)
+	}
+}
+
+func TestReviewDeletionNotTerminal(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Error creating pipe: %v", err)
+	}
+	defer reader.Close()
+	defer writer.Close()
+	input := reviewInput
+	defer func() { reviewInput = input }()
+	reviewInput = reader
+
+	flag := func(Finding) error {
+		t.Error("Expected nothing to be flagged")
+		return nil
+	}
+	review, err := reviewDeletion(context.TODO(), reviewTestFindings, nil, common.Opts{DeleteFlag: true}, AuditDelete, flag, nil, nil)
+	if err == nil {
+		t.Error("Expected an error when stdin is not a terminal")
+	}
+	for _, finding := range reviewTestFindings {
+		if review.action(finding) != reviewSkip {
+			t.Errorf("Expected %s to be skipped, got %v", finding.Name, review.action(finding))
+		}
+	}
+}
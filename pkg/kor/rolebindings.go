@@ -1,12 +1,8 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
-	"fmt"
-	"os"
 
 	v1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -52,23 +48,23 @@ func validateRoleReference(rb v1.RoleBinding, roleNames, clusterRoleNames map[st
 	return nil
 }
 
-func processNamespaceRoleBindings(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	roleBindingsList, err := clientset.RbacV1().RoleBindings(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespaceRoleBindings(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	roleBindingsList, err := clientset.RbacV1().RoleBindings(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
 
-	roleNames, err := convertNamesToPresenseMap(retrieveRoleNames(clientset, namespace, filterOpts))
+	roleNames, err := convertNamesToPresenseMap(retrieveRoleNames(ctx, clientset, namespace, filterOpts))
 	if err != nil {
 		return nil, err
 	}
 
-	clusterRoleNames, err := convertNamesToPresenseMap(retrieveClusterRoleNames(clientset, filterOpts))
+	clusterRoleNames, err := convertNamesToPresenseMap(retrieveClusterRoleNames(ctx, clientset, filterOpts))
 	if err != nil {
 		return nil, err
 	}
 
-	serviceAccountNames, err := convertNamesToPresenseMap(retrieveServiceAccountNames(clientset, namespace, filterOpts))
+	serviceAccountNames, err := convertNamesToPresenseMap(retrieveServiceAccountNames(ctx, clientset, namespace, filterOpts))
 	if err != nil {
 		return nil, err
 	}
@@ -120,47 +116,18 @@ func processNamespaceRoleBindings(clientset kubernetes.Interface, namespace stri
 			unusedRoleBindingNames = append(unusedRoleBindingNames, ResourceInfo{Name: rb.Name, Reason: "RoleBinding references a non-existing ServiceAccount"})
 		}
 	}
-	if opts.DeleteFlag {
-		if unusedRoleBindingNames, err = DeleteResource(unusedRoleBindingNames, clientset, namespace, "RoleBinding", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete RoleBinding %s in namespace %s: %v\n", unusedRoleBindingNames, namespace, err)
-		}
-	}
 	return unusedRoleBindingNames, nil
 }
 
-func GetUnusedRoleBindings(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceRoleBindings(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["RoleBinding"] = diff
-		case "resource":
-			appendResources(resources, "RoleBinding", namespace, diff)
-		}
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", " "); err != nil {
-			return "", err
-		}
-	}
+var roleBindingDetector = newDetector("RoleBinding", []string{"rolebinding", "rolebindings"}, NamespaceScoped, namespacedDetect(processNamespaceRoleBindings),
+	func(clients Clients, namespace string) typedClient[*v1.RoleBinding, *v1.RoleBindingList] {
+		return clients.Clientset.RbacV1().RoleBindings(namespace)
+	})
 
-	unusedRoleBindings, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(roleBindingDetector)
+}
 
-	return unusedRoleBindings, nil
+func GetUnusedRoleBindings(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{roleBindingDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
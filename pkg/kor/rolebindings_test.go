@@ -101,7 +101,7 @@ func createTestRoleBindings(t *testing.T) *fake.Clientset {
 func TestProcessNamespaceRoleBindings(t *testing.T) {
 	clientset := createTestRoleBindings(t)
 
-	unusedRoleBindings, err := processNamespaceRoleBindings(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	unusedRoleBindings, err := processNamespaceRoleBindings(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -132,7 +132,7 @@ func TestGetUnusedRoleBindingStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedRoleBindings(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedRoleBindings(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedRoleBindingStructured: %v", err)
 	}
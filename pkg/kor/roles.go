@@ -1,13 +1,11 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
 	"fmt"
-	"os"
 
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -19,28 +17,23 @@ import (
 //go:embed exceptions/roles/roles.json
 var rolesConfig []byte
 
-func retrieveUsedRoles(clientset kubernetes.Interface, namespace string) ([]string, error) {
+func retrieveUsedRoles(ctx context.Context, clientset kubernetes.Interface, namespace string) (references, error) {
 	// Get a list of all role bindings in the specified namespace
-	roleBindings, err := clientset.RbacV1().RoleBindings(namespace).List(context.TODO(), metav1.ListOptions{})
+	roleBindings, err := clientset.RbacV1().RoleBindings(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list role bindings in namespace %s: %v", namespace, err)
 	}
 
-	usedRoles := make(map[string]bool)
+	usedRoles := references{}
 	for _, rb := range roleBindings.Items {
-		usedRoles[rb.RoleRef.Name] = true
+		usedRoles.add(rb.RoleRef.Name, "RoleBinding", rb.Namespace, rb.Name, "roleRef")
 	}
 
-	var usedRoleNames []string
-	for role := range usedRoles {
-		usedRoleNames = append(usedRoleNames, role)
-	}
-
-	return usedRoleNames, nil
+	return usedRoles, nil
 }
 
-func retrieveRoleNames(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
-	roles, err := clientset.RbacV1().Roles(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func retrieveRoleNames(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
+	roles, err := clientset.RbacV1().Roles(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -78,22 +71,20 @@ func retrieveRoleNames(clientset kubernetes.Interface, namespace string, filterO
 	return names, unusedRoleNames, nil
 }
 
-func processNamespaceRoles(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	usedRoles, err := retrieveUsedRoles(clientset, namespace)
+func processNamespaceRoles(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	usedRoles, err := retrieveUsedRoles(ctx, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	usedRoles = RemoveDuplicatesAndSort(usedRoles)
-
-	roleInfos, rolesUnusedFromLabel, err := retrieveRoleNames(clientset, namespace, filterOpts)
+	roleInfos, rolesUnusedFromLabel, err := retrieveRoleNames(ctx, clientset, namespace, filterOpts)
 	if err != nil {
 		return nil, err
 	}
 
 	var diff []ResourceInfo
 
-	for _, name := range CalculateResourceDifference(usedRoles, roleInfos) {
+	for _, name := range CalculateResourceDifference(usedRoles.names(), roleInfos) {
 		reason := "ServiceAccount is not in use"
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
@@ -102,47 +93,18 @@ func processNamespaceRoles(clientset kubernetes.Interface, namespace string, fil
 		reason := "Marked with unused label"
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
-	if opts.DeleteFlag {
-		if diff, err = DeleteResource(diff, clientset, namespace, "Role", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete Role %s in namespace %s: %v\n", diff, namespace, err)
-		}
-	}
 	return diff, nil
 }
 
-func GetUnusedRoles(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceRoles(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["Role"] = diff
-		case "resource":
-			appendResources(resources, "Role", namespace, diff)
-		}
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var roleDetector = newDetector("Role", []string{"role", "roles"}, NamespaceScoped, namespacedDetect(processNamespaceRoles),
+	func(clients Clients, namespace string) typedClient[*rbacv1.Role, *rbacv1.RoleList] {
+		return clients.Clientset.RbacV1().Roles(namespace)
+	})
 
-	unusedRoles, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(roleDetector)
+}
 
-	return unusedRoles, nil
+func GetUnusedRoles(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{roleDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -1,14 +1,13 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
 	"fmt"
-	"os"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 	"k8s.io/utils/strings/slices"
@@ -27,95 +26,98 @@ var exceptionSecretTypes = []string{
 //go:embed exceptions/secrets/secrets.json
 var secretsConfig []byte
 
-func retrieveIngressTLS(clientset kubernetes.Interface, namespace string) ([]string, error) {
-	secretNames := make([]string, 0)
-	ingressList, err := clientset.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{})
+func retrieveIngressTLS(ctx context.Context, clientset kubernetes.Interface, namespace string) (references, error) {
+	used := references{}
+	ingressList, err := clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve Ingress resources: %v", err)
 	}
 
 	// Extract secret names from Ingress TLS
 	for _, ingress := range ingressList.Items {
-		for _, tls := range ingress.Spec.TLS {
-			secretNames = append(secretNames, tls.SecretName)
+		for i, tls := range ingress.Spec.TLS {
+			used.add(tls.SecretName, "Ingress", ingress.Namespace, ingress.Name, fmt.Sprintf("spec.tls[%d].secretName", i))
 		}
 	}
 
-	return secretNames, nil
+	return used, nil
 
 }
 
-func retrieveUsedSecret(clientset kubernetes.Interface, namespace string) ([]string, []string, []string, []string, []string, []string, error) {
-	var envSecrets []string
-	var envSecrets2 []string
-	var volumeSecrets []string
-	var pullSecrets []string
-	var initContainerEnvSecrets []string
-
-	// Retrieve pods in the specified namespace
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, nil, nil, nil, nil, nil, err
-	}
-
-	// Extract volume and environment information from pods
-	for _, pod := range pods.Items {
-		for _, container := range pod.Spec.Containers {
-			for _, env := range container.Env {
+// addPodSpecSecrets adds the Secrets used by the volumes, containers and
+// image pulls of a pod spec, source.Field being the path of the spec
+func addPodSpecSecrets(used references, spec *corev1.PodSpec, source Reference) {
+	for _, containers := range []struct {
+		field      string
+		containers []corev1.Container
+	}{{"containers", spec.Containers}, {"initContainers", spec.InitContainers}} {
+		for i, container := range containers.containers {
+			for j, env := range container.Env {
 				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
-					envSecrets = append(envSecrets, env.ValueFrom.SecretKeyRef.Name)
+					used.addReference(env.ValueFrom.SecretKeyRef.Name, source.at(fmt.Sprintf(".%s[%d].env[%d].valueFrom.secretKeyRef", containers.field, i, j), env.ValueFrom.SecretKeyRef.Optional))
 				}
 			}
-			for _, envFrom := range container.EnvFrom {
+			for j, envFrom := range container.EnvFrom {
 				if envFrom.SecretRef != nil {
-					envSecrets2 = append(envSecrets2, envFrom.SecretRef.Name)
+					used.addReference(envFrom.SecretRef.Name, source.at(fmt.Sprintf(".%s[%d].envFrom[%d].secretRef", containers.field, i, j), envFrom.SecretRef.Optional))
 				}
 			}
 		}
+	}
 
-		for _, initContainer := range pod.Spec.InitContainers {
-			for _, env := range initContainer.Env {
-				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
-					initContainerEnvSecrets = append(initContainerEnvSecrets, env.ValueFrom.SecretKeyRef.Name)
-				}
-			}
-			for _, envFrom := range initContainer.EnvFrom {
-				if envFrom.SecretRef != nil {
-					initContainerEnvSecrets = append(initContainerEnvSecrets, envFrom.SecretRef.Name)
-				}
-			}
+	for i, volume := range spec.Volumes {
+		if volume.Secret != nil {
+			used.addReference(volume.Secret.SecretName, source.at(fmt.Sprintf(".volumes[%d].secret", i), volume.Secret.Optional))
 		}
-
-		for _, volume := range pod.Spec.Volumes {
-			if volume.Secret != nil {
-				volumeSecrets = append(volumeSecrets, volume.Secret.SecretName)
-			}
-			if volume.Projected != nil && volume.Projected.Sources != nil {
-				for _, projectedResource := range volume.Projected.Sources {
-					if projectedResource.Secret != nil {
-						volumeSecrets = append(volumeSecrets, projectedResource.Secret.Name)
-					}
+		if volume.Projected != nil {
+			for j, projection := range volume.Projected.Sources {
+				if projection.Secret != nil {
+					used.addReference(projection.Secret.Name, source.at(fmt.Sprintf(".volumes[%d].projected.sources[%d].secret", i, j), projection.Secret.Optional))
 				}
 			}
 		}
+	}
 
-		if pod.Spec.ImagePullSecrets != nil {
-			for _, secret := range pod.Spec.ImagePullSecrets {
-				pullSecrets = append(pullSecrets, secret.Name)
-			}
-		}
+	for i, secret := range spec.ImagePullSecrets {
+		used.addReference(secret.Name, source.at(fmt.Sprintf(".imagePullSecrets[%d]", i), nil))
 	}
+}
+
+// retrieveUsedSecret returns the Secrets of a namespace used by pods, Ingress
+// TLS and Gateway listeners. The Gateways are left out without a dynamic client.
+func retrieveUsedSecret(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string) (references, error) {
+	used := references{}
 
-	tlsSecrets, err := retrieveIngressTLS(clientset, namespace)
+	// Retrieve pods in the specified namespace
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, err
+		return nil, err
+	}
+
+	// Extract volume and environment information from pods
+	for _, pod := range pods.Items {
+		addPodSpecSecrets(used, &pod.Spec, Reference{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name, Field: "spec"})
 	}
 
-	return envSecrets, envSecrets2, volumeSecrets, initContainerEnvSecrets, pullSecrets, tlsSecrets, nil
+	tlsSecrets, err := retrieveIngressTLS(ctx, clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	used.merge(tlsSecrets)
+
+	if dynamicClient != nil {
+		certificates, err := retrieveGatewayCertificates(ctx, dynamicClient, namespace)
+		if err != nil {
+			return nil, err
+		}
+		used.merge(certificates)
+	}
+
+	return used, nil
 }
 
-func retrieveSecretNames(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
-	secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func retrieveSecretNames(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
+	secrets, err := clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -158,42 +160,21 @@ func retrieveSecretNames(clientset kubernetes.Interface, namespace string, filte
 	return names, unusedSecretNames, nil
 }
 
-func processNamespaceSecret(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	envSecrets, envSecrets2, volumeSecrets, initContainerEnvSecrets, pullSecrets, tlsSecrets, err := retrieveUsedSecret(clientset, namespace)
+func processNamespaceSecret(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	usedSecrets, err := retrieveUsedSecret(ctx, clientset, dynamicClient, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	envSecrets = RemoveDuplicatesAndSort(envSecrets)
-	envSecrets2 = RemoveDuplicatesAndSort(envSecrets2)
-	volumeSecrets = RemoveDuplicatesAndSort(volumeSecrets)
-	initContainerEnvSecrets = RemoveDuplicatesAndSort(initContainerEnvSecrets)
-	pullSecrets = RemoveDuplicatesAndSort(pullSecrets)
-	tlsSecrets = RemoveDuplicatesAndSort(tlsSecrets)
-
-	secretNames, unusedSecretNames, err := retrieveSecretNames(clientset, namespace, filterOpts)
+	secretNames, unusedSecretNames, err := retrieveSecretNames(ctx, clientset, namespace, filterOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	var usedSecrets []string
-	slicesToAppend := [][]string{
-		envSecrets,
-		envSecrets2,
-		volumeSecrets,
-		pullSecrets,
-		tlsSecrets,
-		initContainerEnvSecrets,
-	}
-
-	for _, slice := range slicesToAppend {
-		usedSecrets = append(usedSecrets, slice...)
-	}
-
 	var diff []ResourceInfo
 
-	for _, name := range CalculateResourceDifference(usedSecrets, secretNames) {
-		reason := "Secret is not used in any pod, container, or ingress"
+	for _, name := range CalculateResourceDifference(usedSecrets.names(), secretNames) {
+		reason := "Secret is not used in any pod, container, ingress, or Gateway listener"
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
 
@@ -202,48 +183,23 @@ func processNamespaceSecret(clientset kubernetes.Interface, namespace string, fi
 		diff = append(diff, ResourceInfo{Name: name, Reason: reason})
 	}
 
-	if opts.DeleteFlag {
-		if diff, err = DeleteResource(diff, clientset, namespace, "Secret", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete Secret %s in namespace %s: %v\n", diff, namespace, err)
-		}
-	}
 	return diff, nil
 
 }
 
-func GetUnusedSecrets(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceSecret(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["Secret"] = diff
-		case "resource":
-			appendResources(resources, "Secret", namespace, diff)
-		}
-	}
+func detectSecrets(ctx context.Context, clients Clients, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	return processNamespaceSecret(ctx, clients.Clientset, clients.Dynamic, namespace, filterOpts, opts)
+}
 
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var secretDetector = newDetector("Secret", []string{"secret", "secrets"}, NamespaceScoped, detectSecrets,
+	func(clients Clients, namespace string) typedClient[*corev1.Secret, *corev1.SecretList] {
+		return clients.Clientset.CoreV1().Secrets(namespace)
+	})
 
-	unusedSecrets, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(secretDetector)
+}
 
-	return unusedSecrets, nil
+func GetUnusedSecrets(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{secretDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"reflect"
+	"regexp"
 	"testing"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -188,17 +189,21 @@ func TestRetrieveIngressTLS(t *testing.T) {
 		t.Fatalf("Error creating fake %s: %v", "Secret", err)
 	}
 
-	tlsSecrets, err := retrieveIngressTLS(clientset, testNamespace)
+	tlsSecrets, err := retrieveIngressTLS(context.TODO(), clientset, testNamespace)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
 	if len(tlsSecrets) != 1 {
-		t.Errorf("Expected 1 used Secret object, got %d", len(tlsSecrets))
+		t.Fatalf("Expected 1 used Secret object, got %d", len(tlsSecrets))
 	}
 
-	if tlsSecrets[0] != "test-secret1" {
-		t.Errorf("Expected 'test-secret1', got %s", tlsSecrets[0])
+	if tlsSecrets.names()[0] != "test-secret1" {
+		t.Errorf("Expected 'test-secret1', got %s", tlsSecrets.names()[0])
+	}
+
+	if field := tlsSecrets["test-secret1"][0].Field; field != "spec.tls[0].secretName" {
+		t.Errorf("Expected the reference from spec.tls[0].secretName, got %s", field)
 	}
 
 }
@@ -206,10 +211,15 @@ func TestRetrieveIngressTLS(t *testing.T) {
 func TestRetrieveUsedSecret(t *testing.T) {
 	clientset := createTestSecrets(t)
 
-	envSecrets, envSecrets2, volumeSecrets, initContainerEnvSecrets, pullSecrets, _, err := retrieveUsedSecret(clientset, testNamespace)
+	usedSecrets, err := retrieveUsedSecret(context.TODO(), clientset, nil, testNamespace)
 	if err != nil {
 		t.Fatalf("Error retrieving used secrets: %v", err)
 	}
+	envSecrets := namesReferencedFrom(usedSecrets, `^spec\.containers\.env\.`)
+	envSecrets2 := namesReferencedFrom(usedSecrets, `^spec\.containers\.envFrom\.`)
+	volumeSecrets := namesReferencedFrom(usedSecrets, `^spec\.volumes\.`)
+	initContainerEnvSecrets := namesReferencedFrom(usedSecrets, `^spec\.initContainers\.`)
+	pullSecrets := namesReferencedFrom(usedSecrets, `^spec\.imagePullSecrets$`)
 
 	expectedVolumeSecrets := []string{
 		"test-secret1",
@@ -261,7 +271,7 @@ func TestRetrieveSecretNames(t *testing.T) {
 		t.Fatalf("Error creating fake secret: %v", err)
 	}
 
-	secretNames, _, err := retrieveSecretNames(clientset, testNamespace, &filters.Options{})
+	secretNames, _, err := retrieveSecretNames(context.TODO(), clientset, testNamespace, &filters.Options{})
 
 	if err != nil {
 		t.Fatalf("Error retrieving secret names: %v", err)
@@ -279,7 +289,7 @@ func TestRetrieveSecretNames(t *testing.T) {
 func TestProcessNamespaceSecret(t *testing.T) {
 	clientset := createTestSecrets(t)
 
-	unusedSecrets, err := processNamespaceSecret(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	unusedSecrets, err := processNamespaceSecret(context.TODO(), clientset, nil, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused secrets: %v", err)
 	}
@@ -306,7 +316,7 @@ func TestGetUnusedSecretsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedSecrets(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedSecrets(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedSecretsStructured: %v", err)
 	}
@@ -367,7 +377,7 @@ func TestFilterOwnerReferencedSecrets(t *testing.T) {
 
 	// Test without filter - should return both
 	filterOptsNoSkip := &filters.Options{IgnoreOwnerReferences: false}
-	unusedWithoutFilter, err := processNamespaceSecret(clientset, testNamespace, filterOptsNoSkip, common.Opts{})
+	unusedWithoutFilter, err := processNamespaceSecret(context.TODO(), clientset, nil, testNamespace, filterOptsNoSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused secrets: %v", err)
 	}
@@ -378,7 +388,7 @@ func TestFilterOwnerReferencedSecrets(t *testing.T) {
 
 	// Test with filter - should return only standalone
 	filterOptsWithSkip := &filters.Options{IgnoreOwnerReferences: true}
-	unusedWithFilter, err := processNamespaceSecret(clientset, testNamespace, filterOptsWithSkip, common.Opts{})
+	unusedWithFilter, err := processNamespaceSecret(context.TODO(), clientset, nil, testNamespace, filterOptsWithSkip, common.Opts{})
 	if err != nil {
 		t.Fatalf("Error retrieving unused secrets: %v", err)
 	}
@@ -392,6 +402,21 @@ func TestFilterOwnerReferencedSecrets(t *testing.T) {
 	}
 }
 
+// namesReferencedFrom returns the sorted names of the resources used from a
+// field matching pattern, with the indexes of the field path removed
+func namesReferencedFrom(used references, pattern string) []string {
+	var names []string
+	for _, name := range used.names() {
+		for _, reference := range used[name] {
+			if matched, _ := regexp.MatchString(pattern, fieldIndexes.ReplaceAllString(reference.Field, "")); matched {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
+
 func equalSlices(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -1,13 +1,11 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
 	"fmt"
-	"os"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
@@ -18,15 +16,14 @@ import (
 //go:embed exceptions/serviceaccounts/serviceaccounts.json
 var serviceAccountsConfig []byte
 
-func getServiceAccountsFromClusterRoleBindings(clientset kubernetes.Interface, namespace string) ([]string, error) {
+func getServiceAccountsFromClusterRoleBindings(ctx context.Context, clientset kubernetes.Interface, namespace string) (references, error) {
 	// Get a list of all role bindings in the specified namespace
-	roleBindings, err := clientset.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+	roleBindings, err := clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list role bindings in namespace %s: %v", namespace, err)
 	}
 
-	// Create a slice to store service account names
-	var serviceAccounts []string
+	serviceAccounts := references{}
 
 	// Extract service account names from the role bindings
 	for _, rb := range roleBindings.Items {
@@ -34,10 +31,10 @@ func getServiceAccountsFromClusterRoleBindings(clientset kubernetes.Interface, n
 			continue
 		}
 
-		for _, subject := range rb.Subjects {
+		for i, subject := range rb.Subjects {
 
 			if subject.Kind == "ServiceAccount" {
-				serviceAccounts = append(serviceAccounts, subject.Name)
+				serviceAccounts.add(subject.Name, "ClusterRoleBinding", "", rb.Name, fmt.Sprintf("subjects[%d]", i))
 			}
 		}
 	}
@@ -45,15 +42,14 @@ func getServiceAccountsFromClusterRoleBindings(clientset kubernetes.Interface, n
 	return serviceAccounts, nil
 }
 
-func getServiceAccountsFromRoleBindings(clientset kubernetes.Interface, namespace string) ([]string, error) {
+func getServiceAccountsFromRoleBindings(ctx context.Context, clientset kubernetes.Interface, namespace string) (references, error) {
 	// Get a list of all role bindings in the specified namespace
-	roleBindings, err := clientset.RbacV1().RoleBindings(namespace).List(context.TODO(), metav1.ListOptions{})
+	roleBindings, err := clientset.RbacV1().RoleBindings(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list role bindings in namespace %s: %v", namespace, err)
 	}
 
-	// Create a slice to store service account names
-	var serviceAccounts []string
+	serviceAccounts := references{}
 
 	// Extract service account names from the role bindings
 	for _, rb := range roleBindings.Items {
@@ -61,9 +57,9 @@ func getServiceAccountsFromRoleBindings(clientset kubernetes.Interface, namespac
 			continue
 		}
 
-		for _, subject := range rb.Subjects {
+		for i, subject := range rb.Subjects {
 			if subject.Kind == "ServiceAccount" {
-				serviceAccounts = append(serviceAccounts, subject.Name)
+				serviceAccounts.add(subject.Name, "RoleBinding", rb.Namespace, rb.Name, fmt.Sprintf("subjects[%d]", i))
 			}
 		}
 	}
@@ -71,35 +67,37 @@ func getServiceAccountsFromRoleBindings(clientset kubernetes.Interface, namespac
 	return serviceAccounts, nil
 }
 
-func retrieveUsedSA(clientset kubernetes.Interface, namespace string) ([]string, []string, []string, error) {
+func retrieveUsedSA(ctx context.Context, clientset kubernetes.Interface, namespace string) (references, error) {
 
-	var podServiceAccounts []string
+	used := references{}
 
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
 
 	// Extract service account names from pods
 	for _, pod := range pods.Items {
 		if pod.Spec.ServiceAccountName != "" {
-			podServiceAccounts = append(podServiceAccounts, pod.Spec.ServiceAccountName)
+			used.add(pod.Spec.ServiceAccountName, "Pod", pod.Namespace, pod.Name, "spec.serviceAccountName")
 		}
 	}
 
-	roleServiceAccounts, err := getServiceAccountsFromRoleBindings(clientset, namespace)
+	roleServiceAccounts, err := getServiceAccountsFromRoleBindings(ctx, clientset, namespace)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
-	clusterRoleServiceAccounts, err := getServiceAccountsFromClusterRoleBindings(clientset, namespace)
+	clusterRoleServiceAccounts, err := getServiceAccountsFromClusterRoleBindings(ctx, clientset, namespace)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
-	return podServiceAccounts, roleServiceAccounts, clusterRoleServiceAccounts, nil
+	used.merge(roleServiceAccounts)
+	used.merge(clusterRoleServiceAccounts)
+	return used, nil
 }
 
-func retrieveServiceAccountNames(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
-	serviceaccounts, err := clientset.CoreV1().ServiceAccounts(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func retrieveServiceAccountNames(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options) ([]string, []string, error) {
+	serviceaccounts, err := clientset.CoreV1().ServiceAccounts(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -126,8 +124,8 @@ func retrieveServiceAccountNames(clientset kubernetes.Interface, namespace strin
 	return names, unusedServiceAccountNames, nil
 }
 
-func processNamespaceSA(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	usedServiceAccounts, roleServiceAccounts, clusterRoleServiceAccounts, err := retrieveUsedSA(clientset, namespace)
+func processNamespaceSA(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	usedServiceAccounts, err := retrieveUsedSA(ctx, clientset, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -136,20 +134,14 @@ func processNamespaceSA(clientset kubernetes.Interface, namespace string, filter
 		return nil, err
 	}
 
-	usedServiceAccounts = RemoveDuplicatesAndSort(usedServiceAccounts)
-	roleServiceAccounts = RemoveDuplicatesAndSort(roleServiceAccounts)
-	clusterRoleServiceAccounts = RemoveDuplicatesAndSort(clusterRoleServiceAccounts)
-
-	usedServiceAccounts = append(append(usedServiceAccounts, roleServiceAccounts...), clusterRoleServiceAccounts...)
-
-	serviceAccountNames, unusedServiceAccountNames, err := retrieveServiceAccountNames(clientset, namespace, filterOpts)
+	serviceAccountNames, unusedServiceAccountNames, err := retrieveServiceAccountNames(ctx, clientset, namespace, filterOpts)
 	if err != nil {
 		return nil, err
 	}
 
 	var unusedServiceAccounts []ResourceInfo
 
-	for _, name := range CalculateResourceDifference(usedServiceAccounts, serviceAccountNames) {
+	for _, name := range CalculateResourceDifference(usedServiceAccounts.names(), serviceAccountNames) {
 		exceptionFound, err := isResourceException(name, namespace, config.ExceptionServiceAccounts)
 		if err != nil {
 			return nil, err
@@ -166,47 +158,18 @@ func processNamespaceSA(clientset kubernetes.Interface, namespace string, filter
 		reason := "Marked with unused label"
 		unusedServiceAccounts = append(unusedServiceAccounts, ResourceInfo{Name: name, Reason: reason})
 	}
-	if opts.DeleteFlag {
-		if unusedServiceAccounts, err = DeleteResource(unusedServiceAccounts, clientset, namespace, "ServiceAccount", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete Serviceaccount %s in namespace %s: %v\n", unusedServiceAccounts, namespace, err)
-		}
-	}
 	return unusedServiceAccounts, nil
 }
 
-func GetUnusedServiceAccounts(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceSA(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			resources[namespace] = make(map[string][]ResourceInfo)
-			resources[namespace]["ServiceAccount"] = diff
-		case "resource":
-			appendResources(resources, "ServiceAccount", namespace, diff)
-		}
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var serviceAccountDetector = newDetector("ServiceAccount", []string{"serviceaccount", "sa", "serviceaccounts"}, NamespaceScoped, namespacedDetect(processNamespaceSA),
+	func(clients Clients, namespace string) typedClient[*corev1.ServiceAccount, *corev1.ServiceAccountList] {
+		return clients.Clientset.CoreV1().ServiceAccounts(namespace)
+	})
 
-	unusedServiceAccounts, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(serviceAccountDetector)
+}
 
-	return unusedServiceAccounts, nil
+func GetUnusedServiceAccounts(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{serviceAccountDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
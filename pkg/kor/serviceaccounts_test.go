@@ -64,7 +64,7 @@ func TestGetServiceAccountsFromClusterRoleBindings(t *testing.T) {
 		t.Fatalf("Error creating fake %s: %v", "clusterRoleBinding", err)
 	}
 
-	serviceAccountWithCRB, err := getServiceAccountsFromClusterRoleBindings(clientset, testNamespace)
+	serviceAccountWithCRB, err := getServiceAccountsFromClusterRoleBindings(context.TODO(), clientset, testNamespace)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -73,8 +73,8 @@ func TestGetServiceAccountsFromClusterRoleBindings(t *testing.T) {
 		t.Errorf("Expected 1 serviceAccount without CRB, got %d", len(serviceAccountWithCRB))
 	}
 
-	if serviceAccountWithCRB[0] != "test-sa1" {
-		t.Errorf("Expected 'test-sa1', got %s", serviceAccountWithCRB[0])
+	if serviceAccountWithCRB.names()[0] != "test-sa1" {
+		t.Errorf("Expected 'test-sa1', got %s", serviceAccountWithCRB.names()[0])
 	}
 
 }
@@ -89,7 +89,7 @@ func TestGetServiceAccountsFromRoleBindings(t *testing.T) {
 		t.Fatalf("Error creating fake %s: %v", "roleBinding", err)
 	}
 
-	serviceAccountWithRB, err := getServiceAccountsFromRoleBindings(clientset, testNamespace)
+	serviceAccountWithRB, err := getServiceAccountsFromRoleBindings(context.TODO(), clientset, testNamespace)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -98,8 +98,8 @@ func TestGetServiceAccountsFromRoleBindings(t *testing.T) {
 		t.Errorf("Expected 1 serviceAccount without CRB, got %d", len(serviceAccountWithRB))
 	}
 
-	if serviceAccountWithRB[0] != "test-sa1" {
-		t.Errorf("Expected 'test-sa1', got %s", serviceAccountWithRB[0])
+	if serviceAccountWithRB.names()[0] != "test-sa1" {
+		t.Errorf("Expected 'test-sa1', got %s", serviceAccountWithRB.names()[0])
 	}
 }
 
@@ -115,10 +115,11 @@ func TestRetrieveUsedSA(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error creating fake %s: %v", "Pod", err)
 	}
-	serviceAccountUsedByPod, _, _, err := retrieveUsedSA(clientset, testNamespace)
+	usedServiceAccounts, err := retrieveUsedSA(context.TODO(), clientset, testNamespace)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
+	serviceAccountUsedByPod := namesReferencedFrom(usedServiceAccounts, `^spec\.serviceAccountName$`)
 
 	if len(serviceAccountUsedByPod) != 1 {
 		t.Errorf("Expected 2 serviceAccount Used by pod, got %d", len(serviceAccountUsedByPod))
@@ -132,7 +133,7 @@ func TestRetrieveUsedSA(t *testing.T) {
 
 func TestRetrieveServiceAccountNames(t *testing.T) {
 	clientset := createTestServiceAccounts(t)
-	serviceAccountNames, _, err := retrieveServiceAccountNames(clientset, testNamespace, &filters.Options{})
+	serviceAccountNames, _, err := retrieveServiceAccountNames(context.TODO(), clientset, testNamespace, &filters.Options{})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -162,7 +163,7 @@ func TestProcessNamespaceSA(t *testing.T) {
 		t.Fatalf("Error creating fake %s: %v", "Pod", err)
 	}
 
-	unusedServiceAccounts, err := processNamespaceSA(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	unusedServiceAccounts, err := processNamespaceSA(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -194,7 +195,7 @@ func TestGetUnusedServiceAccountsStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedServiceAccounts(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedServiceAccounts(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedServiceAccountsStructured: %v", err)
 	}
@@ -258,7 +259,7 @@ func TestProcessNamespaceSAWithOwnerReferences(t *testing.T) {
 	clientset := createTestServiceAccountsWithOwnerReferences(t)
 
 	// Test with --ignore-owner-references=false (default behavior)
-	unusedServiceAccounts, err := processNamespaceSA(clientset, testNamespace, &filters.Options{}, common.Opts{})
+	unusedServiceAccounts, err := processNamespaceSA(context.TODO(), clientset, testNamespace, &filters.Options{}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -269,7 +270,7 @@ func TestProcessNamespaceSAWithOwnerReferences(t *testing.T) {
 	}
 
 	// Test with --ignore-owner-references=true
-	unusedServiceAccounts, err = processNamespaceSA(clientset, testNamespace, &filters.Options{IgnoreOwnerReferences: true}, common.Opts{})
+	unusedServiceAccounts, err = processNamespaceSA(context.TODO(), clientset, testNamespace, &filters.Options{IgnoreOwnerReferences: true}, common.Opts{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -1,13 +1,10 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
-	"fmt"
-	"os"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
@@ -18,8 +15,8 @@ import (
 //go:embed exceptions/services/services.json
 var servicesConfig []byte
 
-func processNamespaceServices(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	endpointSlices, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespaceServices(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	endpointSlices, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -62,54 +59,18 @@ func processNamespaceServices(clientset kubernetes.Interface, namespace string,
 		}
 	}
 
-	if opts.DeleteFlag {
-		if endpointsWithoutSubsets, err = DeleteResource(endpointsWithoutSubsets, clientset, namespace, "Service", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete Service %s in namespace %s: %v\n", endpointsWithoutSubsets, namespace, err)
-		}
-	}
-
 	return endpointsWithoutSubsets, nil
 }
 
-func GetUnusedServices(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceServices(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-
-		switch opts.GroupBy {
-		case "namespace":
-			if diff != nil {
-				resources[namespace] = make(map[string][]ResourceInfo)
-				resources[namespace]["Service"] = diff
-			}
-		case "resource":
-			if diff != nil {
-				appendResources(resources, "Service", namespace, diff)
-			}
-		}
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var serviceDetector = newDetector("Service", []string{"service", "svc", "services"}, NamespaceScoped, namespacedDetect(processNamespaceServices),
+	func(clients Clients, namespace string) typedClient[*corev1.Service, *corev1.ServiceList] {
+		return clients.Clientset.CoreV1().Services(namespace)
+	})
 
-	unusedServices, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(serviceDetector)
+}
 
-	return unusedServices, nil
+func GetUnusedServices(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{serviceDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -0,0 +1,1172 @@
+package kor
+
+import (
+	"context"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	appsv1client "k8s.io/client-go/kubernetes/typed/apps/v1"
+	autoscalingv2client "k8s.io/client-go/kubernetes/typed/autoscaling/v2"
+	batchv1client "k8s.io/client-go/kubernetes/typed/batch/v1"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	discoveryv1client "k8s.io/client-go/kubernetes/typed/discovery/v1"
+	networkingv1client "k8s.io/client-go/kubernetes/typed/networking/v1"
+	policyv1client "k8s.io/client-go/kubernetes/typed/policy/v1"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	schedulingv1client "k8s.io/client-go/kubernetes/typed/scheduling/v1"
+	storagev1client "k8s.io/client-go/kubernetes/typed/storage/v1"
+)
+
+// snapshotPageSize is the page size of the Lists filling a Snapshot
+const snapshotPageSize = 500
+
+// Snapshot is a kubernetes.Interface serving the Lists of the kinds kor
+// inspects from a cache, so each kind is listed once per scan however many
+// detectors and namespaces read it. Cluster-wide snapshots list every kind
+// once across all namespaces and index the items by namespace; otherwise
+// namespaced kinds are listed once per namespace. Lists with a field
+// selector or paging options, and every other call, go to the API server.
+//
+// A Snapshot never refreshes: create a new one for each scan.
+type Snapshot struct {
+	kubernetes.Interface
+	*snapshotCache
+}
+
+// snapshotCache holds the cached Lists of a Snapshot or a DynamicSnapshot
+type snapshotCache struct {
+	clusterWide bool
+
+	mu      sync.Mutex
+	entries map[snapshotKey]any
+}
+
+func newSnapshotCache(clusterWide bool) *snapshotCache {
+	return &snapshotCache{clusterWide: clusterWide, entries: make(map[snapshotKey]any)}
+}
+
+type snapshotKey struct {
+	resource  string
+	namespace string
+}
+
+// snapshotEntry holds the cached items of a resource once a List succeeded.
+// Failed Lists are not cached, so a timeout or cancellation of one caller
+// doesn't fail the later ones.
+type snapshotEntry[T any] struct {
+	mu          sync.Mutex
+	loaded      bool
+	all         []T
+	byNamespace map[string][]T
+}
+
+// load lists the items of the entry unless they are already cached. The
+// List runs with the ctx of the caller, which is not kept afterwards.
+func (e *snapshotEntry[T]) load(ctx context.Context, namespace string, list func(ctx context.Context, namespace string, opts metav1.ListOptions) ([]T, string, error), itemNamespace func(item *T) string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.loaded {
+		return nil
+	}
+
+	var all []T
+	pageOpts := metav1.ListOptions{Limit: snapshotPageSize}
+	for {
+		items, next, err := list(ctx, namespace, pageOpts)
+		if err != nil {
+			return err
+		}
+		all = append(all, items...)
+		if next == "" {
+			break
+		}
+		pageOpts.Continue = next
+	}
+
+	e.all = all
+	e.byNamespace = make(map[string][]T)
+	for i := range all {
+		ns := itemNamespace(&all[i])
+		e.byNamespace[ns] = append(e.byNamespace[ns], all[i])
+	}
+	e.loaded = true
+	return nil
+}
+
+// NewSnapshot wraps clientset in a Snapshot. clusterWide should only be set
+// when the scan covers all namespaces, as it requires cluster-wide list
+// permissions for the namespaced kinds.
+func NewSnapshot(clientset kubernetes.Interface, clusterWide bool) *Snapshot {
+	if snapshot, ok := clientset.(*Snapshot); ok {
+		clientset = snapshot.Interface
+	}
+	return &Snapshot{Interface: clientset, snapshotCache: newSnapshotCache(clusterWide)}
+}
+
+func getSnapshotEntry[T any](s *snapshotCache, key snapshotKey) *snapshotEntry[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[key]; ok {
+		return entry.(*snapshotEntry[T])
+	}
+	entry := &snapshotEntry[T]{}
+	s.entries[key] = entry
+	return entry
+}
+
+// snapshotList returns the items of a resource in namespace, or in every
+// namespace when namespace is empty, matching the label selector of opts.
+// list fetches one page of the resource, returning its continue token.
+func snapshotList[T any, PT interface {
+	*T
+	metav1.Object
+}](ctx context.Context, s *snapshotCache, resource, namespace string, opts metav1.ListOptions, list func(ctx context.Context, namespace string, opts metav1.ListOptions) ([]T, string, error)) ([]T, error) {
+	if opts.FieldSelector != "" || opts.ResourceVersion != "" || opts.Limit != 0 || opts.Continue != "" {
+		items, _, err := list(ctx, namespace, opts)
+		return items, err
+	}
+
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	listNamespace := namespace
+	if s.clusterWide {
+		listNamespace = metav1.NamespaceAll
+	}
+
+	entry := getSnapshotEntry[T](s, snapshotKey{resource: resource, namespace: listNamespace})
+	if err := entry.load(ctx, listNamespace, list, func(item *T) string { return PT(item).GetNamespace() }); err != nil {
+		return nil, err
+	}
+
+	cached := entry.all
+	if namespace != metav1.NamespaceAll {
+		cached = entry.byNamespace[namespace]
+	}
+
+	items := make([]T, 0, len(cached))
+	for _, item := range cached {
+		if selector.Matches(labels.Set(PT(&item).GetLabels())) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// snapshotGet looks name up in the cached list of a resource
+func snapshotGet[T any, PT interface {
+	*T
+	metav1.Object
+}](ctx context.Context, s *snapshotCache, resource, namespace, name string, list func(ctx context.Context, namespace string, opts metav1.ListOptions) ([]T, string, error)) (*T, error) {
+	items, err := snapshotList[T, PT](ctx, s, resource, namespace, metav1.ListOptions{}, list)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if PT(&items[i]).GetName() == name {
+			return &items[i], nil
+		}
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: resource}, name)
+}
+
+func (s *Snapshot) CoreV1() corev1client.CoreV1Interface {
+	return &snapshotCoreV1{CoreV1Interface: s.Interface.CoreV1(), snapshot: s}
+}
+
+func (s *Snapshot) AppsV1() appsv1client.AppsV1Interface {
+	return &snapshotAppsV1{AppsV1Interface: s.Interface.AppsV1(), snapshot: s}
+}
+
+func (s *Snapshot) BatchV1() batchv1client.BatchV1Interface {
+	return &snapshotBatchV1{BatchV1Interface: s.Interface.BatchV1(), snapshot: s}
+}
+
+func (s *Snapshot) CoordinationV1() coordinationv1client.CoordinationV1Interface {
+	return &snapshotCoordinationV1{CoordinationV1Interface: s.Interface.CoordinationV1(), snapshot: s}
+}
+
+func (s *Snapshot) RbacV1() rbacv1client.RbacV1Interface {
+	return &snapshotRbacV1{RbacV1Interface: s.Interface.RbacV1(), snapshot: s}
+}
+
+func (s *Snapshot) NetworkingV1() networkingv1client.NetworkingV1Interface {
+	return &snapshotNetworkingV1{NetworkingV1Interface: s.Interface.NetworkingV1(), snapshot: s}
+}
+
+func (s *Snapshot) PolicyV1() policyv1client.PolicyV1Interface {
+	return &snapshotPolicyV1{PolicyV1Interface: s.Interface.PolicyV1(), snapshot: s}
+}
+
+func (s *Snapshot) AutoscalingV2() autoscalingv2client.AutoscalingV2Interface {
+	return &snapshotAutoscalingV2{AutoscalingV2Interface: s.Interface.AutoscalingV2(), snapshot: s}
+}
+
+func (s *Snapshot) StorageV1() storagev1client.StorageV1Interface {
+	return &snapshotStorageV1{StorageV1Interface: s.Interface.StorageV1(), snapshot: s}
+}
+
+func (s *Snapshot) SchedulingV1() schedulingv1client.SchedulingV1Interface {
+	return &snapshotSchedulingV1{SchedulingV1Interface: s.Interface.SchedulingV1(), snapshot: s}
+}
+
+func (s *Snapshot) DiscoveryV1() discoveryv1client.DiscoveryV1Interface {
+	return &snapshotDiscoveryV1{DiscoveryV1Interface: s.Interface.DiscoveryV1(), snapshot: s}
+}
+
+// core/v1
+
+type snapshotCoreV1 struct {
+	corev1client.CoreV1Interface
+	snapshot *Snapshot
+}
+
+func (c *snapshotCoreV1) Pods(namespace string) corev1client.PodInterface {
+	return &snapshotPods{c.CoreV1Interface.Pods(namespace), c, namespace}
+}
+
+func (c *snapshotCoreV1) ConfigMaps(namespace string) corev1client.ConfigMapInterface {
+	return &snapshotConfigMaps{c.CoreV1Interface.ConfigMaps(namespace), c, namespace}
+}
+
+func (c *snapshotCoreV1) Secrets(namespace string) corev1client.SecretInterface {
+	return &snapshotSecrets{c.CoreV1Interface.Secrets(namespace), c, namespace}
+}
+
+func (c *snapshotCoreV1) ServiceAccounts(namespace string) corev1client.ServiceAccountInterface {
+	return &snapshotServiceAccounts{c.CoreV1Interface.ServiceAccounts(namespace), c, namespace}
+}
+
+func (c *snapshotCoreV1) Services(namespace string) corev1client.ServiceInterface {
+	return &snapshotServices{c.CoreV1Interface.Services(namespace), c, namespace}
+}
+
+func (c *snapshotCoreV1) PersistentVolumeClaims(namespace string) corev1client.PersistentVolumeClaimInterface {
+	return &snapshotPersistentVolumeClaims{c.CoreV1Interface.PersistentVolumeClaims(namespace), c, namespace}
+}
+
+func (c *snapshotCoreV1) ResourceQuotas(namespace string) corev1client.ResourceQuotaInterface {
+	return &snapshotResourceQuotas{c.CoreV1Interface.ResourceQuotas(namespace), c, namespace}
+}
+
+func (c *snapshotCoreV1) LimitRanges(namespace string) corev1client.LimitRangeInterface {
+	return &snapshotLimitRanges{c.CoreV1Interface.LimitRanges(namespace), c, namespace}
+}
+
+func (c *snapshotCoreV1) PersistentVolumes() corev1client.PersistentVolumeInterface {
+	return &snapshotPersistentVolumes{c.CoreV1Interface.PersistentVolumes(), c}
+}
+
+func (c *snapshotCoreV1) Namespaces() corev1client.NamespaceInterface {
+	return &snapshotNamespaces{c.CoreV1Interface.Namespaces(), c}
+}
+
+func (c *snapshotCoreV1) Nodes() corev1client.NodeInterface {
+	return &snapshotNodes{c.CoreV1Interface.Nodes(), c}
+}
+
+type snapshotPods struct {
+	corev1client.PodInterface
+	group     *snapshotCoreV1
+	namespace string
+}
+
+func (c *snapshotPods) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]corev1.Pod, string, error) {
+	list, err := c.group.CoreV1Interface.Pods(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotPods) List(ctx context.Context, opts metav1.ListOptions) (*corev1.PodList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "pods", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.PodList{Items: items}, nil
+}
+
+type snapshotConfigMaps struct {
+	corev1client.ConfigMapInterface
+	group     *snapshotCoreV1
+	namespace string
+}
+
+func (c *snapshotConfigMaps) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]corev1.ConfigMap, string, error) {
+	list, err := c.group.CoreV1Interface.ConfigMaps(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotConfigMaps) List(ctx context.Context, opts metav1.ListOptions) (*corev1.ConfigMapList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "configmaps", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMapList{Items: items}, nil
+}
+
+type snapshotSecrets struct {
+	corev1client.SecretInterface
+	group     *snapshotCoreV1
+	namespace string
+}
+
+func (c *snapshotSecrets) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]corev1.Secret, string, error) {
+	list, err := c.group.CoreV1Interface.Secrets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotSecrets) List(ctx context.Context, opts metav1.ListOptions) (*corev1.SecretList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "secrets", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.SecretList{Items: items}, nil
+}
+
+type snapshotServiceAccounts struct {
+	corev1client.ServiceAccountInterface
+	group     *snapshotCoreV1
+	namespace string
+}
+
+func (c *snapshotServiceAccounts) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]corev1.ServiceAccount, string, error) {
+	list, err := c.group.CoreV1Interface.ServiceAccounts(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotServiceAccounts) List(ctx context.Context, opts metav1.ListOptions) (*corev1.ServiceAccountList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "serviceaccounts", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ServiceAccountList{Items: items}, nil
+}
+
+// Get is served from the cache in cluster-wide snapshots, where the
+// clusterrolebindings detector looks up the subjects of every binding
+func (c *snapshotServiceAccounts) Get(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.ServiceAccount, error) {
+	if !c.group.snapshot.clusterWide || opts.ResourceVersion != "" {
+		return c.ServiceAccountInterface.Get(ctx, name, opts)
+	}
+	return snapshotGet(ctx, c.group.snapshot.snapshotCache, "serviceaccounts", c.namespace, name, c.list)
+}
+
+type snapshotServices struct {
+	corev1client.ServiceInterface
+	group     *snapshotCoreV1
+	namespace string
+}
+
+func (c *snapshotServices) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]corev1.Service, string, error) {
+	list, err := c.group.CoreV1Interface.Services(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotServices) List(ctx context.Context, opts metav1.ListOptions) (*corev1.ServiceList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "services", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ServiceList{Items: items}, nil
+}
+
+// Get is served from the cache in cluster-wide snapshots, where the ingress
+// detector looks up the backend of every ingress
+func (c *snapshotServices) Get(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Service, error) {
+	if !c.group.snapshot.clusterWide || opts.ResourceVersion != "" {
+		return c.ServiceInterface.Get(ctx, name, opts)
+	}
+	return snapshotGet(ctx, c.group.snapshot.snapshotCache, "services", c.namespace, name, c.list)
+}
+
+type snapshotPersistentVolumeClaims struct {
+	corev1client.PersistentVolumeClaimInterface
+	group     *snapshotCoreV1
+	namespace string
+}
+
+func (c *snapshotPersistentVolumeClaims) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]corev1.PersistentVolumeClaim, string, error) {
+	list, err := c.group.CoreV1Interface.PersistentVolumeClaims(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotPersistentVolumeClaims) List(ctx context.Context, opts metav1.ListOptions) (*corev1.PersistentVolumeClaimList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "persistentvolumeclaims", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.PersistentVolumeClaimList{Items: items}, nil
+}
+
+type snapshotResourceQuotas struct {
+	corev1client.ResourceQuotaInterface
+	group     *snapshotCoreV1
+	namespace string
+}
+
+func (c *snapshotResourceQuotas) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]corev1.ResourceQuota, string, error) {
+	list, err := c.group.CoreV1Interface.ResourceQuotas(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotResourceQuotas) List(ctx context.Context, opts metav1.ListOptions) (*corev1.ResourceQuotaList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "resourcequotas", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ResourceQuotaList{Items: items}, nil
+}
+
+type snapshotLimitRanges struct {
+	corev1client.LimitRangeInterface
+	group     *snapshotCoreV1
+	namespace string
+}
+
+func (c *snapshotLimitRanges) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]corev1.LimitRange, string, error) {
+	list, err := c.group.CoreV1Interface.LimitRanges(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotLimitRanges) List(ctx context.Context, opts metav1.ListOptions) (*corev1.LimitRangeList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "limitranges", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.LimitRangeList{Items: items}, nil
+}
+
+type snapshotPersistentVolumes struct {
+	corev1client.PersistentVolumeInterface
+	group *snapshotCoreV1
+}
+
+func (c *snapshotPersistentVolumes) list(ctx context.Context, _ string, opts metav1.ListOptions) ([]corev1.PersistentVolume, string, error) {
+	list, err := c.PersistentVolumeInterface.List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotPersistentVolumes) List(ctx context.Context, opts metav1.ListOptions) (*corev1.PersistentVolumeList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "persistentvolumes", metav1.NamespaceAll, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.PersistentVolumeList{Items: items}, nil
+}
+
+// Get is served from the cache in cluster-wide snapshots, where the
+// volumeattachments detector looks up the volume of every attachment
+func (c *snapshotPersistentVolumes) Get(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.PersistentVolume, error) {
+	if !c.group.snapshot.clusterWide || opts.ResourceVersion != "" {
+		return c.PersistentVolumeInterface.Get(ctx, name, opts)
+	}
+	return snapshotGet(ctx, c.group.snapshot.snapshotCache, "persistentvolumes", metav1.NamespaceAll, name, c.list)
+}
+
+type snapshotNamespaces struct {
+	corev1client.NamespaceInterface
+	group *snapshotCoreV1
+}
+
+func (c *snapshotNamespaces) list(ctx context.Context, _ string, opts metav1.ListOptions) ([]corev1.Namespace, string, error) {
+	list, err := c.NamespaceInterface.List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotNamespaces) List(ctx context.Context, opts metav1.ListOptions) (*corev1.NamespaceList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "namespaces", metav1.NamespaceAll, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.NamespaceList{Items: items}, nil
+}
+
+type snapshotNodes struct {
+	corev1client.NodeInterface
+	group *snapshotCoreV1
+}
+
+func (c *snapshotNodes) list(ctx context.Context, _ string, opts metav1.ListOptions) ([]corev1.Node, string, error) {
+	list, err := c.NodeInterface.List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotNodes) List(ctx context.Context, opts metav1.ListOptions) (*corev1.NodeList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "nodes", metav1.NamespaceAll, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.NodeList{Items: items}, nil
+}
+
+// Get is served from the cache in cluster-wide snapshots, where the
+// volumeattachments detector looks up the node of every attachment
+func (c *snapshotNodes) Get(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Node, error) {
+	if !c.group.snapshot.clusterWide || opts.ResourceVersion != "" {
+		return c.NodeInterface.Get(ctx, name, opts)
+	}
+	return snapshotGet(ctx, c.group.snapshot.snapshotCache, "nodes", metav1.NamespaceAll, name, c.list)
+}
+
+// apps/v1
+
+type snapshotAppsV1 struct {
+	appsv1client.AppsV1Interface
+	snapshot *Snapshot
+}
+
+func (c *snapshotAppsV1) Deployments(namespace string) appsv1client.DeploymentInterface {
+	return &snapshotDeployments{c.AppsV1Interface.Deployments(namespace), c, namespace}
+}
+
+func (c *snapshotAppsV1) StatefulSets(namespace string) appsv1client.StatefulSetInterface {
+	return &snapshotStatefulSets{c.AppsV1Interface.StatefulSets(namespace), c, namespace}
+}
+
+func (c *snapshotAppsV1) ReplicaSets(namespace string) appsv1client.ReplicaSetInterface {
+	return &snapshotReplicaSets{c.AppsV1Interface.ReplicaSets(namespace), c, namespace}
+}
+
+func (c *snapshotAppsV1) DaemonSets(namespace string) appsv1client.DaemonSetInterface {
+	return &snapshotDaemonSets{c.AppsV1Interface.DaemonSets(namespace), c, namespace}
+}
+
+type snapshotDeployments struct {
+	appsv1client.DeploymentInterface
+	group     *snapshotAppsV1
+	namespace string
+}
+
+func (c *snapshotDeployments) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]appsv1.Deployment, string, error) {
+	list, err := c.group.AppsV1Interface.Deployments(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotDeployments) List(ctx context.Context, opts metav1.ListOptions) (*appsv1.DeploymentList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "deployments", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &appsv1.DeploymentList{Items: items}, nil
+}
+
+type snapshotStatefulSets struct {
+	appsv1client.StatefulSetInterface
+	group     *snapshotAppsV1
+	namespace string
+}
+
+func (c *snapshotStatefulSets) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]appsv1.StatefulSet, string, error) {
+	list, err := c.group.AppsV1Interface.StatefulSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotStatefulSets) List(ctx context.Context, opts metav1.ListOptions) (*appsv1.StatefulSetList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "statefulsets", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &appsv1.StatefulSetList{Items: items}, nil
+}
+
+type snapshotReplicaSets struct {
+	appsv1client.ReplicaSetInterface
+	group     *snapshotAppsV1
+	namespace string
+}
+
+func (c *snapshotReplicaSets) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]appsv1.ReplicaSet, string, error) {
+	list, err := c.group.AppsV1Interface.ReplicaSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotReplicaSets) List(ctx context.Context, opts metav1.ListOptions) (*appsv1.ReplicaSetList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "replicasets", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &appsv1.ReplicaSetList{Items: items}, nil
+}
+
+type snapshotDaemonSets struct {
+	appsv1client.DaemonSetInterface
+	group     *snapshotAppsV1
+	namespace string
+}
+
+func (c *snapshotDaemonSets) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]appsv1.DaemonSet, string, error) {
+	list, err := c.group.AppsV1Interface.DaemonSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotDaemonSets) List(ctx context.Context, opts metav1.ListOptions) (*appsv1.DaemonSetList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "daemonsets", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &appsv1.DaemonSetList{Items: items}, nil
+}
+
+// batch/v1
+
+type snapshotBatchV1 struct {
+	batchv1client.BatchV1Interface
+	snapshot *Snapshot
+}
+
+func (c *snapshotBatchV1) Jobs(namespace string) batchv1client.JobInterface {
+	return &snapshotJobs{c.BatchV1Interface.Jobs(namespace), c, namespace}
+}
+
+func (c *snapshotBatchV1) CronJobs(namespace string) batchv1client.CronJobInterface {
+	return &snapshotCronJobs{c.BatchV1Interface.CronJobs(namespace), c, namespace}
+}
+
+type snapshotJobs struct {
+	batchv1client.JobInterface
+	group     *snapshotBatchV1
+	namespace string
+}
+
+func (c *snapshotJobs) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]batchv1.Job, string, error) {
+	list, err := c.group.BatchV1Interface.Jobs(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotJobs) List(ctx context.Context, opts metav1.ListOptions) (*batchv1.JobList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "jobs", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &batchv1.JobList{Items: items}, nil
+}
+
+type snapshotCronJobs struct {
+	batchv1client.CronJobInterface
+	group     *snapshotBatchV1
+	namespace string
+}
+
+func (c *snapshotCronJobs) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]batchv1.CronJob, string, error) {
+	list, err := c.group.BatchV1Interface.CronJobs(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotCronJobs) List(ctx context.Context, opts metav1.ListOptions) (*batchv1.CronJobList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "cronjobs", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &batchv1.CronJobList{Items: items}, nil
+}
+
+// coordination/v1
+
+type snapshotCoordinationV1 struct {
+	coordinationv1client.CoordinationV1Interface
+	snapshot *Snapshot
+}
+
+func (c *snapshotCoordinationV1) Leases(namespace string) coordinationv1client.LeaseInterface {
+	return &snapshotLeases{c.CoordinationV1Interface.Leases(namespace), c, namespace}
+}
+
+type snapshotLeases struct {
+	coordinationv1client.LeaseInterface
+	group     *snapshotCoordinationV1
+	namespace string
+}
+
+func (c *snapshotLeases) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]coordinationv1.Lease, string, error) {
+	list, err := c.group.CoordinationV1Interface.Leases(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotLeases) List(ctx context.Context, opts metav1.ListOptions) (*coordinationv1.LeaseList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "leases", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &coordinationv1.LeaseList{Items: items}, nil
+}
+
+// rbac/v1
+
+type snapshotRbacV1 struct {
+	rbacv1client.RbacV1Interface
+	snapshot *Snapshot
+}
+
+func (c *snapshotRbacV1) Roles(namespace string) rbacv1client.RoleInterface {
+	return &snapshotRoles{c.RbacV1Interface.Roles(namespace), c, namespace}
+}
+
+func (c *snapshotRbacV1) RoleBindings(namespace string) rbacv1client.RoleBindingInterface {
+	return &snapshotRoleBindings{c.RbacV1Interface.RoleBindings(namespace), c, namespace}
+}
+
+func (c *snapshotRbacV1) ClusterRoles() rbacv1client.ClusterRoleInterface {
+	return &snapshotClusterRoles{c.RbacV1Interface.ClusterRoles(), c}
+}
+
+func (c *snapshotRbacV1) ClusterRoleBindings() rbacv1client.ClusterRoleBindingInterface {
+	return &snapshotClusterRoleBindings{c.RbacV1Interface.ClusterRoleBindings(), c}
+}
+
+type snapshotRoles struct {
+	rbacv1client.RoleInterface
+	group     *snapshotRbacV1
+	namespace string
+}
+
+func (c *snapshotRoles) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]rbacv1.Role, string, error) {
+	list, err := c.group.RbacV1Interface.Roles(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotRoles) List(ctx context.Context, opts metav1.ListOptions) (*rbacv1.RoleList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "roles", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &rbacv1.RoleList{Items: items}, nil
+}
+
+type snapshotRoleBindings struct {
+	rbacv1client.RoleBindingInterface
+	group     *snapshotRbacV1
+	namespace string
+}
+
+func (c *snapshotRoleBindings) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]rbacv1.RoleBinding, string, error) {
+	list, err := c.group.RbacV1Interface.RoleBindings(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotRoleBindings) List(ctx context.Context, opts metav1.ListOptions) (*rbacv1.RoleBindingList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "rolebindings", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &rbacv1.RoleBindingList{Items: items}, nil
+}
+
+type snapshotClusterRoles struct {
+	rbacv1client.ClusterRoleInterface
+	group *snapshotRbacV1
+}
+
+func (c *snapshotClusterRoles) list(ctx context.Context, _ string, opts metav1.ListOptions) ([]rbacv1.ClusterRole, string, error) {
+	list, err := c.ClusterRoleInterface.List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotClusterRoles) List(ctx context.Context, opts metav1.ListOptions) (*rbacv1.ClusterRoleList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "clusterroles", metav1.NamespaceAll, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &rbacv1.ClusterRoleList{Items: items}, nil
+}
+
+type snapshotClusterRoleBindings struct {
+	rbacv1client.ClusterRoleBindingInterface
+	group *snapshotRbacV1
+}
+
+func (c *snapshotClusterRoleBindings) list(ctx context.Context, _ string, opts metav1.ListOptions) ([]rbacv1.ClusterRoleBinding, string, error) {
+	list, err := c.ClusterRoleBindingInterface.List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotClusterRoleBindings) List(ctx context.Context, opts metav1.ListOptions) (*rbacv1.ClusterRoleBindingList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "clusterrolebindings", metav1.NamespaceAll, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &rbacv1.ClusterRoleBindingList{Items: items}, nil
+}
+
+// networking/v1
+
+type snapshotNetworkingV1 struct {
+	networkingv1client.NetworkingV1Interface
+	snapshot *Snapshot
+}
+
+func (c *snapshotNetworkingV1) Ingresses(namespace string) networkingv1client.IngressInterface {
+	return &snapshotIngresses{c.NetworkingV1Interface.Ingresses(namespace), c, namespace}
+}
+
+func (c *snapshotNetworkingV1) NetworkPolicies(namespace string) networkingv1client.NetworkPolicyInterface {
+	return &snapshotNetworkPolicies{c.NetworkingV1Interface.NetworkPolicies(namespace), c, namespace}
+}
+
+type snapshotIngresses struct {
+	networkingv1client.IngressInterface
+	group     *snapshotNetworkingV1
+	namespace string
+}
+
+func (c *snapshotIngresses) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]networkingv1.Ingress, string, error) {
+	list, err := c.group.NetworkingV1Interface.Ingresses(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotIngresses) List(ctx context.Context, opts metav1.ListOptions) (*networkingv1.IngressList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "ingresses", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &networkingv1.IngressList{Items: items}, nil
+}
+
+type snapshotNetworkPolicies struct {
+	networkingv1client.NetworkPolicyInterface
+	group     *snapshotNetworkingV1
+	namespace string
+}
+
+func (c *snapshotNetworkPolicies) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]networkingv1.NetworkPolicy, string, error) {
+	list, err := c.group.NetworkingV1Interface.NetworkPolicies(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotNetworkPolicies) List(ctx context.Context, opts metav1.ListOptions) (*networkingv1.NetworkPolicyList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "networkpolicies", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &networkingv1.NetworkPolicyList{Items: items}, nil
+}
+
+// policy/v1
+
+type snapshotPolicyV1 struct {
+	policyv1client.PolicyV1Interface
+	snapshot *Snapshot
+}
+
+func (c *snapshotPolicyV1) PodDisruptionBudgets(namespace string) policyv1client.PodDisruptionBudgetInterface {
+	return &snapshotPodDisruptionBudgets{c.PolicyV1Interface.PodDisruptionBudgets(namespace), c, namespace}
+}
+
+type snapshotPodDisruptionBudgets struct {
+	policyv1client.PodDisruptionBudgetInterface
+	group     *snapshotPolicyV1
+	namespace string
+}
+
+func (c *snapshotPodDisruptionBudgets) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]policyv1.PodDisruptionBudget, string, error) {
+	list, err := c.group.PolicyV1Interface.PodDisruptionBudgets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotPodDisruptionBudgets) List(ctx context.Context, opts metav1.ListOptions) (*policyv1.PodDisruptionBudgetList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "poddisruptionbudgets", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &policyv1.PodDisruptionBudgetList{Items: items}, nil
+}
+
+// autoscaling/v2
+
+type snapshotAutoscalingV2 struct {
+	autoscalingv2client.AutoscalingV2Interface
+	snapshot *Snapshot
+}
+
+func (c *snapshotAutoscalingV2) HorizontalPodAutoscalers(namespace string) autoscalingv2client.HorizontalPodAutoscalerInterface {
+	return &snapshotHorizontalPodAutoscalers{c.AutoscalingV2Interface.HorizontalPodAutoscalers(namespace), c, namespace}
+}
+
+type snapshotHorizontalPodAutoscalers struct {
+	autoscalingv2client.HorizontalPodAutoscalerInterface
+	group     *snapshotAutoscalingV2
+	namespace string
+}
+
+func (c *snapshotHorizontalPodAutoscalers) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]autoscalingv2.HorizontalPodAutoscaler, string, error) {
+	list, err := c.group.AutoscalingV2Interface.HorizontalPodAutoscalers(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotHorizontalPodAutoscalers) List(ctx context.Context, opts metav1.ListOptions) (*autoscalingv2.HorizontalPodAutoscalerList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "horizontalpodautoscalers", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &autoscalingv2.HorizontalPodAutoscalerList{Items: items}, nil
+}
+
+// storage/v1
+
+type snapshotStorageV1 struct {
+	storagev1client.StorageV1Interface
+	snapshot *Snapshot
+}
+
+func (c *snapshotStorageV1) StorageClasses() storagev1client.StorageClassInterface {
+	return &snapshotStorageClasses{c.StorageV1Interface.StorageClasses(), c}
+}
+
+func (c *snapshotStorageV1) VolumeAttachments() storagev1client.VolumeAttachmentInterface {
+	return &snapshotVolumeAttachments{c.StorageV1Interface.VolumeAttachments(), c}
+}
+
+type snapshotStorageClasses struct {
+	storagev1client.StorageClassInterface
+	group *snapshotStorageV1
+}
+
+func (c *snapshotStorageClasses) list(ctx context.Context, _ string, opts metav1.ListOptions) ([]storagev1.StorageClass, string, error) {
+	list, err := c.StorageClassInterface.List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotStorageClasses) List(ctx context.Context, opts metav1.ListOptions) (*storagev1.StorageClassList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "storageclasses", metav1.NamespaceAll, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &storagev1.StorageClassList{Items: items}, nil
+}
+
+type snapshotVolumeAttachments struct {
+	storagev1client.VolumeAttachmentInterface
+	group *snapshotStorageV1
+}
+
+func (c *snapshotVolumeAttachments) list(ctx context.Context, _ string, opts metav1.ListOptions) ([]storagev1.VolumeAttachment, string, error) {
+	list, err := c.VolumeAttachmentInterface.List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotVolumeAttachments) List(ctx context.Context, opts metav1.ListOptions) (*storagev1.VolumeAttachmentList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "volumeattachments", metav1.NamespaceAll, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &storagev1.VolumeAttachmentList{Items: items}, nil
+}
+
+// scheduling/v1
+
+type snapshotSchedulingV1 struct {
+	schedulingv1client.SchedulingV1Interface
+	snapshot *Snapshot
+}
+
+func (c *snapshotSchedulingV1) PriorityClasses() schedulingv1client.PriorityClassInterface {
+	return &snapshotPriorityClasses{c.SchedulingV1Interface.PriorityClasses(), c}
+}
+
+type snapshotPriorityClasses struct {
+	schedulingv1client.PriorityClassInterface
+	group *snapshotSchedulingV1
+}
+
+func (c *snapshotPriorityClasses) list(ctx context.Context, _ string, opts metav1.ListOptions) ([]schedulingv1.PriorityClass, string, error) {
+	list, err := c.PriorityClassInterface.List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotPriorityClasses) List(ctx context.Context, opts metav1.ListOptions) (*schedulingv1.PriorityClassList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "priorityclasses", metav1.NamespaceAll, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &schedulingv1.PriorityClassList{Items: items}, nil
+}
+
+// discovery/v1
+
+type snapshotDiscoveryV1 struct {
+	discoveryv1client.DiscoveryV1Interface
+	snapshot *Snapshot
+}
+
+func (c *snapshotDiscoveryV1) EndpointSlices(namespace string) discoveryv1client.EndpointSliceInterface {
+	return &snapshotEndpointSlices{c.DiscoveryV1Interface.EndpointSlices(namespace), c, namespace}
+}
+
+type snapshotEndpointSlices struct {
+	discoveryv1client.EndpointSliceInterface
+	group     *snapshotDiscoveryV1
+	namespace string
+}
+
+func (c *snapshotEndpointSlices) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]discoveryv1.EndpointSlice, string, error) {
+	list, err := c.group.DiscoveryV1Interface.EndpointSlices(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.Continue, nil
+}
+
+func (c *snapshotEndpointSlices) List(ctx context.Context, opts metav1.ListOptions) (*discoveryv1.EndpointSliceList, error) {
+	items, err := snapshotList(ctx, c.group.snapshot.snapshotCache, "endpointslices", c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &discoveryv1.EndpointSliceList{Items: items}, nil
+}
+
+// dynamic
+
+// DynamicSnapshot is the Snapshot of a dynamic.Interface, caching the Lists
+// of the kinds read through the dynamic client, such as the Gateway API
+// ones, by GroupVersionResource.
+//
+// A DynamicSnapshot never refreshes: create a new one for each scan.
+type DynamicSnapshot struct {
+	dynamic.Interface
+	*snapshotCache
+}
+
+// NewDynamicSnapshot wraps dynamicClient in a DynamicSnapshot, clusterWide
+// being set as for NewSnapshot
+func NewDynamicSnapshot(dynamicClient dynamic.Interface, clusterWide bool) *DynamicSnapshot {
+	if snapshot, ok := dynamicClient.(*DynamicSnapshot); ok {
+		dynamicClient = snapshot.Interface
+	}
+	return &DynamicSnapshot{Interface: dynamicClient, snapshotCache: newSnapshotCache(clusterWide)}
+}
+
+func (s *DynamicSnapshot) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &snapshotDynamicResource{s.Interface.Resource(gvr), s, gvr, metav1.NamespaceAll}
+}
+
+type snapshotDynamicResource struct {
+	dynamic.NamespaceableResourceInterface
+	snapshot  *DynamicSnapshot
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+func (c *snapshotDynamicResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return &snapshotDynamicResource{c.NamespaceableResourceInterface, c.snapshot, c.gvr, namespace}
+}
+
+func (c *snapshotDynamicResource) list(ctx context.Context, namespace string, opts metav1.ListOptions) ([]unstructured.Unstructured, string, error) {
+	list, err := c.NamespaceableResourceInterface.Namespace(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Items, list.GetContinue(), nil
+}
+
+func (c *snapshotDynamicResource) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	items, err := snapshotList(ctx, c.snapshot.snapshotCache, c.gvr.String(), c.namespace, opts, c.list)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.UnstructuredList{Items: items}, nil
+}
@@ -0,0 +1,223 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func countListActions(client interface{ Actions() []k8stesting.Action }, resource string) int {
+	return countActions(client, "list", resource)
+}
+
+func countActions(client interface{ Actions() []k8stesting.Action }, verb, resource string) int {
+	count := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == verb && action.GetResource().Resource == resource {
+			count++
+		}
+	}
+	return count
+}
+
+func createSnapshotTestPods(t *testing.T, clientset *fake.Clientset) {
+	for _, namespace := range []string{"ns-1", "ns-2"} {
+		for _, pod := range []struct {
+			name   string
+			labels map[string]string
+		}{
+			{name: "pod-a", labels: map[string]string{"app": "a"}},
+			{name: "pod-b", labels: map[string]string{"app": "b"}},
+		} {
+			_, err := clientset.CoreV1().Pods(namespace).Create(context.TODO(), CreateTestPod(namespace, pod.name, "", nil, pod.labels), v1.CreateOptions{})
+			if err != nil {
+				t.Fatalf("Error creating fake pod: %v", err)
+			}
+		}
+	}
+	clientset.ClearActions()
+}
+
+func TestSnapshotListsOncePerKind(t *testing.T) {
+	clientset := fake.NewClientset()
+	createSnapshotTestPods(t, clientset)
+
+	snapshot := NewSnapshot(clientset, true)
+	for _, namespace := range []string{"ns-1", "ns-2", "ns-1"} {
+		pods, err := snapshot.CoreV1().Pods(namespace).List(context.TODO(), v1.ListOptions{})
+		if err != nil {
+			t.Fatalf("Error listing pods: %v", err)
+		}
+		if len(pods.Items) != 2 {
+			t.Errorf("Expected 2 pods in %s, got %d", namespace, len(pods.Items))
+		}
+		for _, pod := range pods.Items {
+			if pod.Namespace != namespace {
+				t.Errorf("Expected pods of %s, got %s/%s", namespace, pod.Namespace, pod.Name)
+			}
+		}
+	}
+
+	pods, err := snapshot.CoreV1().Pods("").List(context.TODO(), v1.ListOptions{LabelSelector: "app=a"})
+	if err != nil {
+		t.Fatalf("Error listing pods: %v", err)
+	}
+	if len(pods.Items) != 2 {
+		t.Errorf("Expected 2 pods matching app=a, got %d", len(pods.Items))
+	}
+
+	if count := countListActions(clientset, "pods"); count != 1 {
+		t.Errorf("Expected pods to be listed once, got %d lists", count)
+	}
+	if list := clientset.Actions()[0].(k8stesting.ListAction); list.GetNamespace() != "" {
+		t.Errorf("Expected a cluster-wide list, got namespace %q", list.GetNamespace())
+	}
+}
+
+func TestSnapshotPerNamespace(t *testing.T) {
+	clientset := fake.NewClientset()
+	createSnapshotTestPods(t, clientset)
+
+	snapshot := NewSnapshot(clientset, false)
+	for _, namespace := range []string{"ns-1", "ns-1", "ns-2"} {
+		if _, err := snapshot.CoreV1().Pods(namespace).List(context.TODO(), v1.ListOptions{}); err != nil {
+			t.Fatalf("Error listing pods: %v", err)
+		}
+	}
+	if count := countListActions(clientset, "pods"); count != 2 {
+		t.Errorf("Expected pods to be listed once per namespace, got %d lists", count)
+	}
+
+	if _, err := snapshot.CoreV1().Pods("ns-1").List(context.TODO(), v1.ListOptions{FieldSelector: "status.phase=Running"}); err != nil {
+		t.Fatalf("Error listing pods: %v", err)
+	}
+	if count := countListActions(clientset, "pods"); count != 3 {
+		t.Errorf("Expected field selector lists to reach the API server, got %d lists", count)
+	}
+}
+
+func TestSnapshotRetriesFailedList(t *testing.T) {
+	clientset := fake.NewClientset()
+	createSnapshotTestPods(t, clientset)
+	failures := 1
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if failures == 0 {
+			return false, nil, nil
+		}
+		failures--
+		return true, nil, context.DeadlineExceeded
+	})
+
+	snapshot := NewSnapshot(clientset, true)
+	if _, err := snapshot.CoreV1().Pods("ns-1").List(context.TODO(), v1.ListOptions{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the list to fail, got %v", err)
+	}
+	pods, err := snapshot.CoreV1().Pods("ns-1").List(context.TODO(), v1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Expected the failed list not to be cached, got %v", err)
+	}
+	if len(pods.Items) != 2 {
+		t.Errorf("Expected 2 pods, got %d", len(pods.Items))
+	}
+
+	if _, err := snapshot.CoreV1().Pods("ns-2").List(context.TODO(), v1.ListOptions{}); err != nil {
+		t.Fatalf("Error listing pods: %v", err)
+	}
+	if count := countListActions(clientset, "pods"); count != 2 {
+		t.Errorf("Expected pods to be listed again after the failure only, got %d lists", count)
+	}
+}
+
+func TestSnapshotGet(t *testing.T) {
+	clientset := fake.NewClientset()
+	_, err := clientset.CoreV1().ServiceAccounts(testNamespace).Create(context.TODO(), CreateTestServiceAccount(testNamespace, "test-sa", AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake serviceaccount: %v", err)
+	}
+
+	snapshot := NewSnapshot(clientset, true)
+	sa, err := snapshot.CoreV1().ServiceAccounts(testNamespace).Get(context.TODO(), "test-sa", v1.GetOptions{})
+	if err != nil || sa.Name != "test-sa" {
+		t.Errorf("Expected test-sa, got %v, %v", sa, err)
+	}
+
+	_, err = snapshot.CoreV1().ServiceAccounts(testNamespace).Get(context.TODO(), "missing-sa", v1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
+
+func TestGetUnusedReportListsOncePerKind(t *testing.T) {
+	clientset := fake.NewClientset()
+	for _, namespace := range []string{"ns-1", "ns-2"} {
+		_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(namespace, AppLabels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating namespace %s: %v", namespace, err)
+		}
+	}
+	createSnapshotTestPods(t, clientset)
+
+	detectors, err := resolveDetectors([]string{"cm", "secret", "sa", "pvc"})
+	if err != nil {
+		t.Fatalf("Expected all resource types to be supported, got %v", err)
+	}
+	if _, err := GetUnusedReport(context.TODO(), detectors, &filters.Options{}, Clients{Clientset: clientset}, common.Opts{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if count := countListActions(clientset, "pods"); count != 1 {
+		t.Errorf("Expected pods to be listed once per scan, got %d lists", count)
+	}
+}
+
+func TestGetUnusedReportListsQuotasOnce(t *testing.T) {
+	clientset := fake.NewClientset()
+	for _, namespace := range []string{"ns-1", "ns-2"} {
+		_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(namespace, AppLabels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating namespace %s: %v", namespace, err)
+		}
+	}
+
+	detectors, err := resolveDetectors([]string{"quota", "limitrange"})
+	if err != nil {
+		t.Fatalf("Expected all resource types to be supported, got %v", err)
+	}
+	if _, err := GetUnusedReport(context.TODO(), detectors, &filters.Options{}, Clients{Clientset: clientset}, common.Opts{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, resource := range []string{"resourcequotas", "limitranges"} {
+		if count := countListActions(clientset, resource); count != 1 {
+			t.Errorf("Expected %s to be listed once per scan, got %d lists", resource, count)
+		}
+	}
+}
+
+func TestGetUnusedReportListsLeasesOnce(t *testing.T) {
+	clientset := createTestLeases(t)
+	clientset.ClearActions()
+
+	report, err := GetUnusedReport(context.TODO(), []Detector{leaseDetector}, &filters.Options{}, Clients{Clientset: clientset}, common.Opts{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Findings) != 2 {
+		t.Errorf("Expected the stale lease and the heartbeat of the missing node, got %v", report.Findings)
+	}
+
+	for _, resource := range []string{"leases", "nodes"} {
+		if count := countListActions(clientset, resource); count != 1 {
+			t.Errorf("Expected %s to be listed once per scan, got %d lists", resource, count)
+		}
+	}
+}
@@ -1,13 +1,10 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
-	"fmt"
-	"os"
 
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
@@ -18,8 +15,8 @@ import (
 //go:embed exceptions/statefulsets/statefulsets.json
 var statefulsetConfig []byte
 
-func processNamespaceStatefulSets(clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
-	statefulSetsList, err := clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processNamespaceStatefulSets(ctx context.Context, clientset kubernetes.Interface, namespace string, filterOpts *filters.Options, opts common.Opts) ([]ResourceInfo, error) {
+	statefulSetsList, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -62,52 +59,19 @@ func processNamespaceStatefulSets(clientset kubernetes.Interface, namespace stri
 			statefulSetsWithoutReplicas = append(statefulSetsWithoutReplicas, status)
 		}
 	}
-	if opts.DeleteFlag {
-		if statefulSetsWithoutReplicas, err = DeleteResource(statefulSetsWithoutReplicas, clientset, namespace, "StatefulSet", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete Statefulset %s in namespace %s: %v\n", statefulSetsWithoutReplicas, namespace, err)
-		}
-	}
 
 	return statefulSetsWithoutReplicas, nil
 }
 
-func GetUnusedStatefulSets(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	for _, namespace := range filterOpts.Namespaces(clientset) {
-		diff, err := processNamespaceStatefulSets(clientset, namespace, filterOpts, opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to process namespace %s: %v\n", namespace, err)
-			continue
-		}
-		switch opts.GroupBy {
-		case "namespace":
-			if diff != nil {
-				resources[namespace] = make(map[string][]ResourceInfo)
-				resources[namespace]["StatefulSet"] = diff
-			}
-		case "resource":
-			if diff != nil {
-				appendResources(resources, "StatefulSet", namespace, diff)
-			}
-		}
-	}
+var statefulSetDetector = newDetector("StatefulSet", []string{"statefulset", "sts", "statefulsets"}, NamespaceScoped, namespacedDetect(processNamespaceStatefulSets),
+	func(clients Clients, namespace string) typedClient[*appsv1.StatefulSet, *appsv1.StatefulSetList] {
+		return clients.Clientset.AppsV1().StatefulSets(namespace)
+	})
 
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
-
-	unusedStatefulsets, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(statefulSetDetector)
+}
 
-	return unusedStatefulsets, nil
+func GetUnusedStatefulSets(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{statefulSetDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
@@ -1,13 +1,11 @@
 package kor
 
 import (
-	"bytes"
 	"context"
 	_ "embed"
-	"encoding/json"
 	"fmt"
-	"os"
 
+	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -19,40 +17,38 @@ import (
 //go:embed exceptions/storageclasses/storageclasses.json
 var storageClassesConfig []byte
 
-func retrieveUsedStorageClasses(clientset kubernetes.Interface) ([]string, error) {
-	pvs, err := clientset.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+func retrieveUsedStorageClasses(ctx context.Context, clientset kubernetes.Interface) (references, error) {
+	pvs, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		fmt.Printf("Failed to list PVs: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to list PVs: %v", err)
 	}
 
-	pvcs, err := clientset.CoreV1().PersistentVolumeClaims("").List(context.TODO(), metav1.ListOptions{})
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims("").List(ctx, metav1.ListOptions{})
 	if err != nil {
-		fmt.Printf("Failed to list PVCs: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to list PVCs: %v", err)
 	}
 
-	var usedStorageClasses []string
+	usedStorageClasses := references{}
 
 	// Iterate through each PV and check for StorageClass usage
 	for _, pv := range pvs.Items {
 		if pv.Spec.StorageClassName != "" {
-			usedStorageClasses = append(usedStorageClasses, pv.Spec.StorageClassName)
+			usedStorageClasses.add(pv.Spec.StorageClassName, "PersistentVolume", "", pv.Name, "spec.storageClassName")
 		}
 	}
 
 	// Iterate through each PVC and check for StorageClass usage
 	for _, pvc := range pvcs.Items {
 		if pvc.Spec.StorageClassName != nil {
-			usedStorageClasses = append(usedStorageClasses, *pvc.Spec.StorageClassName)
+			usedStorageClasses.add(*pvc.Spec.StorageClassName, "PersistentVolumeClaim", pvc.Namespace, pvc.Name, "spec.storageClassName")
 		}
 	}
 
 	return usedStorageClasses, err
 }
 
-func processStorageClasses(clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	scs, err := clientset.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
+func processStorageClasses(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	scs, err := clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{LabelSelector: filterOpts.IncludeLabels})
 	if err != nil {
 		return nil, err
 	}
@@ -92,53 +88,27 @@ func processStorageClasses(clientset kubernetes.Interface, filterOpts *filters.O
 		storageClassNames = append(storageClassNames, sc.Name)
 	}
 
-	usedStorageClasses, err := retrieveUsedStorageClasses(clientset)
+	usedStorageClasses, err := retrieveUsedStorageClasses(ctx, clientset)
 	if err != nil {
 		return nil, err
 	}
 
-	diff := CalculateResourceDifference(usedStorageClasses, storageClassNames)
+	diff := CalculateResourceDifference(usedStorageClasses.names(), storageClassNames)
 	for _, name := range diff {
 		unusedStorageClasses = append(unusedStorageClasses, ResourceInfo{Name: name, Reason: "Not in Use"})
 	}
 	return unusedStorageClasses, nil
 }
 
-func GetUnusedStorageClasses(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	diff, err := processStorageClasses(clientset, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to process storageClasses: %v\n", err)
-	}
-	if opts.DeleteFlag {
-		if diff, err = DeleteResource(diff, clientset, "", "StorageClass", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete StorageClass %s: %v\n", diff, err)
-		}
-	}
-	switch opts.GroupBy {
-	case "namespace":
-		resources[""] = make(map[string][]ResourceInfo)
-		resources[""]["StorageClass"] = diff
-	case "resource":
-		appendResources(resources, "StorageClass", "", diff)
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		var err error
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var storageClassDetector = newDetector("StorageClass", []string{"storageclass", "sc", "storageclasses", "storageclassses"}, ClusterScoped, clusterDetect(processStorageClasses),
+	func(clients Clients, _ string) typedClient[*storagev1.StorageClass, *storagev1.StorageClassList] {
+		return clients.Clientset.StorageV1().StorageClasses()
+	})
 
-	unusedStorageClasses, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(storageClassDetector)
+}
 
-	return unusedStorageClasses, nil
+func GetUnusedStorageClasses(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{storageClassDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
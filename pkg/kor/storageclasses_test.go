@@ -27,33 +27,33 @@ func createTestStorageClass(t *testing.T) *fake.Clientset {
 
 func TestRetrieveUsedStorageClassesFromPVCs(t *testing.T) {
 	clientset := createTestPvcs(t)
-	usedStorageClasses, err := retrieveUsedStorageClasses(clientset)
+	usedStorageClasses, err := retrieveUsedStorageClasses(context.TODO(), clientset)
 
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
-	if !contains(usedStorageClasses, "test-sc1") {
+	if !contains(usedStorageClasses.names(), "test-sc1") {
 		t.Errorf("Expected 'test-sc1', got %v", usedStorageClasses)
 	}
 }
 
 func TestRetrieveUsedStorageClassesFromPVs(t *testing.T) {
 	clientset := createTestPvs(t)
-	usedStorageClasses, err := retrieveUsedStorageClasses(clientset)
+	usedStorageClasses, err := retrieveUsedStorageClasses(context.TODO(), clientset)
 
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
-	if !contains(usedStorageClasses, "test-sc1") {
+	if !contains(usedStorageClasses.names(), "test-sc1") {
 		t.Errorf("Expected 'test-sc1', got %v", usedStorageClasses)
 	}
 }
 
 func TestProcessStorageClasses(t *testing.T) {
 	clientset := createTestStorageClass(t)
-	unusedStorageClasses, err := processStorageClasses(clientset, &filters.Options{})
+	unusedStorageClasses, err := processStorageClasses(context.TODO(), clientset, &filters.Options{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -79,7 +79,7 @@ func TestGetUnusedStorageClassesStructured(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedStorageClasses(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedStorageClasses(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedStorageClasses: %v", err)
 	}
@@ -129,7 +129,7 @@ func TestFilterOwnerReferencedStorageClasses(t *testing.T) {
 
 	// Test without filter - should return both
 	filterOptsNoSkip := &filters.Options{IgnoreOwnerReferences: false}
-	unusedWithoutFilter, err := processStorageClasses(clientset, filterOptsNoSkip)
+	unusedWithoutFilter, err := processStorageClasses(context.TODO(), clientset, filterOptsNoSkip)
 	if err != nil {
 		t.Fatalf("Error retrieving unused StorageClasses: %v", err)
 	}
@@ -140,7 +140,7 @@ func TestFilterOwnerReferencedStorageClasses(t *testing.T) {
 
 	// Test with filter - should return only standalone
 	filterOptsWithSkip := &filters.Options{IgnoreOwnerReferences: true}
-	unusedWithFilter, err := processStorageClasses(clientset, filterOptsWithSkip)
+	unusedWithFilter, err := processStorageClasses(context.TODO(), clientset, filterOptsWithSkip)
 	if err != nil {
 		t.Fatalf("Error retrieving unused StorageClasses: %v", err)
 	}
@@ -0,0 +1,116 @@
+package kor
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/yonahd/kor/pkg/common"
+)
+
+// ErrThresholdExceeded is returned along with the output when the unused
+// resources exceed the thresholds of opts.FailOnFindings
+var ErrThresholdExceeded = errors.New("unused resources exceed the configured thresholds")
+
+// ThresholdViolation is a kind or namespace with more unused resources than
+// allowed. Both Kind and Namespace are empty when no threshold is set and
+// any unused resource fails the scan.
+type ThresholdViolation struct {
+	Kind      string `json:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Unused    int    `json:"unused"`
+	Max       int    `json:"max"`
+}
+
+func (v ThresholdViolation) String() string {
+	switch {
+	case v.Kind != "":
+		return fmt.Sprintf("%s: %d unused, max %d", v.Kind, v.Unused, v.Max)
+	case v.Namespace != "":
+		return fmt.Sprintf("namespace %s: %d unused, max %d", v.Namespace, v.Unused, v.Max)
+	default:
+		return fmt.Sprintf("%d unused resources found", v.Unused)
+	}
+}
+
+// thresholds holds the per-kind and per-namespace maximums of unused
+// resources, kinds being keyed on their detector name
+type thresholds struct {
+	enabled    bool
+	kinds      map[string]int
+	namespaces map[string]int
+}
+
+// newThresholds resolves the resource types of opts.MaxUnused to the kinds
+// reported in findings
+func newThresholds(opts common.Opts) (thresholds, error) {
+	t := thresholds{
+		enabled:    opts.FailOnFindings,
+		kinds:      make(map[string]int, len(opts.MaxUnused)),
+		namespaces: opts.MaxUnusedPerNamespace,
+	}
+	var errs []error
+	for resource, maxUnused := range opts.MaxUnused {
+		detector, ok := LookupDetector(resource)
+		if !ok {
+			errs = append(errs, fmt.Errorf("invalid --max-unused: resource type %q is not supported", resource))
+			continue
+		}
+		t.kinds[detector.Name()] = maxUnused
+	}
+	return t, errors.Join(errs...)
+}
+
+// check counts the findings left in the cluster against the thresholds.
+// Kinds and namespaces without a threshold are not limited, unless no
+// threshold is set at all.
+func (t thresholds) check(findings []Finding) []ThresholdViolation {
+	if !t.enabled {
+		return nil
+	}
+
+	total := 0
+	byKind := make(map[string]int)
+	byNamespace := make(map[string]int)
+	for _, finding := range findings {
+		if finding.removed() {
+			continue
+		}
+		total++
+		byKind[finding.Kind]++
+		byNamespace[finding.Namespace]++
+	}
+
+	var violations []ThresholdViolation
+	if len(t.kinds) == 0 && len(t.namespaces) == 0 {
+		if total > 0 {
+			violations = append(violations, ThresholdViolation{Unused: total})
+		}
+		return violations
+	}
+	for _, kind := range slices.Sorted(maps.Keys(t.kinds)) {
+		if byKind[kind] > t.kinds[kind] {
+			violations = append(violations, ThresholdViolation{Kind: kind, Unused: byKind[kind], Max: t.kinds[kind]})
+		}
+	}
+	for _, namespace := range slices.Sorted(maps.Keys(t.namespaces)) {
+		if byNamespace[namespace] > t.namespaces[namespace] {
+			violations = append(violations, ThresholdViolation{Namespace: namespace, Unused: byNamespace[namespace], Max: t.namespaces[namespace]})
+		}
+	}
+	return violations
+}
+
+// formatReportWithThresholds renders report, returning ErrThresholdExceeded
+// along with the output when it has threshold violations
+func formatReportWithThresholds(report *Report, outputFormat string, opts common.Opts) (string, error) {
+	output, err := FormatReport(report, outputFormat, opts)
+	if err != nil {
+		return "", err
+	}
+	if len(report.Violations) != 0 {
+		return output, ErrThresholdExceeded
+	}
+	return output, nil
+}
@@ -0,0 +1,138 @@
+package kor
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func TestThresholdsCheck(t *testing.T) {
+	findings := []Finding{
+		{Kind: "ConfigMap", Namespace: "ns-1", Name: "configmap-1"},
+		{Kind: "ConfigMap", Namespace: "ns-1", Name: "configmap-2"},
+		{Kind: "ConfigMap", Namespace: "ns-2", Name: "configmap-3"},
+		{Kind: "ConfigMap", Namespace: "ns-2", Name: "configmap-4", Deleted: true},
+		{Kind: "Secret", Namespace: "ns-2", Name: "secret-1"},
+	}
+
+	tests := []struct {
+		name     string
+		opts     common.Opts
+		expected []ThresholdViolation
+	}{
+		{
+			name: "disabled",
+			opts: common.Opts{MaxUnused: map[string]int{"cm": 0}},
+		},
+		{
+			name:     "any finding",
+			opts:     common.Opts{FailOnFindings: true},
+			expected: []ThresholdViolation{{Unused: 4}},
+		},
+		{
+			name: "per kind",
+			opts: common.Opts{FailOnFindings: true, MaxUnused: map[string]int{"cm": 2, "secret": 1}},
+			expected: []ThresholdViolation{
+				{Kind: "ConfigMap", Unused: 3, Max: 2},
+			},
+		},
+		{
+			name: "per namespace",
+			opts: common.Opts{FailOnFindings: true, MaxUnusedPerNamespace: map[string]int{"ns-1": 2, "ns-2": 1, "ns-3": 0}},
+			expected: []ThresholdViolation{
+				{Namespace: "ns-2", Unused: 2, Max: 1},
+			},
+		},
+		{
+			name: "within thresholds",
+			opts: common.Opts{FailOnFindings: true, MaxUnused: map[string]int{"configmaps": 3}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			thresholds, err := newThresholds(test.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if violations := thresholds.check(findings); !reflect.DeepEqual(violations, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, violations)
+			}
+		})
+	}
+}
+
+func TestNewThresholdsUnsupported(t *testing.T) {
+	if _, err := newThresholds(common.Opts{FailOnFindings: true, MaxUnused: map[string]int{"unknown": 1}}); err == nil {
+		t.Errorf("Expected an error for an unsupported resource type")
+	}
+}
+
+func TestGetUnusedMultiThresholds(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	for _, name := range []string{"configmap-1", "configmap-2"} {
+		_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, name, AppLabels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+
+	opts := common.Opts{GroupBy: "namespace", FailOnFindings: true, MaxUnused: map[string]int{"configmap": 1}}
+	output, err := GetUnusedMulti(context.TODO(), "cm", &filters.Options{}, clientset, nil, nil, "json", opts)
+	if !errors.Is(err, ErrThresholdExceeded) {
+		t.Errorf("Expected ErrThresholdExceeded, got %v", err)
+	}
+	if output == "" {
+		t.Errorf("Expected the output along with the threshold error")
+	}
+
+	opts.MaxUnused["configmap"] = 2
+	if _, err := GetUnusedMulti(context.TODO(), "cm", &filters.Options{}, clientset, nil, nil, "json", opts); err != nil {
+		t.Errorf("Expected no error within the threshold, got %v", err)
+	}
+}
+
+func TestGetUnusedMultiThresholdsDryRun(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+	for _, name := range []string{"configmap-1", "configmap-2"} {
+		_, err = clientset.CoreV1().ConfigMaps(testNamespace).Create(context.TODO(), CreateTestConfigmap(testNamespace, name, AppLabels), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake configmap: %v", err)
+		}
+	}
+
+	// a dry run leaves the configmaps in the cluster, so they still count
+	for _, dryRun := range []string{DryRunClient, DryRunServer} {
+		opts := common.Opts{GroupBy: "namespace", FailOnFindings: true, MaxUnused: map[string]int{"configmap": 1}, DeleteFlag: true, NoInteractive: true, DryRun: dryRun}
+		report, err := GetUnusedMultiReport(context.TODO(), "cm", &filters.Options{}, Clients{Clientset: clientset}, opts)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, finding := range report.Findings {
+			if !finding.Deleted || !finding.DryRun {
+				t.Errorf("Expected %s to be deleted by a %s dry run", finding.Name, dryRun)
+			}
+		}
+		expected := []ThresholdViolation{{Kind: "ConfigMap", Unused: 2, Max: 1}}
+		if !reflect.DeepEqual(report.Violations, expected) {
+			t.Errorf("Expected %v with a %s dry run, got %v", expected, dryRun, report.Violations)
+		}
+	}
+}
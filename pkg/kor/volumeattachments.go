@@ -1,12 +1,10 @@
 package kor
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
 
+	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -15,8 +13,8 @@ import (
 	"github.com/yonahd/kor/pkg/filters"
 )
 
-func processVolumeAttachments(clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
-	vaList, err := clientset.StorageV1().VolumeAttachments().List(context.TODO(), metav1.ListOptions{
+func processVolumeAttachments(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	vaList, err := clientset.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{
 		LabelSelector: filterOpts.IncludeLabels,
 	})
 	if err != nil {
@@ -47,7 +45,7 @@ func processVolumeAttachments(clientset kubernetes.Interface, filterOpts *filter
 			unusedVAtts = append(unusedVAtts, ResourceInfo{Name: va.Name, Reason: reason})
 			continue
 		}
-		if _, err := clientset.CoreV1().PersistentVolumes().Get(context.TODO(), *pvName, metav1.GetOptions{}); err != nil {
+		if _, err := clientset.CoreV1().PersistentVolumes().Get(ctx, *pvName, metav1.GetOptions{}); err != nil {
 			reason := fmt.Sprintf("PersistentVolume %s does not exist", *pvName)
 			unusedVAtts = append(unusedVAtts, ResourceInfo{Name: va.Name, Reason: reason})
 			continue
@@ -59,7 +57,7 @@ func processVolumeAttachments(clientset kubernetes.Interface, filterOpts *filter
 			unusedVAtts = append(unusedVAtts, ResourceInfo{Name: va.Name, Reason: reason})
 			continue
 		}
-		if _, err := clientset.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{}); err != nil {
+		if _, err := clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{}); err != nil {
 			reason := fmt.Sprintf("Node %s does not exist", nodeName)
 			unusedVAtts = append(unusedVAtts, ResourceInfo{Name: va.Name, Reason: reason})
 			continue
@@ -71,7 +69,7 @@ func processVolumeAttachments(clientset kubernetes.Interface, filterOpts *filter
 			unusedVAtts = append(unusedVAtts, ResourceInfo{Name: va.Name, Reason: reason})
 			continue
 		}
-		if _, err := clientset.StorageV1().CSIDrivers().Get(context.TODO(), attacher, metav1.GetOptions{}); err != nil {
+		if _, err := clientset.StorageV1().CSIDrivers().Get(ctx, attacher, metav1.GetOptions{}); err != nil {
 			reason := fmt.Sprintf("CSIDriver %s does not exist", attacher)
 			unusedVAtts = append(unusedVAtts, ResourceInfo{Name: va.Name, Reason: reason})
 			continue
@@ -82,40 +80,15 @@ func processVolumeAttachments(clientset kubernetes.Interface, filterOpts *filter
 	return unusedVAtts, nil
 }
 
-func GetUnusedVolumeAttachments(filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
-	resources := make(map[string]map[string][]ResourceInfo)
-	diff, err := processVolumeAttachments(clientset, filterOpts)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to process volume attachments: %v\n", err)
-	}
-	if opts.DeleteFlag {
-		if diff, err = DeleteResource(diff, clientset, "", "VolumeAttachment", opts.NoInteractive); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to delete VolumeAttachments: %v\n", err)
-		}
-	}
-	switch opts.GroupBy {
-	case "namespace":
-		resources[""] = make(map[string][]ResourceInfo)
-		resources[""]["VolumeAttachment"] = diff
-	case "resource":
-		appendResources(resources, "VolumeAttachment", "", diff)
-	}
-
-	var outputBuffer bytes.Buffer
-	var jsonResponse []byte
-	switch outputFormat {
-	case "table":
-		outputBuffer = FormatOutput(resources, opts)
-	case "json", "yaml":
-		if jsonResponse, err = json.MarshalIndent(resources, "", "  "); err != nil {
-			return "", err
-		}
-	}
+var volumeAttachmentDetector = newDetector("VolumeAttachment", []string{"volumeattachment", "volumeattachments"}, ClusterScoped, clusterDetect(processVolumeAttachments),
+	func(clients Clients, _ string) typedClient[*storagev1.VolumeAttachment, *storagev1.VolumeAttachmentList] {
+		return clients.Clientset.StorageV1().VolumeAttachments()
+	})
 
-	unusedVAtts, err := unusedResourceFormatter(outputFormat, outputBuffer, opts, jsonResponse)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
+func init() {
+	MustRegisterDetector(volumeAttachmentDetector)
+}
 
-	return unusedVAtts, nil
+func GetUnusedVolumeAttachments(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{volumeAttachmentDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
 }
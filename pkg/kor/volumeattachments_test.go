@@ -71,7 +71,7 @@ func TestGetUnusedVolumeAttachments(t *testing.T) {
 		GroupBy:       "namespace",
 	}
 
-	output, err := GetUnusedVolumeAttachments(&filters.Options{}, clientset, "json", opts)
+	output, err := GetUnusedVolumeAttachments(context.TODO(), &filters.Options{}, clientset, "json", opts)
 	if err != nil {
 		t.Fatalf("Error calling GetUnusedVolumeAttachments: %v", err)
 	}
@@ -148,7 +148,7 @@ func TestFilterOwnerReferencedVolumeAttachments(t *testing.T) {
 
 	// Test without filter - should return both
 	filterOptsNoSkip := &filters.Options{IgnoreOwnerReferences: false}
-	unusedWithoutFilter, err := processVolumeAttachments(clientset, filterOptsNoSkip)
+	unusedWithoutFilter, err := processVolumeAttachments(context.TODO(), clientset, filterOptsNoSkip)
 	if err != nil {
 		t.Fatalf("Error retrieving unused VolumeAttachments: %v", err)
 	}
@@ -159,7 +159,7 @@ func TestFilterOwnerReferencedVolumeAttachments(t *testing.T) {
 
 	// Test with filter - should return only standalone
 	filterOptsWithSkip := &filters.Options{IgnoreOwnerReferences: true}
-	unusedWithFilter, err := processVolumeAttachments(clientset, filterOptsWithSkip)
+	unusedWithFilter, err := processVolumeAttachments(context.TODO(), clientset, filterOptsWithSkip)
 	if err != nil {
 		t.Fatalf("Error retrieving unused VolumeAttachments: %v", err)
 	}
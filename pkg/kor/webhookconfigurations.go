@@ -0,0 +1,186 @@
+package kor
+
+import (
+	"context"
+	"fmt"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+// webhook is the part of a mutating or validating webhook kor checks
+type webhook struct {
+	name          string
+	service       *admissionregistrationv1.ServiceReference
+	failurePolicy *admissionregistrationv1.FailurePolicyType
+}
+
+// webhookServiceProblem returns why a webhook Service can't be called, or an
+// empty string when it has a ready endpoint
+func webhookServiceProblem(ctx context.Context, clientset kubernetes.Interface, service *admissionregistrationv1.ServiceReference) (string, error) {
+	_, err := clientset.CoreV1().Services(service.Namespace).Get(ctx, service.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Sprintf("Service %s/%s does not exist", service.Namespace, service.Name), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get service %s/%s: %v", service.Namespace, service.Name, err)
+	}
+
+	endpointSlices, err := clientset.DiscoveryV1().EndpointSlices(service.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + service.Name,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list endpointslices: %v", err)
+	}
+	for _, endpointSlice := range endpointSlices.Items {
+		for _, endpoint := range endpointSlice.Endpoints {
+			// a nil ready condition is to be read as ready
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				return "", nil
+			}
+		}
+	}
+	return fmt.Sprintf("Service %s/%s has no ready endpoints", service.Namespace, service.Name), nil
+}
+
+// webhookConfigurationReason returns why a webhook configuration is unused,
+// or an empty string when all its webhooks can be called. Webhooks failing
+// closed are reported first, as they reject the requests they match.
+// Webhooks called by URL are not checked.
+func webhookConfigurationReason(ctx context.Context, clientset kubernetes.Interface, webhooks []webhook) (string, error) {
+	var failOpenReason string
+	for _, wh := range webhooks {
+		if wh.service == nil {
+			continue
+		}
+		problem, err := webhookServiceProblem(ctx, clientset, wh.service)
+		if err != nil {
+			return "", err
+		}
+		if problem == "" {
+			continue
+		}
+		// the failurePolicy of admissionregistration/v1 defaults to Fail
+		if wh.failurePolicy == nil || *wh.failurePolicy == admissionregistrationv1.Fail {
+			return fmt.Sprintf("Webhook %s fails closed and rejects the requests it matches: %s", wh.name, problem), nil
+		}
+		if failOpenReason == "" {
+			failOpenReason = fmt.Sprintf("Webhook %s fails open and is skipped: %s", wh.name, problem)
+		}
+	}
+	return failOpenReason, nil
+}
+
+func processMutatingWebhookConfigurations(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	mwcList, err := clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{
+		LabelSelector: filterOpts.IncludeLabels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedMwcs []ResourceInfo
+
+	for _, mwc := range mwcList.Items {
+		// Skip resources with ownerReferences if the general flag is set
+		if filterOpts.IgnoreOwnerReferences && len(mwc.OwnerReferences) > 0 {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&mwc).Run(filterOpts); pass {
+			continue
+		}
+
+		if mwc.Labels["kor/used"] == "false" {
+			reason := "Marked with unused label"
+			unusedMwcs = append(unusedMwcs, ResourceInfo{Name: mwc.Name, Reason: reason})
+			continue
+		}
+
+		webhooks := make([]webhook, 0, len(mwc.Webhooks))
+		for _, wh := range mwc.Webhooks {
+			webhooks = append(webhooks, webhook{name: wh.Name, service: wh.ClientConfig.Service, failurePolicy: wh.FailurePolicy})
+		}
+		reason, err := webhookConfigurationReason(ctx, clientset, webhooks)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			unusedMwcs = append(unusedMwcs, ResourceInfo{Name: mwc.Name, Reason: reason})
+		}
+	}
+
+	return unusedMwcs, nil
+}
+
+func processValidatingWebhookConfigurations(ctx context.Context, clientset kubernetes.Interface, filterOpts *filters.Options) ([]ResourceInfo, error) {
+	vwcList, err := clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{
+		LabelSelector: filterOpts.IncludeLabels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var unusedVwcs []ResourceInfo
+
+	for _, vwc := range vwcList.Items {
+		// Skip resources with ownerReferences if the general flag is set
+		if filterOpts.IgnoreOwnerReferences && len(vwc.OwnerReferences) > 0 {
+			continue
+		}
+
+		if pass, _ := filter.SetObject(&vwc).Run(filterOpts); pass {
+			continue
+		}
+
+		if vwc.Labels["kor/used"] == "false" {
+			reason := "Marked with unused label"
+			unusedVwcs = append(unusedVwcs, ResourceInfo{Name: vwc.Name, Reason: reason})
+			continue
+		}
+
+		webhooks := make([]webhook, 0, len(vwc.Webhooks))
+		for _, wh := range vwc.Webhooks {
+			webhooks = append(webhooks, webhook{name: wh.Name, service: wh.ClientConfig.Service, failurePolicy: wh.FailurePolicy})
+		}
+		reason, err := webhookConfigurationReason(ctx, clientset, webhooks)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			unusedVwcs = append(unusedVwcs, ResourceInfo{Name: vwc.Name, Reason: reason})
+		}
+	}
+
+	return unusedVwcs, nil
+}
+
+var mutatingWebhookConfigurationDetector = newDetector("MutatingWebhookConfiguration", []string{"mutatingwebhookconfiguration", "mutatingwebhookconfigurations"}, ClusterScoped, clusterDetect(processMutatingWebhookConfigurations),
+	func(clients Clients, _ string) typedClient[*admissionregistrationv1.MutatingWebhookConfiguration, *admissionregistrationv1.MutatingWebhookConfigurationList] {
+		return clients.Clientset.AdmissionregistrationV1().MutatingWebhookConfigurations()
+	})
+
+var validatingWebhookConfigurationDetector = newDetector("ValidatingWebhookConfiguration", []string{"validatingwebhookconfiguration", "validatingwebhookconfigurations"}, ClusterScoped, clusterDetect(processValidatingWebhookConfigurations),
+	func(clients Clients, _ string) typedClient[*admissionregistrationv1.ValidatingWebhookConfiguration, *admissionregistrationv1.ValidatingWebhookConfigurationList] {
+		return clients.Clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+	})
+
+func init() {
+	MustRegisterDetector(mutatingWebhookConfigurationDetector)
+	MustRegisterDetector(validatingWebhookConfigurationDetector)
+}
+
+func GetUnusedMutatingWebhookConfigurations(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{mutatingWebhookConfigurationDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
+}
+
+func GetUnusedValidatingWebhookConfigurations(ctx context.Context, filterOpts *filters.Options, clientset kubernetes.Interface, outputFormat string, opts common.Opts) (string, error) {
+	return GetUnusedWithDetectors(ctx, []Detector{validatingWebhookConfigurationDetector}, filterOpts, Clients{Clientset: clientset}, outputFormat, opts)
+}
@@ -0,0 +1,156 @@
+package kor
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/yonahd/kor/pkg/common"
+	"github.com/yonahd/kor/pkg/filters"
+)
+
+func createTestWebhookConfigurations(t *testing.T) *fake.Clientset {
+	clientset := fake.NewClientset()
+
+	_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), CreateTestNamespace(testNamespace, AppLabels), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating namespace %s: %v", testNamespace, err)
+	}
+
+	// a Service with a ready endpoint and one without any
+	for _, name := range []string{"ready-service", "idle-service"} {
+		_, err = clientset.CoreV1().Services(testNamespace).Create(context.TODO(), CreateTestService(testNamespace, name), v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake service: %v", err)
+		}
+	}
+	_, err = clientset.DiscoveryV1().EndpointSlices(testNamespace).Create(context.TODO(), CreateTestEndpoint(testNamespace, "ready-service", 1, map[string]string{}), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake endpointslice: %v", err)
+	}
+	_, err = clientset.DiscoveryV1().EndpointSlices(testNamespace).Create(context.TODO(), CreateTestEndpoint(testNamespace, "idle-service", 0, map[string]string{}), v1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating fake endpointslice: %v", err)
+	}
+
+	validatingWebhookConfigurations := []*admissionregistrationv1.ValidatingWebhookConfiguration{
+		CreateTestValidatingWebhookConfiguration("ready-vwc", testNamespace, "ready-service", admissionregistrationv1.Fail),
+		CreateTestValidatingWebhookConfiguration("missing-vwc", testNamespace, "missing-service", admissionregistrationv1.Fail),
+		CreateTestValidatingWebhookConfiguration("idle-vwc", testNamespace, "idle-service", admissionregistrationv1.Ignore),
+	}
+	for _, vwc := range validatingWebhookConfigurations {
+		_, err = clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().Create(context.TODO(), vwc, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake ValidatingWebhookConfiguration %s: %v", vwc.Name, err)
+		}
+	}
+
+	urlMwc := CreateTestMutatingWebhookConfiguration("url-mwc", "", "", admissionregistrationv1.Fail)
+	urlMwc.Webhooks[0].ClientConfig = admissionregistrationv1.WebhookClientConfig{URL: ptrToString("https://webhook.example.com")}
+	mutatingWebhookConfigurations := []*admissionregistrationv1.MutatingWebhookConfiguration{
+		CreateTestMutatingWebhookConfiguration("ready-mwc", testNamespace, "ready-service", admissionregistrationv1.Fail),
+		CreateTestMutatingWebhookConfiguration("missing-mwc", testNamespace, "missing-service", admissionregistrationv1.Ignore),
+		urlMwc,
+	}
+	for _, mwc := range mutatingWebhookConfigurations {
+		_, err = clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().Create(context.TODO(), mwc, v1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Error creating fake MutatingWebhookConfiguration %s: %v", mwc.Name, err)
+		}
+	}
+
+	return clientset
+}
+
+func TestGetUnusedValidatingWebhookConfigurations(t *testing.T) {
+	clientset := createTestWebhookConfigurations(t)
+
+	opts := common.Opts{
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedValidatingWebhookConfigurations(context.TODO(), &filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedValidatingWebhookConfigurations: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		"": {
+			"ValidatingWebhookConfiguration": {"idle-vwc", "missing-vwc"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+	sort.Strings(actualOutput[""]["ValidatingWebhookConfiguration"])
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output %+v, but got %+v", expectedOutput, actualOutput)
+	}
+}
+
+func TestGetUnusedMutatingWebhookConfigurations(t *testing.T) {
+	clientset := createTestWebhookConfigurations(t)
+
+	opts := common.Opts{
+		DeleteFlag:    false,
+		NoInteractive: true,
+		GroupBy:       "namespace",
+	}
+
+	output, err := GetUnusedMutatingWebhookConfigurations(context.TODO(), &filters.Options{}, clientset, "json", opts)
+	if err != nil {
+		t.Fatalf("Error calling GetUnusedMutatingWebhookConfigurations: %v", err)
+	}
+
+	expectedOutput := map[string]map[string][]string{
+		"": {
+			"MutatingWebhookConfiguration": {"missing-mwc"},
+		},
+	}
+
+	var actualOutput map[string]map[string][]string
+	if err := json.Unmarshal([]byte(output), &actualOutput); err != nil {
+		t.Fatalf("Error unmarshaling actual output: %v", err)
+	}
+
+	if !reflect.DeepEqual(expectedOutput, actualOutput) {
+		t.Errorf("Expected output %+v, but got %+v", expectedOutput, actualOutput)
+	}
+}
+
+func TestWebhookConfigurationReasons(t *testing.T) {
+	clientset := createTestWebhookConfigurations(t)
+
+	unused, err := processValidatingWebhookConfigurations(context.TODO(), clientset, &filters.Options{})
+	if err != nil {
+		t.Fatalf("Error retrieving unused ValidatingWebhookConfigurations: %v", err)
+	}
+
+	reasons := make(map[string]string)
+	for _, resource := range unused {
+		reasons[resource.Name] = resource.Reason
+	}
+	expected := map[string][]string{
+		"missing-vwc": {"fails closed", "Service test-namespace/missing-service does not exist"},
+		"idle-vwc":    {"fails open", "Service test-namespace/idle-service has no ready endpoints"},
+	}
+	for name, fragments := range expected {
+		for _, fragment := range fragments {
+			if !strings.Contains(reasons[name], fragment) {
+				t.Errorf("Expected the reason of %s to contain %q, got %q", name, fragment, reasons[name])
+			}
+		}
+	}
+}